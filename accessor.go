@@ -0,0 +1,152 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// accessor is a raw handle accessor of a wrapper receiver type (e.g.
+// `{recv}.handle` of `type Window struct { handle *sdlWindow }`), forwarded in
+// place of the receiver to functions taking the raw handle.
+type accessor struct {
+	// wrapper receiver type.
+	recvType types.Type
+	// accessor expression template.
+	template string
+	// type of the accessor expression; i.e. the raw handle type.
+	rawType types.Type
+}
+
+// accessorRecvName is the identifier substituted for the {recv} placeholder
+// when resolving the type of accessor expressions.
+const accessorRecvName = "genmethodsRecv"
+
+// resolveAccessors resolves the accessors of wrapper receiver types of the
+// user-provided config, sorted by receiver type.
+func (gen *Gen) resolveAccessors() error {
+	config := gen.opts.Config
+	if config == nil || len(config.Accessors) == 0 {
+		return nil
+	}
+	var typStrs []string
+	for typStr := range config.Accessors {
+		typStrs = append(typStrs, typStr)
+	}
+	sort.Strings(typStrs)
+	for _, typStr := range typStrs {
+		recvType, err := gen.lookupType(typStr)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		template := config.Accessors[typStr]
+		rawType, err := gen.accessorType(recvType, template)
+		if err != nil {
+			return errors.Wrapf(err, "invalid accessor %q of receiver type %v", template, recvType)
+		}
+		gen.accessors = append(gen.accessors, &accessor{recvType: recvType, template: template, rawType: rawType})
+	}
+	return nil
+}
+
+// accessorType returns the type of the given accessor expression template on
+// the given receiver type. Accessor expressions are selectors of fields and
+// calls of methods without arguments, starting at the receiver (e.g.
+// `{recv}.handle` or `{recv}.inner.Handle()`).
+func (gen *Gen) accessorType(recvType types.Type, template string) (types.Type, error) {
+	expr, err := parser.ParseExpr(strings.ReplaceAll(template, "{recv}", accessorRecvName))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ident, ok := expr.(*ast.Ident); ok && ident.Name == accessorRecvName {
+		return nil, errors.New("accessor expression is the receiver itself; expected field selectors or method calls of the receiver")
+	}
+	return gen.accessorExprType(recvType, expr)
+}
+
+// accessorExprType returns the type of the given (sub)expression of an
+// accessor expression on the given receiver type.
+func (gen *Gen) accessorExprType(recvType types.Type, expr ast.Expr) (types.Type, error) {
+	lookup := func(x ast.Expr, name string) (types.Object, error) {
+		typ, err := gen.accessorExprType(recvType, x)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		obj, _, _ := types.LookupFieldOrMethod(typ, true, gen.pkg.Types, name)
+		if obj == nil {
+			return nil, errors.Errorf("no field or method %q of %v", name, typ)
+		}
+		return obj, nil
+	}
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if expr.Name == accessorRecvName {
+			return recvType, nil
+		}
+	case *ast.ParenExpr:
+		return gen.accessorExprType(recvType, expr.X)
+	case *ast.SelectorExpr:
+		obj, err := lookup(expr.X, expr.Sel.Name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		field, ok := obj.(*types.Var)
+		if !ok {
+			return nil, errors.Errorf("%q is a method; expected a field or a method call", expr.Sel.Name)
+		}
+		return field.Type(), nil
+	case *ast.CallExpr:
+		sel, ok := expr.Fun.(*ast.SelectorExpr)
+		if !ok || len(expr.Args) > 0 {
+			break
+		}
+		obj, err := lookup(sel.X, sel.Sel.Name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		method, ok := obj.(*types.Func)
+		if !ok {
+			return nil, errors.Errorf("%q is a field; expected a method", sel.Sel.Name)
+		}
+		sig := method.Type().(*types.Signature)
+		if sig.Results().Len() != 1 {
+			return nil, errors.Errorf("method %q returns %d results; expected one result", sel.Sel.Name, sig.Results().Len())
+		}
+		return sig.Results().At(0).Type(), nil
+	}
+	return nil, errors.Errorf("unsupported accessor expression %q; expected field selectors or method calls without arguments of the receiver (e.g. %q)", strings.ReplaceAll(types.ExprString(expr), accessorRecvName, "{recv}"), "{recv}.handle")
+}
+
+// accessorRecvTypes returns the wrapper receiver types with accessors of the
+// given raw handle parameter type.
+func (gen *Gen) accessorRecvTypes(paramType types.Type) []types.Type {
+	var recvTypes []types.Type
+	for _, acc := range gen.accessors {
+		if types.Identical(acc.rawType, paramType) {
+			recvTypes = append(recvTypes, acc.recvType)
+		}
+	}
+	return recvTypes
+}
+
+// accessorOf returns the accessor of the given wrapper receiver type of the
+// given raw handle parameter type. Accessors are matched by the base type of
+// receivers, as receiver kinds may be pinned per function.
+func (gen *Gen) accessorOf(recvType, paramType types.Type) (*accessor, bool) {
+	base := func(typ types.Type) types.Type {
+		if ptr, ok := typ.(*types.Pointer); ok {
+			return ptr.Elem()
+		}
+		return typ
+	}
+	for _, acc := range gen.accessors {
+		if types.Identical(base(acc.recvType), base(recvType)) && types.Identical(acc.rawType, paramType) {
+			return acc, true
+		}
+	}
+	return nil, false
+}
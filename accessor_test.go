@@ -0,0 +1,105 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAccessors(t *testing.T) {
+	accessors := map[string]string{
+		"*" + fixturePkgPath + ".Window":   "{recv}.handle",
+		"*" + fixturePkgPath + ".Renderer": "{recv}.inner.Handle()",
+	}
+	golden := []struct {
+		name      string
+		recvNames map[string]string
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "accessors",
+			want: map[string]string{
+				// field selector.
+				"(*Window).Show":           "func (window *Window) Show() bool {\n\treturn ShowWindow(window.handle)\n}",
+				"(*Window).SetWindowTitle": "func (window *Window) SetWindowTitle(title string) bool { return SetWindowTitle(window.handle, title) }",
+				// method call.
+				"(*Renderer).Clear": "func (renderer *Renderer) Clear() bool {\n\treturn RenderClear(renderer.inner.Handle())\n}",
+			},
+		},
+		{
+			name:      "recv names",
+			recvNames: map[string]string{"*" + fixturePkgPath + ".Window": "w"},
+			want: map[string]string{
+				"(*Window).GetWindowID": "func (w *Window) GetWindowID() uint32 {\n\treturn GetWindowID(w.handle)\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			opts := &GenOptions{Config: &Config{Accessors: accessors, RecvNames: g.recvNames}}
+			genFixture(t, "wrapper", opts).checkMethods(t, g.want)
+			// the tests of the fixture check the forwarded raw handles.
+			runFixtureTests(t)
+		})
+	}
+}
+
+func TestAccessorsError(t *testing.T) {
+	golden := []struct {
+		name string
+		// receiver type name of accessor.
+		typeName string
+		accessor string
+		// expected substring of error.
+		want string
+	}{
+		{
+			name:     "receiver",
+			typeName: "Window",
+			accessor: "{recv}",
+			want:     "accessor expression is the receiver itself",
+		},
+		{
+			name:     "missing field",
+			typeName: "Window",
+			accessor: "{recv}.raw",
+			want:     `no field or method "raw" of *github.com/jupiterrider/purego-sdl3/sdl.Window`,
+		},
+		{
+			name:     "method value",
+			typeName: "Renderer",
+			accessor: "{recv}.inner.Handle",
+			want:     `"Handle" is a method; expected a field or a method call`,
+		},
+		{
+			name:     "field call",
+			typeName: "Renderer",
+			accessor: "{recv}.inner()",
+			want:     `"inner" is a field; expected a method`,
+		},
+		{
+			name:     "call with arguments",
+			typeName: "Renderer",
+			accessor: "{recv}.inner.Handle(1)",
+			want:     `unsupported accessor expression "{recv}.inner.Handle(1)"`,
+		},
+		{
+			name:     "syntax error",
+			typeName: "Window",
+			accessor: "{recv}.",
+			want:     `invalid accessor "{recv}." of receiver type`,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "wrapper")
+			typeName := "*" + fixturePkgPath + "." + g.typeName
+			opts := &GenOptions{Config: &Config{Accessors: map[string]string{typeName: g.accessor}}}
+			_, err := genMethods(fixturePkgPath, filepath.Join(dir, "sdl", "methods_gen.go"), opts)
+			if err == nil || !strings.Contains(err.Error(), g.want) {
+				t.Errorf("error mismatch; expected %q, got %v", g.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/unitchecker"
+	"golang.org/x/tools/go/packages"
+)
+
+// Analyzer reports missing or stale methods of the genmethods output file of
+// each analyzed package as diagnostics. Run it using:
+//
+//	go vet -vettool=$(which genmethods) ./...
+//
+// By default, the output file of a package is the file with a `// Code
+// generated by "genmethods"` header; packages without such a file are not
+// reported unless an output file name is given (-output).
+var Analyzer = &analysis.Analyzer{
+	Name: "genmethods",
+	Doc:  "report missing or stale methods generated by genmethods",
+	Run:  runAnalyzer,
+}
+
+// Flags of the analyzer.
+var (
+	// output file name within each package directory.
+	analyzerOutput string
+	// path to config file.
+	analyzerConfig string
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&analyzerOutput, "output", "", "output file name within each package directory (default file generated by genmethods)")
+	Analyzer.Flags.StringVar(&analyzerConfig, "config", "", "path to JSON or TOML config file (default genmethods.toml located automatically)")
+}
+
+// isVetTool reports whether the given command line arguments denote an
+// invocation by `go vet -vettool`, following the protocol of unitchecker; i.e.
+// a query of the tool version (`genmethods -V=full`) or flags (`genmethods
+// -flags`), or the analysis of a package described by a JSON vet config file
+// (`genmethods vet.cfg`).
+func isVetTool(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch last := args[len(args)-1]; {
+	case len(args) == 1 && (last == "-flags" || last == "-V=full"):
+		return true
+	case strings.HasSuffix(last, ".cfg"):
+		return isVetConfig(last)
+	default:
+		return false
+	}
+}
+
+// isVetConfig reports whether the given file is a JSON vet config file, as
+// written by `go vet` for each analyzed package.
+func isVetConfig(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		Compiler   string
+		ImportPath string
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return false
+	}
+	return len(cfg.Compiler) > 0 && len(cfg.ImportPath) > 0
+}
+
+// runVetTool runs the analyzer as the vet tool of `go vet -vettool`.
+func runVetTool() {
+	clog.SetPathLevel("main", clog.LevelWarn)
+	unitchecker.Main(Analyzer)
+}
+
+// runAnalyzer generates the methods of the package of the given analysis pass,
+// and reports differences to its output file.
+func runAnalyzer(pass *analysis.Pass) (any, error) {
+	opts := &GenOptions{}
+	configPath := analyzerConfig
+	if len(configPath) == 0 {
+		path, err := findConfig()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		configPath = path
+	}
+	if len(configPath) > 0 {
+		config, err := loadConfig(configPath, "")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		opts.Config = config
+	}
+	pkg := &packages.Package{
+		PkgPath:   pass.Pkg.Path(),
+		Name:      pass.Pkg.Name(),
+		Fset:      pass.Fset,
+		Types:     pass.Pkg,
+		TypesInfo: pass.TypesInfo,
+	}
+	var outputFile *ast.File
+	for _, file := range pass.Files {
+		filename := pass.Fset.File(file.Pos()).Name()
+		// skip test files of test variants of the package, as methods are
+		// generated for the functions of the package proper.
+		if strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		pkg.Syntax = append(pkg.Syntax, file)
+		pkg.GoFiles = append(pkg.GoFiles, filename)
+		switch {
+		case len(analyzerOutput) > 0:
+			if filepath.Base(filename) == analyzerOutput {
+				outputFile = file
+			}
+		case isGenmethodsFile(file):
+			outputFile = file
+		}
+	}
+	if len(pkg.Syntax) == 0 {
+		return nil, nil // external test package.
+	}
+	if outputFile == nil && len(analyzerOutput) == 0 {
+		return nil, nil // package not using genmethods.
+	}
+	output := ""
+	if outputFile != nil {
+		output = pass.Fset.File(outputFile.Pos()).Name()
+	} else {
+		output = filepath.Join(filepath.Dir(pkg.GoFiles[0]), analyzerOutput)
+	}
+	gen, err := newGenFromPkg(pkg, output, opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(gen.methods) == 0 {
+		return nil, nil
+	}
+	want, err := gen.outputSource()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if outputFile == nil {
+		pass.Reportf(pkg.Syntax[0].Package, "missing genmethods output file %q with %d generated methods; re-run genmethods", analyzerOutput, len(gen.methods))
+		return nil, nil
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if bytes.Equal(got, want) {
+		return nil, nil
+	}
+	wantFile, err := parser.ParseFile(token.NewFileSet(), output, want, parser.ParseComments)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if n := reportMethodDiffs(pass, outputFile, wantFile); n == 0 {
+		// methods are up to date, but other declarations (e.g. imports)
+		// differ.
+		pass.Reportf(outputFile.Package, "genmethods output file is not up to date; re-run genmethods")
+	}
+	return nil, nil
+}
+
+// reportMethodDiffs reports the missing, stale and extraneous methods of the
+// given output file compared to the given file of generated methods, and
+// returns the number of reported diagnostics.
+func reportMethodDiffs(pass *analysis.Pass, gotFile, wantFile *ast.File) int {
+	n := 0
+	gotMethods := fileMethods(gotFile)
+	wantMethods := fileMethods(wantFile)
+	for _, want := range wantFile.Decls {
+		want, ok := want.(*ast.FuncDecl)
+		if !ok || want.Recv == nil {
+			continue
+		}
+		key := methodKey(want)
+		got, ok := gotMethods[key]
+		switch {
+		case !ok:
+			pass.Reportf(gotFile.Package, "missing generated method %s; re-run genmethods", key)
+			n++
+		case declText(got) != declText(want):
+			pass.Reportf(got.Pos(), "generated method %s is stale; re-run genmethods", key)
+			n++
+		}
+	}
+	for _, got := range gotFile.Decls {
+		got, ok := got.(*ast.FuncDecl)
+		if !ok || got.Recv == nil {
+			continue
+		}
+		key := methodKey(got)
+		if _, ok := wantMethods[key]; !ok {
+			pass.Reportf(got.Pos(), "method %s is no longer generated; re-run genmethods", key)
+			n++
+		}
+	}
+	return n
+}
+
+// isGenmethodsFile reports whether the given file was generated by genmethods.
+func isGenmethodsFile(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if strings.HasPrefix(comment.Text, `// Code generated by "genmethods"`) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fileMethods returns the method declarations of the given file, keyed by
+// method key (e.g. "(*Window).SetTitle").
+func fileMethods(file *ast.File) map[string]*ast.FuncDecl {
+	methods := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv != nil {
+			methods[methodKey(funcDecl)] = funcDecl
+		}
+	}
+	return methods
+}
+
+// methodKey returns the receiver type and name of the given method (e.g.
+// "(*Window).SetTitle").
+func methodKey(decl *ast.FuncDecl) string {
+	recvType := decl.Recv.List[0].Type
+	if index, ok := recvType.(*ast.IndexExpr); ok {
+		recvType = index.X // generic receiver types.
+	}
+	if _, ok := recvType.(*ast.StarExpr); ok {
+		return fmt.Sprintf("(%s).%s", types.ExprString(recvType), decl.Name)
+	}
+	return fmt.Sprintf("%s.%s", types.ExprString(recvType), decl.Name)
+}
+
+// declText returns the formatted Go source of the given declaration, including
+// its doc comment, for comparison of declarations of different files.
+func declText(decl *ast.FuncDecl) string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(decl.Doc.Text())
+	// print without doc comment and source positions, as positions are not
+	// comparable between files.
+	noDoc := *decl
+	noDoc.Doc = nil
+	if err := format.Node(buf, token.NewFileSet(), &noDoc); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsVetTool(t *testing.T) {
+	dir := t.TempDir()
+	vetConfig := filepath.Join(dir, "vet.cfg")
+	if err := os.WriteFile(vetConfig, []byte(`{"ID": "sdl", "Compiler": "gc", "ImportPath": "sdl", "GoFiles": ["sdl.go"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	otherConfig := filepath.Join(dir, "genmethods.cfg")
+	if err := os.WriteFile(otherConfig, []byte("types = []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "no arguments", args: nil, want: false},
+		{name: "version", args: []string{"-V=full"}, want: true},
+		{name: "flags", args: []string{"-flags"}, want: true},
+		{name: "vet config", args: []string{vetConfig}, want: true},
+		{name: "vet config with flags", args: []string{"-genmethods.output=methods.go", vetConfig}, want: true},
+		{name: "other cfg file", args: []string{"-config", otherConfig}, want: false},
+		{name: "missing cfg file", args: []string{filepath.Join(dir, "missing.cfg")}, want: false},
+		{name: "generation flags", args: []string{"-pkg", "./sdl", "-o", "methods.go"}, want: false},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			if got := isVetTool(g.args); got != g.want {
+				t.Errorf("isVetTool(%q) mismatch; expected %v, got %v", g.args, g.want, got)
+			}
+		})
+	}
+}
+
+func TestVetTool(t *testing.T) {
+	dir := newFixture(t, "sdl")
+	if _, stderr, err := runMain(t, dir, "-pkg", fixturePkgPath, "-o", "sdl/methods_gen.go"); err != nil {
+		t.Fatalf("unable to run genmethods; %v\n%s", err, stderr)
+	}
+	// functions of test files are not converted to methods, and are thus not
+	// reported as missing generated methods.
+	testSrc := "package sdl\n\n// CloneWindow returns a copy of the window.\nfunc CloneWindow(window *Window) *Window { return window }\n"
+	if err := os.WriteFile(filepath.Join(dir, "sdl", "clone_test.go"), []byte(testSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vet := func() ([]byte, error) {
+		cmd := exec.Command("go", "vet", "-vettool="+exe, "./...")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), runMainEnv+"=1", "GOWORK=off", "GOFLAGS=-mod=mod")
+		return cmd.CombinedOutput()
+	}
+	if out, err := vet(); err != nil || bytes.Contains(out, []byte("re-run genmethods")) {
+		t.Fatalf("unexpected diagnostics; %v\n%s", err, out)
+	}
+	// stale methods of the output file are reported.
+	output := filepath.Join(dir, "sdl", "methods_gen.go")
+	src, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = bytes.Replace(src, []byte("SetWindowTitle(window, title)"), []byte(`SetWindowTitle(window, "")`), 1)
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := vet()
+	if want := "generated method (*Window).SetWindowTitle is stale; re-run genmethods"; !bytes.Contains(out, []byte(want)) {
+		t.Errorf("diagnostics mismatch; expected %q, got %v\n%s", want, err, out)
+	}
+}
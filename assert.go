@@ -0,0 +1,128 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/pkg/errors"
+)
+
+// stdInterfaces specifies the standard library interfaces for which
+// compile-time assertions are generated (-assert-interfaces).
+var stdInterfaces = []struct {
+	// import path of interface package.
+	pkgPath string
+	// interface name.
+	name string
+	// method name of interface.
+	methodName string
+	// method signature of interface.
+	sig *types.Signature
+}{
+	{pkgPath: "fmt", name: "Stringer", methodName: "String", sig: types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String])), false)},
+	{pkgPath: "io", name: "Closer", methodName: "Close", sig: types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Universe.Lookup("error").Type())), false)},
+}
+
+// assertDecls returns compile-time interface assertions (e.g. `var _
+// io.Closer = (*Window)(nil)`) for the receiver types of the given generated
+// methods implementing standard library interfaces (i.e. fmt.Stringer and
+// io.Closer), in order of first generated method.
+func (gen *Gen) assertDecls(methods []*Method) []ast.Decl {
+	var decls []ast.Decl
+	seen := make(map[string]bool)
+	for _, method := range methods {
+		if method.Func == nil {
+			continue
+		}
+		for _, iface := range stdInterfaces {
+			if method.Decl.Name.Name != iface.methodName || !types.Identical(methodSig(method), iface.sig) {
+				continue
+			}
+			if params := method.Decl.Type.Params; params != nil && len(params.List) > 0 {
+				continue // e.g. injected context parameter.
+			}
+			base := method.RecvType
+			if ptr, ok := base.(*types.Pointer); ok {
+				base = ptr.Elem()
+			}
+			key := base.String() + "." + iface.name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			decl := &ast.GenDecl{
+				Tok: token.VAR,
+				Specs: []ast.Spec{
+					&ast.ValueSpec{
+						Names: []*ast.Ident{ast.NewIdent("_")},
+						Type: &ast.SelectorExpr{
+							X:   ast.NewIdent(iface.pkgPath),
+							Sel: ast.NewIdent(iface.name),
+						},
+						Values: []ast.Expr{
+							&ast.CallExpr{
+								Fun:  &ast.ParenExpr{X: gen.typeExpr(types.NewPointer(base))},
+								Args: []ast.Expr{ast.NewIdent("nil")},
+							},
+						},
+					},
+				},
+			}
+			decls = append(decls, decl)
+		}
+	}
+	return decls
+}
+
+// checkAssertInterfaces checks that the interfaces of -add-interface-assertion
+// are declared in the analyzed package.
+func (gen *Gen) checkAssertInterfaces() error {
+	for _, name := range gen.opts.AddInterfaceAssertions {
+		if _, err := gen.lookupInterface(name); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// customAssertDecls returns compile-time interface assertions (e.g. `var _
+// Drawable = (*Window)(nil)`) of the interfaces of -add-interface-assertion
+// for each receiver type of the given generated methods, in order of first
+// generated method.
+func (gen *Gen) customAssertDecls(methods []*Method) []ast.Decl {
+	if len(gen.opts.AddInterfaceAssertions) == 0 {
+		return nil
+	}
+	var decls []ast.Decl
+	seen := make(map[string]bool)
+	for _, method := range methods {
+		base := method.RecvType
+		if ptr, ok := base.(*types.Pointer); ok {
+			base = ptr.Elem()
+		}
+		if seen[base.String()] {
+			continue
+		}
+		seen[base.String()] = true
+		for _, name := range gen.opts.AddInterfaceAssertions {
+			decl := &ast.GenDecl{
+				Tok: token.VAR,
+				Specs: []ast.Spec{
+					&ast.ValueSpec{
+						Names: []*ast.Ident{ast.NewIdent("_")},
+						Type:  ast.NewIdent(name),
+						Values: []ast.Expr{
+							&ast.CallExpr{
+								Fun:  &ast.ParenExpr{X: gen.typeExpr(types.NewPointer(base))},
+								Args: []ast.Expr{ast.NewIdent("nil")},
+							},
+						},
+					},
+				},
+			}
+			decls = append(decls, decl)
+		}
+	}
+	return decls
+}
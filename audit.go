@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+)
+
+// printAudit prints an audit of the method-ability of the exported functions
+// of the package to w, as generated under the current configuration.
+//
+// The output is line-oriented and sorted, with tab-separated entries:
+//
+//	exported functions: 19
+//	qualifying functions: 12
+//	methods by receiver type:
+//		*Renderer	3
+//		*Window	9
+//	non-qualifying functions:
+//		CreateWindow	first parameter type string is not a valid method type
+func (gen *Gen) printAudit(w io.Writer) {
+	qualifier := types.RelativeTo(gen.pkg.Types)
+	qualifying := make(map[string]bool)
+	recvCounts := make(map[string]int)
+	for _, method := range gen.methods {
+		if !token.IsExported(method.Func.Name()) {
+			continue
+		}
+		qualifying[method.Func.Name()] = true
+		recvCounts[types.TypeString(method.RecvType, qualifier)]++
+	}
+	var skipped []*SkippedFunc
+	for _, s := range gen.skipped {
+		if token.IsExported(s.Name) && !qualifying[s.Name] {
+			skipped = append(skipped, s)
+		}
+	}
+	sort.SliceStable(skipped, func(i, j int) bool {
+		return skipped[i].Name < skipped[j].Name
+	})
+	var recvTypes []string
+	for recvType := range recvCounts {
+		recvTypes = append(recvTypes, recvType)
+	}
+	sort.Strings(recvTypes)
+	fmt.Fprintf(w, "exported functions: %d\n", len(qualifying)+len(skipped))
+	fmt.Fprintf(w, "qualifying functions: %d\n", len(qualifying))
+	fmt.Fprintln(w, "methods by receiver type:")
+	for _, recvType := range recvTypes {
+		fmt.Fprintf(w, "\t%s\t%d\n", recvType, recvCounts[recvType])
+	}
+	fmt.Fprintln(w, "non-qualifying functions:")
+	for _, s := range skipped {
+		fmt.Fprintf(w, "\t%s\t%s\n", s.Name, s.Reason)
+	}
+}
+
+// printGlobals prints the exported functions of the package with no parameter
+// of a valid receiver type to w; i.e. candidates for a singleton or global
+// wrapper (e.g. `GetError() string`), as they cannot be converted to methods.
+//
+// The output is line-oriented and sorted, with tab-separated entries:
+//
+//	global function candidates: 2
+//		ClearError	func()
+//		GetError	func() string
+func (gen *Gen) printGlobals(w io.Writer) {
+	qualifier := types.RelativeTo(gen.pkg.Types)
+	scope := gen.pkg.Types.Scope()
+	var lines []string
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		if _, ok := obj.(*types.TypeName); ok {
+			continue
+		}
+		sig, ok := obj.Type().Underlying().(*types.Signature)
+		if !ok {
+			continue
+		}
+		if gen.hasRecvParam(sig) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("\t%s\t%s\n", name, types.TypeString(sig, qualifier)))
+	}
+	fmt.Fprintf(w, "global function candidates: %d\n", len(lines))
+	for _, line := range lines {
+		fmt.Fprint(w, line)
+	}
+}
+
+// hasRecvParam reports whether any parameter of the given function signature
+// has a valid receiver type.
+func (gen *Gen) hasRecvParam(sig *types.Signature) bool {
+	for i := 0; i < sig.Params().Len(); i++ {
+		if _, ok := gen.methodRecvType(sig.Params().At(i).Type()); ok {
+			return true
+		}
+		if gen.opts.InterfaceRecv && types.IsInterface(sig.Params().At(i).Type()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// returnsError reports whether the last result of the given result list is of
+// type error.
+func (gen *Gen) returnsError(results *ast.FieldList) bool {
+	if results == nil || len(results.List) == 0 {
+		return false
+	}
+	last := results.List[len(results.List)-1]
+	return isError(gen.pkg.TypesInfo.TypeOf(last.Type))
+}
+
+// namedResults returns a copy of the given result list with named results, and
+// the name of the last result. Unnamed results are named r0, r1, etc, and a
+// trailing error result is named err; names colliding with parameters are
+// suffixed with underscores.
+func namedResults(results *ast.FieldList, params []param) (*ast.FieldList, string) {
+	if len(results.List[0].Names) > 0 {
+		last := results.List[len(results.List)-1]
+		return results, last.Names[len(last.Names)-1].Name
+	}
+	used := make(map[string]bool)
+	for _, param := range params {
+		used[param.name.Name] = true
+	}
+	uniqueName := func(name string) string {
+		for used[name] {
+			name += "_"
+		}
+		used[name] = true
+		return name
+	}
+	newResults := &ast.FieldList{}
+	var lastName string
+	for i, field := range results.List {
+		name := fmt.Sprintf("r%d", i)
+		if i == len(results.List)-1 {
+			name = "err"
+		}
+		lastName = uniqueName(name)
+		newField := &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(lastName)},
+			Type:  field.Type,
+		}
+		newResults.List = append(newResults.List, newField)
+	}
+	return newResults, lastName
+}
+
+// recoverStmt returns a deferred function call which recovers panics and
+// assigns them as errors to the named error result, e.g.
+//
+//	defer func() {
+//		if rec := recover(); rec != nil {
+//			err = fmt.Errorf("panic in Foo: %v", rec)
+//		}
+//	}()
+func recoverStmt(errName, funcName string) ast.Stmt {
+	return &ast.DeferStmt{
+		Call: &ast.CallExpr{
+			Fun: &ast.FuncLit{
+				Type: &ast.FuncType{Params: &ast.FieldList{}},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.IfStmt{
+							Init: &ast.AssignStmt{
+								Lhs: []ast.Expr{ast.NewIdent("rec")},
+								Tok: token.DEFINE,
+								Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent("recover")}},
+							},
+							Cond: &ast.BinaryExpr{
+								X:  ast.NewIdent("rec"),
+								Op: token.NEQ,
+								Y:  ast.NewIdent("nil"),
+							},
+							Body: &ast.BlockStmt{
+								List: []ast.Stmt{
+									&ast.AssignStmt{
+										Lhs: []ast.Expr{ast.NewIdent(errName)},
+										Tok: token.ASSIGN,
+										Rhs: []ast.Expr{
+											&ast.CallExpr{
+												Fun: &ast.SelectorExpr{
+													X:   ast.NewIdent("fmt"),
+													Sel: ast.NewIdent("Errorf"),
+												},
+												Args: []ast.Expr{
+													&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("panic in " + funcName + ": %v")},
+													ast.NewIdent("rec"),
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// wrapErrorStmt returns a statement wrapping the non-nil error of the given
+// error variable with the name of the forwarded function, e.g.
+//
+//	if err != nil {
+//		err = fmt.Errorf("Foo: %w", err)
+//	}
+func wrapErrorStmt(errName, funcName string) ast.Stmt {
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X:  ast.NewIdent(errName),
+			Op: token.NEQ,
+			Y:  ast.NewIdent("nil"),
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{ast.NewIdent(errName)},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{
+						&ast.CallExpr{
+							Fun: &ast.SelectorExpr{
+								X:   ast.NewIdent("fmt"),
+								Sel: ast.NewIdent("Errorf"),
+							},
+							Args: []ast.Expr{
+								&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(funcName + ": %w")},
+								ast.NewIdent(errName),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// hasContextParam reports whether the first parameter of the given parameter
+// list is of type context.Context.
+func (gen *Gen) hasContextParam(params *ast.FieldList) bool {
+	if params == nil || len(params.List) == 0 {
+		return false
+	}
+	named, ok := types.Unalias(gen.pkg.TypesInfo.TypeOf(params.List[0].Type)).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
+
+// injectContext returns a copy of the given method parameter list with a `ctx
+// context.Context` first parameter; named ctx_ etc if colliding with other
+// parameters.
+func injectContext(methodParams *ast.FieldList, params []param) *ast.FieldList {
+	used := make(map[string]bool)
+	for _, param := range params {
+		used[param.name.Name] = true
+	}
+	name := "ctx"
+	for used[name] {
+		name += "_"
+	}
+	ctxField := &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(name)},
+		Type: &ast.SelectorExpr{
+			X:   ast.NewIdent("context"),
+			Sel: ast.NewIdent("Context"),
+		},
+	}
+	newParams := &ast.FieldList{
+		List: []*ast.Field{ctxField},
+	}
+	if methodParams != nil {
+		newParams.List = append(newParams.List, methodParams.List...)
+	}
+	return newParams
+}
+
+// expandResults returns an assignment of the results of the given forwarded
+// call, and a return statement of the assigned results, e.g.
+//
+//	result := Foo(recv)
+//	return result
+//
+// Named results are assigned directly; otherwise results are assigned to local
+// variables named result (single result) or r0, r1, etc (multiple results),
+// with a trailing error result named err.
+func (gen *Gen) expandResults(callExpr *ast.CallExpr, results *ast.FieldList, params []param) (ast.Stmt, *ast.ReturnStmt) {
+	var names []string
+	named := true
+	for _, field := range results.List {
+		if len(field.Names) == 0 {
+			named = false
+			break
+		}
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				named = false
+			}
+			names = append(names, name.Name)
+		}
+	}
+	tok := token.ASSIGN
+	if !named {
+		tok = token.DEFINE
+		used := make(map[string]bool)
+		for _, param := range params {
+			used[param.name.Name] = true
+		}
+		fields := flatResults(results)
+		names = names[:0]
+		for i, field := range fields {
+			name := fmt.Sprintf("r%d", i)
+			switch {
+			case i == len(fields)-1 && isError(gen.pkg.TypesInfo.TypeOf(field.Type)):
+				name = "err"
+			case len(fields) == 1:
+				name = "result"
+			}
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			names = append(names, name)
+		}
+	}
+	var lhs, rets []ast.Expr
+	for _, name := range names {
+		lhs = append(lhs, ast.NewIdent(name))
+		rets = append(rets, ast.NewIdent(name))
+	}
+	assignStmt := &ast.AssignStmt{
+		Lhs: lhs,
+		Tok: tok,
+		Rhs: []ast.Expr{callExpr},
+	}
+	returnStmt := &ast.ReturnStmt{
+		Results: rets,
+	}
+	return assignStmt, returnStmt
+}
+
+// flatResults returns the given result list with one field per result (e.g.
+// `(a, b int)` is flattened to `(a int, b int)`), with result names removed.
+func flatResults(results *ast.FieldList) []*ast.Field {
+	var fields []*ast.Field
+	for _, field := range results.List {
+		n := max(1, len(field.Names))
+		for range n {
+			fields = append(fields, &ast.Field{Type: field.Type})
+		}
+	}
+	return fields
+}
+
+// traceStmt returns the tracing call of the given method with an injected
+// context parameter, as specified by the tracing call template of the config.
+func (gen *Gen) traceStmt(config *Config, ctxName, recvName, funcName, methodName string) (ast.Stmt, error) {
+	template := strings.ReplaceAll(config.Trace, "{ctx}", ctxName)
+	expr, err := forwardFunc(template, recvName, funcName, methodName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid tracing call template %q", config.Trace)
+	}
+	if _, ok := expr.(*ast.CallExpr); !ok {
+		return nil, errors.Errorf("invalid tracing call template %q; expected call expression", config.Trace)
+	}
+	if len(config.TraceImport) > 0 {
+		gen.imports[config.TraceImport] = true
+	}
+	return &ast.ExprStmt{X: expr}, nil
+}
+
+// metricsStmts returns the metrics calls of the given method, as specified by
+// the metrics call templates of the generation options (-metrics and
+// -metrics-defer); the deferred call runs after the forwarded call returns.
+//
+// Example:
+//
+//	func (window *Window) Show() bool {
+//		metrics.Inc("ShowWindow")
+//		defer metrics.Since("ShowWindow", time.Now())
+//		return ShowWindow(window)
+//	}
+func (gen *Gen) metricsStmts(recvName, funcName, methodName string) ([]ast.Stmt, error) {
+	var stmts []ast.Stmt
+	if len(gen.opts.Metrics) > 0 {
+		call, err := metricsCall(gen.opts.Metrics, recvName, funcName, methodName)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		stmts = append(stmts, &ast.ExprStmt{X: call})
+	}
+	if len(gen.opts.MetricsDefer) > 0 {
+		call, err := metricsCall(gen.opts.MetricsDefer, recvName, funcName, methodName)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		stmts = append(stmts, &ast.DeferStmt{Call: call})
+	}
+	for _, importPath := range gen.opts.MetricsImports {
+		gen.imports[importPath] = true
+	}
+	return stmts, nil
+}
+
+// metricsCall returns the metrics call of the given metrics call template, with
+// placeholders as of forwarding templates.
+func metricsCall(template, recvName, funcName, methodName string) (*ast.CallExpr, error) {
+	expr, err := forwardFunc(template, recvName, funcName, methodName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid metrics call template %q", template)
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, errors.Errorf("invalid metrics call template %q; expected call expression", template)
+	}
+	return call, nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Window).Show": "func (window *Window) Show() error {\n\treturn ShowWindow(window)\n}",
+			},
+		},
+		{
+			name: "recover",
+			opts: &GenOptions{Recover: true},
+			want: map[string]string{
+				"(*Window).Show":          "func (window *Window) Show() (err error) {\n\tdefer func() {\n\t\tif rec := recover(); rec != nil {\n\t\t\terr = fmt.Errorf(\"panic in ShowWindow: %v\", rec)\n\t\t}\n\t}()\n\treturn ShowWindow(window)\n}",
+				"(*Window).GetWindowIcon": "func (window *Window) GetWindowIcon(size int32) (r0 []byte, err error) {\n\tdefer func() {\n\t\tif rec := recover(); rec != nil {\n\t\t\terr = fmt.Errorf(\"panic in GetWindowIcon: %v\", rec)\n\t\t}\n\t}()\n\treturn GetWindowIcon(window, size)\n}",
+				// not error-returning.
+				"(*Window).GetWindowTitle": "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "recover", g.opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			if g.opts.Recover {
+				// the tests of the fixture convert panics of forwarded calls.
+				runFixtureTests(t)
+			}
+		})
+	}
+}
+
+func TestInjectContext(t *testing.T) {
+	const tracingPath = "github.com/jupiterrider/purego-sdl3/tracing"
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected methods, by method expression.
+		want map[string]string
+		// expected imports.
+		imports []string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Window).GetSize": "func (window *Window) GetSize(w, h *int32) bool { return GetWindowSize(window, w, h) }",
+			},
+		},
+		{
+			name: "inject context",
+			opts: &GenOptions{InjectContext: true},
+			want: map[string]string{
+				"(*Window).GetSize": "func (window *Window) GetSize(ctx context.Context, w, h *int32) bool {\n\treturn GetWindowSize(window, w, h)\n}",
+				"(*Window).Destroy": "func (window *Window) Destroy(ctx context.Context) {\n\tDestroyWindow(window)\n}",
+			},
+			imports: []string{"context"},
+		},
+		{
+			name: "trace",
+			opts: &GenOptions{
+				InjectContext: true,
+				Config: &Config{
+					Trace:       `tracing.Trace({ctx}, "{method}")`,
+					TraceImport: tracingPath,
+				},
+			},
+			want: map[string]string{
+				"(*Window).GetSize": "func (window *Window) GetSize(ctx context.Context, w, h *int32) bool {\n\ttracing.Trace(ctx, \"GetSize\")\n\treturn GetWindowSize(window, w, h)\n}",
+				"(*Window).Destroy": "func (window *Window) Destroy(ctx context.Context) {\n\ttracing.Trace(ctx, \"Destroy\")\n\tDestroyWindow(window)\n}",
+			},
+			imports: []string{"context", tracingPath},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", g.opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			for _, importPath := range g.imports {
+				if !got.hasImport(importPath) {
+					t.Errorf("import %q not found", importPath)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapErrors(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Window).SetWindowOpacity": "func (window *Window) SetWindowOpacity(opacity float32) error {\n\treturn SetWindowOpacity(window, opacity)\n}",
+			},
+		},
+		{
+			name: "wrap errors",
+			opts: &GenOptions{WrapErrors: true},
+			want: map[string]string{
+				"(*Window).SetWindowOpacity": "func (window *Window) SetWindowOpacity(opacity float32) error {\n\terr := SetWindowOpacity(window, opacity)\n\tif err != nil {\n\t\terr = fmt.Errorf(\"SetWindowOpacity: %w\", err)\n\t}\n\treturn err\n}",
+				"(*Window).CreateRenderer":   "func (window *Window) CreateRenderer(name string) (*Renderer, error) {\n\tr0, err := CreateRenderer(window, name)\n\tif err != nil {\n\t\terr = fmt.Errorf(\"CreateRenderer: %w\", err)\n\t}\n\treturn r0, err\n}",
+				// not error-returning.
+				"(*Window).GetWindowTitle": "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "wrap", g.opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			if ok := got.hasImport("fmt"); ok != g.opts.WrapErrors {
+				t.Errorf("import of %q mismatch; expected %v, got %v", "fmt", g.opts.WrapErrors, ok)
+			}
+			if g.opts.WrapErrors {
+				// the tests of the fixture unwrap errors of forwarded calls.
+				runFixtureTests(t)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	const metricsPath = "github.com/jupiterrider/purego-sdl3/metrics"
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected methods, by method expression.
+		want map[string]string
+		// expected imports.
+		imports []string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Renderer).Clear": "func (renderer *Renderer) Clear() bool {\n\treturn RenderClear(renderer)\n}",
+			},
+		},
+		{
+			name: "metrics",
+			opts: &GenOptions{
+				Metrics:        `metrics.Inc("{func}")`,
+				MetricsImports: []string{metricsPath},
+			},
+			want: map[string]string{
+				"(*Renderer).Clear": "func (renderer *Renderer) Clear() bool {\n\tmetrics.Inc(\"RenderClear\")\n\treturn RenderClear(renderer)\n}",
+				"(*Window).Destroy": "func (window *Window) Destroy() {\n\tmetrics.Inc(\"DestroyWindow\")\n\tDestroyWindow(window)\n}",
+			},
+			imports: []string{metricsPath},
+		},
+		{
+			name: "metrics defer",
+			opts: &GenOptions{
+				Metrics:        `metrics.Inc("{func}")`,
+				MetricsDefer:   `metrics.Since("{method}", time.Now())`,
+				MetricsImports: []string{metricsPath, "time"},
+			},
+			want: map[string]string{
+				"(*Renderer).Clear":        "func (renderer *Renderer) Clear() bool {\n\tmetrics.Inc(\"RenderClear\")\n\tdefer metrics.Since(\"Clear\", time.Now())\n\treturn RenderClear(renderer)\n}",
+				"(*Window).CreateRenderer": "func (window *Window) CreateRenderer(name string) (*Renderer, error) {\n\tmetrics.Inc(\"CreateRenderer\")\n\tdefer metrics.Since(\"CreateRenderer\", time.Now())\n\treturn CreateRenderer(window, name)\n}",
+			},
+			imports: []string{metricsPath, "time"},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", g.opts)
+			got.checkMethods(t, g.want)
+			for _, importPath := range g.imports {
+				if !got.hasImport(importPath) {
+					t.Errorf("import of %q missing", importPath)
+				}
+			}
+			if len(g.imports) == 0 && len(got.file.Imports) > 0 {
+				t.Errorf("unexpected imports; got %d imports", len(got.file.Imports))
+			}
+		})
+	}
+}
+
+func TestMetricsValidate(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected substring of error.
+		want string
+	}{
+		{
+			name: "not a call",
+			opts: &GenOptions{Metrics: `metrics.Calls["{func}"]`},
+			want: "expected call expression",
+		},
+		{
+			name: "invalid template",
+			opts: &GenOptions{MetricsDefer: `metrics.Since("{func}", `},
+			want: "invalid metrics call template",
+		},
+		{
+			name: "import without template",
+			opts: &GenOptions{MetricsImports: []string{"github.com/jupiterrider/purego-sdl3/metrics"}},
+			want: "metrics import (-metrics-import) requires a metrics call template (-metrics or -metrics-defer)",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			err := g.opts.Validate()
+			if err == nil || !strings.Contains(err.Error(), g.want) {
+				t.Errorf("error mismatch; expected %q, got %v", g.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Exit codes of the check subcommand; stale and invalid config are combined
+// (bitwise or) when both checks fail.
+const (
+	// generated file is not up to date.
+	exitStale = 1
+	// config is invalid.
+	exitInvalidConfig = 2
+	// check could not be run (e.g. package load or generation failure).
+	exitError = 4
+)
+
+// runCheck runs the check subcommand, verifying that the config file is valid
+// (i.e. conforms to the JSON Schema and all configured types exist) and that
+// the output file is up to date. All failures are reported to w, and the exit
+// code is returned. If the checks could not be run, exitError is returned
+// together with the error.
+func runCheck(w io.Writer, pkgPath, output, configPath, schemaPath string, opts *GenOptions) (int, error) {
+	if len(output) == 0 {
+		return exitError, errors.New("check mode requires an output path (-o)")
+	}
+	code := 0
+	if len(configPath) > 0 {
+		config, err := loadConfig(configPath, schemaPath)
+		if err != nil {
+			fmt.Fprintf(w, "invalid config: %v\n", err)
+			// staleness cannot be determined without a valid config.
+			return exitInvalidConfig, nil
+		}
+		opts.Config = config
+	}
+	pkg, err := loadPkg(pkgPath, opts)
+	if err != nil {
+		return exitError, errors.WithStack(err)
+	}
+	if err := opts.Validate(); err != nil {
+		return exitError, errors.WithStack(err)
+	}
+	gen := &Gen{
+		pkg:    pkg,
+		opts:   opts,
+		output: output,
+	}
+	gen.Reset()
+	// check config before generation, as invalid entries may fail generation.
+	errs := gen.checkConfig()
+	for _, err := range errs {
+		fmt.Fprintf(w, "invalid config: %v\n", err)
+	}
+	if len(errs) > 0 {
+		code |= exitInvalidConfig
+	}
+	upToDate, err := gen.checkUpToDate(output)
+	if err != nil {
+		if len(errs) > 0 {
+			// staleness cannot be determined without a valid config.
+			return code, nil
+		}
+		return exitError, errors.WithStack(err)
+	}
+	if !upToDate {
+		fmt.Fprintf(w, "%s is not up to date; re-run genmethods\n", output)
+		code |= exitStale
+	}
+	return code, nil
+}
+
+// checkUpToDate generates methods and reports whether the given output file is
+// up to date.
+func (gen *Gen) checkUpToDate(output string) (bool, error) {
+	if err := gen.generate(); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return gen.upToDate(output)
+}
+
+// checkConfig verifies that the types of the user-provided config defined in
+// the analyzed package exist, and that the entries of sections naming types
+// are valid for their type (e.g. accessor expressions and scope functions).
+// Patterns and types of other packages are not checked.
+func (gen *Gen) checkConfig() []error {
+	config := gen.opts.Config
+	if config == nil {
+		return nil
+	}
+	var errs []error
+	checkType := func(section, typStr string) types.Type {
+		name := strings.TrimPrefix(typStr, "*")
+		if strings.ContainsAny(name, "*?[") {
+			return nil // skip patterns.
+		}
+		pos := strings.LastIndex(name, ".")
+		if pos == -1 || name[:pos] != gen.pkg.PkgPath {
+			return nil // skip types of other packages.
+		}
+		typ, err := gen.lookupType(typStr)
+		if err != nil {
+			errs = append(errs, errors.Errorf("%s: unable to locate type %q in package %q", section, typStr, gen.pkg.PkgPath))
+			return nil
+		}
+		return typ
+	}
+	for _, typStr := range config.Types {
+		checkType("types", typStr)
+	}
+	for _, typStr := range sortedKeys(config.Forward) {
+		checkType("forward", typStr)
+	}
+	for _, typStr := range sortedKeys(config.Receivers) {
+		checkType("receivers", typStr)
+	}
+	for _, ifaceStr := range sortedKeys(config.Interfaces) {
+		if typ := checkType("interfaces", ifaceStr); typ != nil && !types.IsInterface(typ) {
+			errs = append(errs, errors.Errorf("interfaces: type %q is not an interface", ifaceStr))
+		}
+		for _, typStr := range config.Interfaces[ifaceStr] {
+			checkType("interfaces", typStr)
+		}
+	}
+	for _, typStr := range sortedKeys(config.Accessors) {
+		if typ := checkType("accessors", typStr); typ != nil {
+			template := config.Accessors[typStr]
+			if _, err := gen.accessorType(typ, template); err != nil {
+				errs = append(errs, errors.Errorf("accessors: invalid accessor %q of receiver type %q; %v", template, typStr, err))
+			}
+		}
+	}
+	for _, typStr := range sortedKeys(config.RecvNames) {
+		checkType("recv_names", typStr)
+		if recvName := config.RecvNames[typStr]; !token.IsIdentifier(recvName) {
+			errs = append(errs, errors.Errorf("recv_names: invalid receiver name %q of %q", recvName, typStr))
+		}
+	}
+	for _, typStr := range sortedKeys(config.SafeWrappers) {
+		checkType("safe_wrappers", typStr)
+		if wrapper := config.SafeWrappers[typStr]; !token.IsIdentifier(wrapper.Name) {
+			errs = append(errs, errors.Errorf("safe_wrappers: invalid name %q of safe wrapper of %q", wrapper.Name, typStr))
+		}
+	}
+	for _, typStr := range sortedKeys(config.Scopes) {
+		typ := checkType("scopes", typStr)
+		for _, scope := range config.Scopes[typStr] {
+			if !token.IsIdentifier(scope.Name) {
+				errs = append(errs, errors.Errorf("scopes: invalid name %q of scoped method on %q", scope.Name, typStr))
+			}
+			if typ == nil {
+				continue
+			}
+			for _, funcName := range []string{scope.Acquire, scope.Release} {
+				if _, err := gen.scopeFunc(typ, scope.Name, funcName); err != nil {
+					errs = append(errs, errors.Errorf("scopes: %v", err))
+				}
+			}
+		}
+	}
+	for _, typStr := range sortedKeys(config.ConstructorPrefixes) {
+		if typStr != "*" {
+			checkType("constructor_prefixes", typStr)
+		}
+	}
+	return errs
+}
+
+// sortedKeys returns the keys of the given map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	golden := []struct {
+		name string
+		// config file contents; no config file if empty.
+		config string
+		// generate output file before check.
+		generate bool
+		// check arguments following -pkg.
+		args []string
+		// expected exit code.
+		code int
+		// expected substring of standard error.
+		stderr string
+	}{
+		{
+			name:     "up to date",
+			config:   `{"types": ["*` + fixturePkgPath + `.Renderer"]}`,
+			generate: true,
+			args:     []string{"-o", "sdl/methods_gen.go"},
+			code:     0,
+		},
+		{
+			name:   "stale",
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitStale,
+			stderr: "sdl/methods_gen.go is not up to date",
+		},
+		{
+			name:     "unknown type",
+			config:   `{"types": ["*` + fixturePkgPath + `.Missing"]}`,
+			generate: true,
+			args:     []string{"-o", "sdl/methods_gen.go"},
+			code:     exitInvalidConfig,
+			stderr:   `types: unable to locate type "*` + fixturePkgPath + `.Missing"`,
+		},
+		{
+			name:   "accessor expression",
+			config: `{"accessors": {"*` + fixturePkgPath + `.Renderer": "{recv}.handle"}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig,
+			stderr: `accessors: invalid accessor "{recv}.handle" of receiver type "*` + fixturePkgPath + `.Renderer"`,
+		},
+		{
+			name:   "accessor type",
+			config: `{"accessors": {"*` + fixturePkgPath + `.Missing": "{recv}.handle"}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig,
+			stderr: `accessors: unable to locate type "*` + fixturePkgPath + `.Missing"`,
+		},
+		{
+			name:   "receiver name",
+			config: `{"recv_names": {"*` + fixturePkgPath + `.Renderer": "func"}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig,
+			stderr: `recv_names: invalid receiver name "func"`,
+		},
+		{
+			name:   "receiver name type",
+			config: `{"recv_names": {"*` + fixturePkgPath + `.Missing": "m"}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig | exitStale,
+			stderr: `recv_names: unable to locate type "*` + fixturePkgPath + `.Missing"`,
+		},
+		{
+			name:   "safe wrapper type",
+			config: `{"safe_wrappers": {"*` + fixturePkgPath + `.Missing": {"name": "SafeMissing"}}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig | exitStale,
+			stderr: `safe_wrappers: unable to locate type "*` + fixturePkgPath + `.Missing"`,
+		},
+		{
+			name:   "safe wrapper name",
+			config: `{"safe_wrappers": {"*` + fixturePkgPath + `.Renderer": {"name": "type"}}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig,
+			stderr: `safe_wrappers: invalid name "type"`,
+		},
+		{
+			name:   "scope type",
+			config: `{"scopes": {"*` + fixturePkgPath + `.Missing": [{"name": "WithLock", "acquire": "LockSurface", "release": "UnlockSurface"}]}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig,
+			stderr: `scopes: unable to locate type "*` + fixturePkgPath + `.Missing"`,
+		},
+		{
+			name:   "scope function",
+			config: `{"scopes": {"*` + fixturePkgPath + `.Surface": [{"name": "WithLock", "acquire": "LockWindow", "release": "UnlockSurface"}]}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig,
+			stderr: `scopes: unable to locate function "LockWindow" of scoped method "WithLock"`,
+		},
+		{
+			name:   "constructor prefixes type",
+			config: `{"constructor_prefixes": {"*": ["Create"], "*` + fixturePkgPath + `.Missing": ["Open"]}}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig | exitStale,
+			stderr: `constructor_prefixes: unable to locate type "*` + fixturePkgPath + `.Missing"`,
+		},
+		{
+			name:   "schema violation",
+			config: `{"types": "*` + fixturePkgPath + `.Renderer"}`,
+			args:   []string{"-o", "sdl/methods_gen.go"},
+			code:   exitInvalidConfig,
+			stderr: "invalid config",
+		},
+		{
+			name:   "missing output",
+			code:   exitError,
+			stderr: "check mode requires an output path (-o)",
+		},
+		{
+			name:   "load failure",
+			args:   []string{"-o", "sdl/methods_gen.go", "-pkg", fixturePkgPath + "/missing"},
+			code:   exitError,
+			stderr: "missing",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "sdl")
+			var configArgs []string
+			if len(g.config) > 0 {
+				if err := os.WriteFile(filepath.Join(dir, "genmethods.json"), []byte(g.config), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				configArgs = []string{"-config", "genmethods.json"}
+			}
+			if g.generate {
+				args := append([]string{"-pkg", fixturePkgPath, "-o", "sdl/methods_gen.go"}, configArgs...)
+				if _, stderr, err := runMain(t, dir, args...); err != nil {
+					t.Fatalf("unable to run genmethods; %v\n%s", err, stderr)
+				}
+			}
+			args := append([]string{"check", "-pkg", fixturePkgPath}, configArgs...)
+			args = append(args, g.args...)
+			_, stderr, err := runMain(t, dir, args...)
+			code := 0
+			if err != nil {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) {
+					t.Fatalf("unable to run genmethods check; %v", err)
+				}
+				code = exitErr.ExitCode()
+			}
+			if code != g.code {
+				t.Errorf("exit code mismatch; expected %d, got %d\n%s", g.code, code, stderr)
+			}
+			if !bytes.Contains(stderr, []byte(g.stderr)) {
+				t.Errorf("standard error mismatch; expected to contain %q, got %q", g.stderr, stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// slicePair is a (count, pointer) parameter pair of a source function (e.g.
+// `count int, points *Point`), collapsed into a single slice parameter of the
+// generated method (e.g. `points []Point`).
+type slicePair struct {
+	// flat index of count parameter.
+	countIndex int
+	// flat index of pointer parameter.
+	ptrIndex int
+	// name of local variable holding the address of the first slice element.
+	ptrVar string
+}
+
+// slicePairs returns the (count, pointer) parameter pairs of the given
+// function collapsed into slice parameters, as specified by the user-provided
+// config.
+func (gen *Gen) slicePairs(funcDecl *ast.FuncDecl, params []param, recvIndex int) ([]slicePair, error) {
+	config := gen.opts.Config
+	if config == nil {
+		return nil, nil
+	}
+	funcName := funcDecl.Name.String()
+	used := make(map[string]bool)
+	for _, param := range params {
+		used[param.name.Name] = true
+	}
+	var pairs []slicePair
+	for _, sliceParam := range config.Slices[funcName] {
+		indexOf := func(name string) (int, error) {
+			i := slices.IndexFunc(params, func(param param) bool {
+				return param.name.Name == name
+			})
+			if i == -1 {
+				return 0, errors.Errorf("slice parameter %q of function %q not found", name, funcName)
+			}
+			if i == recvIndex {
+				return 0, errors.Errorf("slice parameter %q of function %q is the receiver parameter", name, funcName)
+			}
+			return i, nil
+		}
+		countIndex, err := indexOf(sliceParam.Count)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ptrIndex, err := indexOf(sliceParam.Ptr)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if countIndex-ptrIndex != 1 && ptrIndex-countIndex != 1 {
+			return nil, errors.Errorf("count parameter %q and pointer parameter %q of function %q are not adjacent", sliceParam.Count, sliceParam.Ptr, funcName)
+		}
+		countType := gen.pkg.TypesInfo.TypeOf(params[countIndex].field.Type)
+		if basic, ok := countType.Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 {
+			return nil, errors.Errorf("count parameter %q of function %q has non-integer type %v", sliceParam.Count, funcName, countType)
+		}
+		if _, ok := params[ptrIndex].field.Type.(*ast.StarExpr); !ok {
+			return nil, errors.Errorf("pointer parameter %q of function %q has non-pointer type %v", sliceParam.Ptr, funcName, gen.pkg.TypesInfo.TypeOf(params[ptrIndex].field.Type))
+		}
+		ptrVar := sliceParam.Ptr + "Ptr"
+		for used[ptrVar] {
+			ptrVar += "_"
+		}
+		used[ptrVar] = true
+		pair := slicePair{
+			countIndex: countIndex,
+			ptrIndex:   ptrIndex,
+			ptrVar:     ptrVar,
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// collapseParams returns the method parameter list of the given function
+// parameters, without the receiver parameter and with (count, pointer)
+// parameter pairs collapsed into slice parameters.
+func collapseParams(params []param, recvIndex int, pairs []slicePair) *ast.FieldList {
+	newParams := &ast.FieldList{}
+loop:
+	for i, param := range params {
+		if i == recvIndex {
+			continue // skip receiver parameter
+		}
+		typ := param.field.Type
+		for _, pair := range pairs {
+			switch i {
+			case pair.countIndex:
+				continue loop // count derived from slice length
+			case pair.ptrIndex:
+				typ = &ast.ArrayType{Elt: typ.(*ast.StarExpr).X}
+			}
+		}
+		field := &ast.Field{
+			Names: []*ast.Ident{param.name},
+			Type:  typ,
+		}
+		newParams.List = append(newParams.List, field)
+	}
+	return newParams
+}
+
+// collapseArgs updates the forwarded call arguments of (count, pointer)
+// parameter pairs to the length of and the address of the first element of
+// the slice parameter respectively, and returns the statements computing the
+// addresses, e.g.
+//
+//	var pointsPtr *Point
+//	if len(points) > 0 {
+//		pointsPtr = &points[0]
+//	}
+func collapseArgs(args []ast.Expr, params []param, pairs []slicePair) []ast.Stmt {
+	var stmts []ast.Stmt
+	for _, pair := range pairs {
+		countParam, ptrParam := params[pair.countIndex], params[pair.ptrIndex]
+		lenExpr := &ast.CallExpr{
+			Fun:  ast.NewIdent("len"),
+			Args: []ast.Expr{ast.NewIdent(ptrParam.name.Name)},
+		}
+		args[pair.countIndex] = &ast.CallExpr{
+			Fun:  countParam.field.Type,
+			Args: []ast.Expr{lenExpr},
+		}
+		args[pair.ptrIndex] = ast.NewIdent(pair.ptrVar)
+		declStmt := &ast.DeclStmt{
+			Decl: &ast.GenDecl{
+				Tok: token.VAR,
+				Specs: []ast.Spec{
+					&ast.ValueSpec{
+						Names: []*ast.Ident{ast.NewIdent(pair.ptrVar)},
+						Type:  ptrParam.field.Type,
+					},
+				},
+			},
+		}
+		ifStmt := &ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X:  lenExpr,
+				Op: token.GTR,
+				Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+			},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{ast.NewIdent(pair.ptrVar)},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{
+							&ast.UnaryExpr{
+								Op: token.AND,
+								X: &ast.IndexExpr{
+									X:     ast.NewIdent(ptrParam.name.Name),
+									Index: &ast.BasicLit{Kind: token.INT, Value: "0"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		stmts = append(stmts, declStmt, ifStmt)
+	}
+	return stmts
+}
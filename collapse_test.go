@@ -0,0 +1,102 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSlices(t *testing.T) {
+	golden := []struct {
+		name   string
+		slices map[string][]SliceParam
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "pointer before count",
+			slices: map[string][]SliceParam{
+				"RenderPoints": {{Count: "count", Ptr: "points"}},
+			},
+			want: map[string]string{
+				// count of int32 type converted from slice length.
+				"(*Renderer).RenderPoints": "func (renderer *Renderer) RenderPoints(points []Point) bool {\n\tvar pointsPtr *Point\n\tif len(points) > 0 {\n\t\tpointsPtr = &points[0]\n\t}\n\treturn RenderPoints(renderer, pointsPtr, int32(len(points)))\n}",
+				// not collapsed.
+				"(*Renderer).RenderLines": "func (renderer *Renderer) RenderLines(count int, points *Point) bool {\n\treturn RenderLines(renderer, count, points)\n}",
+			},
+		},
+		{
+			name: "count before pointer",
+			slices: map[string][]SliceParam{
+				"RenderPoints": {{Count: "count", Ptr: "points"}},
+				"RenderLines":  {{Count: "count", Ptr: "points"}},
+			},
+			want: map[string]string{
+				"(*Renderer).RenderLines": "func (renderer *Renderer) RenderLines(points []Point) bool {\n\tvar pointsPtr *Point\n\tif len(points) > 0 {\n\t\tpointsPtr = &points[0]\n\t}\n\treturn RenderLines(renderer, int(len(points)), pointsPtr)\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "slices", &GenOptions{Config: &Config{Slices: g.slices}})
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			// the tests of the fixture forward the first element of slices, and nil
+			// pointers of empty slices.
+			runFixtureTests(t)
+		})
+	}
+}
+
+func TestSlicesError(t *testing.T) {
+	golden := []struct {
+		name     string
+		funcName string
+		slice    SliceParam
+		// expected error.
+		want string
+	}{
+		{
+			name:     "unknown parameter",
+			funcName: "RenderPoints",
+			slice:    SliceParam{Count: "n", Ptr: "points"},
+			want:     `slice parameter "n" of function "RenderPoints" not found`,
+		},
+		{
+			name:     "receiver parameter",
+			funcName: "RenderPoints",
+			slice:    SliceParam{Count: "count", Ptr: "renderer"},
+			want:     `slice parameter "renderer" of function "RenderPoints" is the receiver parameter`,
+		},
+		{
+			name:     "not adjacent",
+			funcName: "RenderGeometry",
+			slice:    SliceParam{Count: "count", Ptr: "vertices"},
+			want:     `count parameter "count" and pointer parameter "vertices" of function "RenderGeometry" are not adjacent`,
+		},
+		{
+			name:     "non-integer count",
+			funcName: "RenderScaled",
+			slice:    SliceParam{Count: "scale", Ptr: "points"},
+			want:     `count parameter "scale" of function "RenderScaled" has non-integer type float32`,
+		},
+		{
+			name:     "non-pointer",
+			funcName: "RenderGeometry",
+			slice:    SliceParam{Count: "count", Ptr: "color"},
+			want:     `pointer parameter "color" of function "RenderGeometry" has non-pointer type uint32`,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "slices")
+			opts := &GenOptions{Config: &Config{Slices: map[string][]SliceParam{g.funcName: {g.slice}}}}
+			_, err := genMethods(fixturePkgPath, filepath.Join(dir, "sdl", "methods_gen.go"), opts)
+			if err == nil || err.Error() != g.want {
+				t.Errorf("error mismatch; expected %q, got %v", g.want, err)
+			}
+		})
+	}
+}
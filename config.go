@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Config specifies user-provided configuration of method generation, as
+// read from a JSON or TOML config file. Entries are merged with the built-in default
+// tables, taking precedence over them.
+type Config struct {
+	// Receiver types for which methods are generated (e.g.
+	// "*github.com/jupiterrider/purego-sdl3/sdl.Window").
+	Types []string `json:"types,omitempty"`
+	// Map from function name to method name.
+	Rename map[string]string `json:"rename,omitempty"`
+	// Map from receiver type to forwarding target expression template. By
+	// default generated methods forward to the package function, passing the
+	// receiver as first argument (e.g. `Foo(recv, args)`). A forwarding
+	// template instead specifies the function to call with the remaining
+	// arguments (e.g. "{recv}.inner.{func}" forwards to
+	// `recv.inner.Foo(args)`).
+	//
+	// Placeholders:
+	//
+	//    {recv}    receiver name
+	//    {func}    source function name
+	//    {method}  generated method name
+	Forward map[string]string `json:"forward,omitempty"`
+	// Map from wrapper receiver type (e.g. "*github.com/foo/sdl.Window") to
+	// accessor expression template of the raw handle held by the wrapper (e.g.
+	// "{recv}.handle"). Functions with a first parameter of the raw handle type
+	// are converted to methods on the wrapper type, forwarding the accessor
+	// expression in place of the receiver.
+	Accessors map[string]string `json:"accessors,omitempty"`
+	// Map from interface type to concrete receiver types satisfying the
+	// interface (e.g. "github.com/foo/sdl.Drawable" to
+	// ["*github.com/foo/sdl.Window"]). Functions with an interface first
+	// parameter are converted to methods on each concrete type
+	// (-interface-recv).
+	Interfaces map[string][]string `json:"interfaces,omitempty"`
+	// Map from receiver base type (e.g. "github.com/foo/sdl.Window") to pinned
+	// receiver kind, either "pointer" or "value". Forwarded calls take the
+	// address of or dereference the receiver as needed.
+	Receivers map[string]string `json:"receivers,omitempty"`
+	// Map from function name or path.Match pattern of function names (e.g.
+	// "Get*") to pinned receiver kind of the generated method, either
+	// "pointer" or "value"; allowing a mix of value and pointer receivers
+	// within one type. Takes precedence over receivers pinned per type.
+	FuncReceivers map[string]string `json:"func_receivers,omitempty"`
+	// Map from receiver type (e.g. "*github.com/foo/sdl.Renderer") to receiver
+	// name of generated methods (e.g. "r"), regardless of the name of the
+	// receiver parameter of the source function.
+	RecvNames map[string]string `json:"recv_names,omitempty"`
+	// Map from function name to receiver parameter name, overriding the
+	// receiver priority in any-position mode (e.g. "BlitSurface" to "dst").
+	RecvParams map[string]string `json:"recv_params,omitempty"`
+	// Acronyms kept all-caps when converting snake_case function names to
+	// CamelCase method names (-sanitize-names), in addition to the default
+	// acronyms (e.g. "SDL", "IO").
+	Acronyms []string `json:"acronyms,omitempty"`
+	// Map from function name to (count, pointer) parameter pairs collapsed
+	// into slice parameters of the generated method (e.g. "RenderPoints" to
+	// [{"count": "count", "ptr": "points"}] generates `RenderPoints(points
+	// []Point)` for `RenderPoints(r *Renderer, count int32, points *Point)`).
+	Slices map[string][]SliceParam `json:"slices,omitempty"`
+	// Functions converted to String methods satisfying fmt.Stringer (e.g.
+	// "GetWindowTitle"); the functions must take only the receiver and return
+	// exactly one string.
+	Stringers []string `json:"stringers,omitempty"`
+	// Map from function name to parameter renames of the generated method,
+	// mapping original to new parameter names (e.g. "SetPosition" to {"p0":
+	// "x", "p1": "y"}).
+	ParamNames map[string]map[string]string `json:"param_names,omitempty"`
+	// Tracing call template of methods with an injected context parameter
+	// (-inject-context), called before forwarding (e.g.
+	// "tracing.Trace({ctx}, \"{method}\")"). Placeholders as of forwarding
+	// templates, and {ctx} for the context parameter name.
+	Trace string `json:"trace,omitempty"`
+	// Import path of the package referenced by the tracing call template
+	// (e.g. "example.com/tracing").
+	TraceImport string `json:"trace_import,omitempty"`
+	// Selection of preferred variants among functions sharing a stem;
+	// non-preferred variants are skipped.
+	Variants *Variants `json:"variants,omitempty"`
+	// Map from receiver type (e.g. "*github.com/foo/sdl.Renderer") to
+	// thread-safety wrapper type generated around the receiver type, with
+	// methods holding a mutex while forwarding to the generated methods.
+	SafeWrappers map[string]*SafeWrapper `json:"safe_wrappers,omitempty"`
+	// Map from receiver type (e.g. "*github.com/foo/sdl.Surface") to scoped
+	// methods generated on the receiver type (e.g. WithLock), calling an
+	// acquire function, running a callback and deferring the paired release
+	// function.
+	Scopes map[string][]*Scope `json:"scopes,omitempty"`
+	// Map from function name to additional documentation appended to the doc
+	// comment of the generated method (-merge-doc), as plain text without
+	// comment markers (e.g. usage examples or warnings).
+	Docs map[string]string `json:"docs,omitempty"`
+	// Map from result type of constructors (e.g. "*github.com/foo/sdl.Camera"),
+	// or "*" for all types, to name prefixes replaced by "New" in the names of
+	// generated constructors (-gen-constructors); e.g. ["Open"] to name the
+	// constructor of OpenCamera NewCamera. Defaults to ["Create"]; an empty list
+	// keeps the names of constructors.
+	ConstructorPrefixes map[string][]string `json:"constructor_prefixes,omitempty"`
+}
+
+// Variants specifies the selection of preferred variants among functions
+// sharing a stem, i.e. the function name without a variant suffix (e.g.
+// GetWindowSize and GetWindowSizeInPixels share the stem GetWindowSize).
+type Variants struct {
+	// Variant suffixes stripped from function names to derive stems (e.g.
+	// "InPixels").
+	Suffixes []string `json:"suffixes"`
+	// Map from stem to preferred variant (e.g. "GetWindowSize" to
+	// "GetWindowSizeInPixels"). By default, the function without variant
+	// suffix is preferred.
+	Prefer map[string]string `json:"prefer,omitempty"`
+}
+
+// SliceParam specifies a (count, pointer) parameter pair collapsed into a
+// slice parameter.
+type SliceParam struct {
+	// name of count parameter.
+	Count string `json:"count"`
+	// name of pointer parameter; also the name of the slice parameter.
+	Ptr string `json:"ptr"`
+}
+
+// loadConfig loads the JSON config file at the given path, after validating it
+// against the JSON Schema at schemaPath (or the default schema if empty).
+// Config files with a .toml extension are TOML-encoded, with the same keys as
+// JSON config files.
+func loadConfig(path, schemaPath string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if filepath.Ext(path) == ".toml" {
+		if data, err = tomlToJSON(path, data); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	s, err := loadSchema(schemaPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := validateConfig(path, data, s); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse config file %q", path)
+	}
+	return config, nil
+}
+
+// tomlToJSON converts the given TOML-encoded config file to JSON, so that it is
+// validated and decoded as JSON config files.
+func tomlToJSON(path string, data []byte) ([]byte, error) {
+	var v map[string]any
+	if err := toml.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse config file %q", path)
+	}
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return jsonData, nil
+}
+
+// forwardFunc returns the function expression to forward calls to, as
+// specified by the given forwarding template.
+func forwardFunc(template, recvName, funcName, methodName string) (ast.Expr, error) {
+	r := strings.NewReplacer(
+		"{recv}", recvName,
+		"{func}", funcName,
+		"{method}", methodName,
+	)
+	s := r.Replace(template)
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid forwarding template %q", template)
+	}
+	return stripPos(expr), nil
+}
+
+// stripPos clears the positions of the given parsed expression (e.g. of
+// identifiers, call expressions and type expressions), so that it may be
+// inserted into generated code.
+func stripPos(expr ast.Expr) ast.Expr {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			n.NamePos = 0
+		case *ast.BasicLit:
+			n.ValuePos = 0
+		case *ast.CallExpr:
+			n.Lparen, n.Rparen = 0, 0
+		case *ast.IndexExpr:
+			n.Lbrack, n.Rbrack = 0, 0
+		case *ast.ParenExpr:
+			n.Lparen, n.Rparen = 0, 0
+		case *ast.StarExpr:
+			n.Star = 0
+		case *ast.UnaryExpr:
+			n.OpPos = 0
+		case *ast.ArrayType:
+			n.Lbrack = 0
+		case *ast.ChanType:
+			n.Begin, n.Arrow = 0, 0
+		case *ast.Ellipsis:
+			n.Ellipsis = 0
+		case *ast.FieldList:
+			n.Opening, n.Closing = 0, 0
+		case *ast.FuncType:
+			n.Func = 0
+		case *ast.IndexListExpr:
+			n.Lbrack, n.Rbrack = 0, 0
+		case *ast.InterfaceType:
+			n.Interface = 0
+		case *ast.MapType:
+			n.Map = 0
+		case *ast.StructType:
+			n.Struct = 0
+		}
+		return true
+	})
+	return expr
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/mewpkg/clog"
+)
+
+// parseConstructors generates constructors of the wrapper types of the wrapper
+// package (-gen-constructors), forwarding to the constructors of the wrapped
+// package; i.e. functions without parameters of a receiver type, returning a
+// value of a wrapped type optionally followed by an error (e.g.
+// `CreateWindow(title string) (w *Window)`). Constructors are generated for
+// wrapper types with generated methods, and are thus parsed after the methods.
+func (gen *Gen) parseConstructors() {
+	// map from wrapper type name to wrapped type.
+	wrapped := make(map[string]types.Type)
+	// names declared by the wrapper package.
+	used := make(map[string]bool)
+	for _, method := range gen.methods {
+		wrapperName := typeName(method.RecvType)
+		wrapped[wrapperName] = gen.wrappedType(method.RecvType)
+		used[wrapperName] = true
+		used[wrapCtorName(wrapperName)] = true
+	}
+	type ctor struct {
+		decl        *ast.FuncDecl
+		wrapperName string
+	}
+	var ctors []ctor
+	for _, file := range gen.pkg.Syntax {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv != nil || skipDirective(funcDecl) {
+				continue
+			}
+			if wrapperName, ok := gen.constructorOf(funcDecl, wrapped); ok {
+				ctors = append(ctors, ctor{decl: funcDecl, wrapperName: wrapperName})
+				used[funcDecl.Name.Name] = true
+			}
+		}
+	}
+	for _, c := range ctors {
+		funcName := c.decl.Name.Name
+		ctorName := gen.constructorName(funcName, wrapped[c.wrapperName])
+		if ctorName != funcName && used[ctorName] {
+			clog.Warnf("constructor name %q of function %q collides with another declaration of the wrapper package; using %q", ctorName, funcName, funcName)
+			ctorName = funcName
+		}
+		used[ctorName] = true
+		ctorDecl := gen.genConstructor(c.decl, c.wrapperName, ctorName)
+		gen.constructors[c.wrapperName] = append(gen.constructors[c.wrapperName], ctorDecl)
+	}
+}
+
+// constructorOf reports whether the given function is a constructor of a
+// wrapped type, and if so returns the name of its wrapper type.
+func (gen *Gen) constructorOf(decl *ast.FuncDecl, wrapped map[string]types.Type) (string, bool) {
+	if !decl.Name.IsExported() || decl.Type.TypeParams != nil {
+		return "", false
+	}
+	sig, ok := gen.pkg.TypesInfo.Defs[decl.Name].Type().(*types.Signature)
+	if !ok || hasUnexported(sig) {
+		return "", false
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		if _, ok := gen.methodRecvType(sig.Params().At(i).Type()); ok {
+			return "", false // method of receiver type.
+		}
+	}
+	results := sig.Results()
+	switch {
+	case results.Len() == 1:
+	case results.Len() == 2 && isError(results.At(1).Type()):
+	default:
+		return "", false
+	}
+	resultType := types.Unalias(results.At(0).Type())
+	wrapperName := typeName(resultType)
+	wrappedType, ok := wrapped[wrapperName]
+	if !ok || !types.Identical(resultType, types.Unalias(wrappedType)) {
+		return "", false
+	}
+	return wrapperName, true
+}
+
+// genConstructor returns the constructor with the given name of the given
+// wrapper type, forwarding to the given constructor of the wrapped package and
+// keeping the parameters and result names of the wrapped constructor.
+//
+// Example:
+//
+//	// CreateWindow creates a window.
+//	func NewWindow(title string) (w *Window) {
+//		return WrapWindow(sdl.CreateWindow(title))
+//	}
+//
+//	// CreateRenderer creates a renderer.
+//	func NewRenderer(name string) (*Renderer, error) {
+//		v, err := sdl.CreateRenderer(name)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return WrapRenderer(v), nil
+//	}
+func (gen *Gen) genConstructor(decl *ast.FuncDecl, wrapperName, ctorName string) *ast.FuncDecl {
+	clog.Debugf("constructor %q of wrapper type %q forwarding to %q", ctorName, wrapperName, decl.Name)
+	wrapPkg := gen.wrapImport()
+	// qualified types of parameters and results (e.g. `io.Reader`).
+	gen.addTypeImports(decl.Type)
+	params := gen.qualifyFields(nameParams(decl.Type.Params), wrapPkg)
+	// name the wrapped value suffixed with underscores if colliding with
+	// parameters or results.
+	used := make(map[string]bool)
+	var args []ast.Expr
+	variadic := false
+	for _, field := range params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			variadic = true
+		}
+		for _, name := range field.Names {
+			used[name.Name] = true
+			args = append(args, ast.NewIdent(name.Name))
+		}
+	}
+	results := gen.qualifyFields(decl.Type.Results, wrapPkg)
+	for _, field := range results.List {
+		for _, name := range field.Names {
+			used[name.Name] = true
+		}
+	}
+	// spell the result as the wrapper type, keeping result names.
+	results.List[0].Type = &ast.StarExpr{X: ast.NewIdent(wrapperName)}
+	call := &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: ast.NewIdent(wrapPkg), Sel: ast.NewIdent(decl.Name.Name)},
+		Args: args,
+	}
+	if variadic {
+		call.Ellipsis = 1
+	}
+	wrap := func(x ast.Expr) ast.Expr {
+		return &ast.CallExpr{
+			Fun:  ast.NewIdent(wrapCtorName(wrapperName)),
+			Args: []ast.Expr{x},
+		}
+	}
+	var stmts []ast.Stmt
+	if len(decl.Type.Results.List) == 1 && len(decl.Type.Results.List[0].Names) <= 1 {
+		stmts = []ast.Stmt{
+			&ast.ReturnStmt{Results: []ast.Expr{wrap(call)}},
+		}
+	} else {
+		// err is assigned rather than declared if declared by parameters or
+		// results.
+		value := "v"
+		for used[value] {
+			value += "_"
+		}
+		stmts = []ast.Stmt{
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{ast.NewIdent(value), ast.NewIdent("err")},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{call},
+			},
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: ast.NewIdent("err"), Op: token.NEQ, Y: ast.NewIdent("nil")},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("nil"), ast.NewIdent("err")}},
+					},
+				},
+			},
+			&ast.ReturnStmt{Results: []ast.Expr{wrap(ast.NewIdent(value)), ast.NewIdent("nil")}},
+		}
+	}
+	doc := &ast.CommentGroup{}
+	if decl.Doc != nil {
+		for _, comment := range decl.Doc.List {
+			if strings.HasPrefix(comment.Text, directivePrefix) || isNoescape(comment) {
+				continue // skip genmethods directives and pragmas.
+			}
+			doc.List = append(doc.List, &ast.Comment{Text: comment.Text})
+		}
+	}
+	if gen.opts.DocNormalize {
+		doc = normalizeDoc(doc, decl.Name.Name, ctorName)
+	}
+	return &ast.FuncDecl{
+		Doc:  doc,
+		Name: ast.NewIdent(ctorName),
+		Type: &ast.FuncType{
+			Params:  params,
+			Results: results,
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}
+}
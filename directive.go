@@ -0,0 +1,111 @@
+package main
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// directivePrefix is the prefix of genmethods directives in doc comments of
+// source functions.
+const directivePrefix = "//genmethods:"
+
+// noescapeWarning is the comment emitted on generated methods of functions
+// with a //go:noescape pragma, which cannot be propagated to methods; the
+// pragma only applies to function declarations without body (e.g. implemented
+// in assembly).
+const noescapeWarning = directivePrefix + "warning: original function has //go:noescape, which cannot be propagated"
+
+// isNoescape reports whether the given comment is a //go:noescape pragma.
+func isNoescape(comment *ast.Comment) bool {
+	return strings.TrimSpace(comment.Text) == "//go:noescape"
+}
+
+// recvDirective returns the receiver type name of the `//genmethods:recv
+// TypeName` directive in the doc comment of the given function, and reports
+// whether the directive is present.
+func recvDirective(decl *ast.FuncDecl) (string, bool) {
+	return directiveArg(decl, "recv")
+}
+
+// nameDirective returns the method name of the `//genmethods:name MethodName`
+// or `//genmethods:rename MethodName` directive in the doc comment of the
+// given function, the name of the directive, and reports whether the
+// directive is present. The directives are synonyms.
+func nameDirective(decl *ast.FuncDecl) (string, string, bool) {
+	for _, name := range []string{"name", "rename"} {
+		if methodName, ok := directiveArg(decl, name); ok {
+			return methodName, name, true
+		}
+	}
+	return "", "", false
+}
+
+// directiveArg returns the argument of the `//genmethods:name arg` directive
+// with the given name in the doc comment of the given function, and reports
+// whether the directive is present with a non-empty argument.
+func directiveArg(decl *ast.FuncDecl, name string) (string, bool) {
+	if decl.Doc == nil {
+		return "", false
+	}
+	for _, comment := range decl.Doc.List {
+		arg, ok := strings.CutPrefix(comment.Text, directivePrefix+name)
+		if !ok || len(arg) == 0 || (arg[0] != ' ' && arg[0] != '\t') {
+			continue // e.g. "//genmethods:names" of directive "name".
+		}
+		if arg = strings.TrimSpace(arg); len(arg) > 0 {
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// skipDirective reports whether the doc comment of the given function contains
+// a `//genmethods:skip` directive.
+func skipDirective(decl *ast.FuncDecl) bool {
+	if decl.Doc == nil {
+		return false
+	}
+	for _, comment := range decl.Doc.List {
+		if strings.TrimSpace(comment.Text) == directivePrefix+"skip" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRecvDirective converts the given function to a method on the pointer
+// type of the named receiver type of the package (e.g. *Window for "Window"),
+// as specified by a `//genmethods:recv TypeName` directive; overriding the
+// first parameter heuristic.
+//
+// The receiver parameter is the first parameter accepting the receiver (e.g.
+// of type *Window, Window or an interface implemented by *Window).
+func (gen *Gen) parseRecvDirective(decl *ast.FuncDecl, typeName string) error {
+	name := strings.TrimPrefix(typeName, "*")
+	obj, ok := gen.pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return errors.Errorf("unable to locate receiver type %q of directive on function %q in package %q", typeName, decl.Name, gen.pkg.PkgPath)
+	}
+	recvType := types.NewPointer(obj.Type())
+	if decl.Type.Params != nil {
+		for _, field := range decl.Type.Params.List {
+			if len(field.Names) == 0 {
+				gen.skipFunc(decl, "function with receiver directive has unnamed parameters")
+				return nil
+			}
+		}
+	}
+	for i, param := range flatParams(decl.Type.Params) {
+		paramType := gen.pkg.TypesInfo.TypeOf(param.field.Type)
+		if types.AssignableTo(recvType, paramType) || types.Identical(recvType.Elem(), paramType) {
+			if err := gen.convertFunc(decl, i, recvType); err != nil {
+				return errors.WithStack(err)
+			}
+			return nil
+		}
+	}
+	return errors.Errorf("no parameter of function %q accepts receiver type %v of directive", decl.Name, recvType)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+)
+
+// defaultConfigName is the name of config files located automatically if no
+// config file is specified (-config).
+const defaultConfigName = "genmethods.toml"
+
+// findConfig returns the path of the config file located automatically, or an
+// empty string if not found. The config file is searched for in the current
+// directory, its parent directories up to the module root (the directory
+// containing go.mod), and $XDG_CONFIG_HOME/genmethods; in that order. Outside
+// of modules, only the current directory is searched before
+// $XDG_CONFIG_HOME/genmethods.
+func findConfig() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	dirs := []string{wd}
+	if root, ok := moduleRoot(wd); ok {
+		for dir := wd; dir != root; {
+			dir = filepath.Dir(dir)
+			dirs = append(dirs, dir)
+		}
+	}
+	// os.UserConfigDir honours $XDG_CONFIG_HOME; falling back to platform
+	// defaults (e.g. ~/.config on Linux).
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "genmethods"))
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, defaultConfigName)
+		switch _, err := os.Stat(path); {
+		case err == nil:
+			return path, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return "", errors.WithStack(err)
+		}
+		clog.Debugf("config file %q not found", path)
+	}
+	return "", nil
+}
+
+// moduleRoot returns the module root of the given directory; i.e. the closest
+// directory (the given directory or one of its parents) containing go.mod.
+func moduleRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"go/ast"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// normalizeDoc returns a copy of the given doc comment of a generated method,
+// normalized to godoc conventions (-doc-normalize); i.e. the first sentence
+// starts with the method name and the first paragraph ends with a period.
+//
+// A leading function name (matched case-insensitively) is replaced by the
+// method name, and otherwise the method name is prepended, lowercasing a
+// leading capitalized word (e.g. "Sets the title" is normalized to "SetTitle
+// sets the title."). Doc comments starting with a block comment or an indented
+// line are left as is.
+func normalizeDoc(doc *ast.CommentGroup, funcName, methodName string) *ast.CommentGroup {
+	if len(doc.List) == 0 {
+		return doc
+	}
+	first, ok := strings.CutPrefix(doc.List[0].Text, "// ")
+	if !ok || len(strings.TrimSpace(first)) == 0 || strings.HasPrefix(first, " ") || strings.HasPrefix(first, "\t") {
+		return doc
+	}
+	newDoc := &ast.CommentGroup{}
+	for _, comment := range doc.List {
+		newDoc.List = append(newDoc.List, &ast.Comment{Text: comment.Text})
+	}
+	word, rest, _ := strings.Cut(first, " ")
+	switch {
+	case strings.EqualFold(word, funcName), word == methodName:
+		first = methodName
+		if len(rest) > 0 {
+			first += " " + rest
+		}
+	default:
+		first = methodName + " " + lowerInitial(first)
+	}
+	newDoc.List[0].Text = "// " + first
+	// end first paragraph with a period.
+	last := newDoc.List[0]
+	for _, comment := range newDoc.List[1:] {
+		text, ok := strings.CutPrefix(comment.Text, "//")
+		if !ok || len(strings.TrimSpace(text)) == 0 || strings.HasPrefix(text, "  ") || strings.HasPrefix(text, " \t") {
+			break // end of paragraph.
+		}
+		last = comment
+	}
+	if !strings.HasSuffix(strings.TrimRight(last.Text, " "), ".") && !strings.HasSuffix(last.Text, ":") {
+		last.Text = strings.TrimRight(last.Text, " ") + "."
+	}
+	return newDoc
+}
+
+// lowerInitial returns s with its first letter lowercased if s starts with a
+// capitalized word (e.g. "Sets" but not "SDL" or "GPU").
+func lowerInitial(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if !unicode.IsUpper(r) {
+		return s
+	}
+	if next, _ := utf8.DecodeRuneInString(s[size:]); !unicode.IsLower(next) {
+		return s // acronym or single letter.
+	}
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// mergeDoc returns a copy of the given doc comment of a generated method, with
+// the additional documentation of the given function in the config file (docs
+// section) appended after a blank comment line (-merge-doc). Empty lines of the
+// additional documentation are preserved as blank comment lines, and lines
+// indented by tabs as code blocks.
+func (gen *Gen) mergeDoc(doc *ast.CommentGroup, funcName string) *ast.CommentGroup {
+	config := gen.opts.Config
+	if config == nil {
+		return doc
+	}
+	text := strings.TrimRight(config.Docs[funcName], " \t\n")
+	if len(strings.TrimSpace(text)) == 0 {
+		return doc
+	}
+	newDoc := &ast.CommentGroup{}
+	for _, comment := range doc.List {
+		newDoc.List = append(newDoc.List, &ast.Comment{Text: comment.Text})
+	}
+	if len(newDoc.List) > 0 {
+		newDoc.List = append(newDoc.List, &ast.Comment{Text: "//"})
+	}
+	for _, line := range strings.Split(text, "\n") {
+		switch line = strings.TrimRight(line, " \t"); {
+		case len(line) == 0:
+			newDoc.List = append(newDoc.List, &ast.Comment{Text: "//"})
+		case strings.HasPrefix(line, "\t"):
+			// code block.
+			newDoc.List = append(newDoc.List, &ast.Comment{Text: "//" + line})
+		default:
+			newDoc.List = append(newDoc.List, &ast.Comment{Text: "// " + line})
+		}
+	}
+	return newDoc
+}
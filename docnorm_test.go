@@ -0,0 +1,142 @@
+package main
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDoc(t *testing.T) {
+	golden := []struct {
+		name       string
+		doc        string
+		funcName   string
+		methodName string
+		// expected normalized doc comment.
+		want string
+	}{
+		{
+			name:       "missing period",
+			doc:        "// GetWindowSize returns the size of the window",
+			funcName:   "GetWindowSize",
+			methodName: "GetSize",
+			want:       "// GetSize returns the size of the window.",
+		},
+		{
+			name:       "lowercase start",
+			doc:        "// sets the title of the window.",
+			funcName:   "SetWindowTitle",
+			methodName: "SetTitle",
+			want:       "// SetTitle sets the title of the window.",
+		},
+		{
+			name:       "capitalized start",
+			doc:        "// Sets the title of the window",
+			funcName:   "SetWindowTitle",
+			methodName: "SetTitle",
+			want:       "// SetTitle sets the title of the window.",
+		},
+		{
+			name:       "lowercase function name",
+			doc:        "// setwindowtitle sets the title of the window",
+			funcName:   "SetWindowTitle",
+			methodName: "SetTitle",
+			want:       "// SetTitle sets the title of the window.",
+		},
+		{
+			name:       "acronym start",
+			doc:        "// SDL window title setter",
+			funcName:   "SetWindowTitle",
+			methodName: "SetTitle",
+			want:       "// SetTitle SDL window title setter.",
+		},
+		{
+			name:       "multi-line paragraph",
+			doc:        "// SetWindowTitle sets the title\n// of the window\n//\n// The title is UTF-8 encoded",
+			funcName:   "SetWindowTitle",
+			methodName: "SetTitle",
+			want:       "// SetTitle sets the title\n// of the window.\n//\n// The title is UTF-8 encoded",
+		},
+		{
+			name:       "trailing colon",
+			doc:        "// SetWindowTitle sets the title of the window:\n//\n//\twindow.SetTitle(\"foo\")",
+			funcName:   "SetWindowTitle",
+			methodName: "SetTitle",
+			want:       "// SetTitle sets the title of the window:\n//\n//\twindow.SetTitle(\"foo\")",
+		},
+		{
+			name:       "indented line",
+			doc:        "//  sets the title of the window",
+			funcName:   "SetWindowTitle",
+			methodName: "SetTitle",
+			want:       "//  sets the title of the window",
+		},
+		{
+			name:       "block comment",
+			doc:        "/* sets the title of the window */",
+			funcName:   "SetWindowTitle",
+			methodName: "SetTitle",
+			want:       "/* sets the title of the window */",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			doc := &ast.CommentGroup{}
+			for _, line := range strings.Split(g.doc, "\n") {
+				doc.List = append(doc.List, &ast.Comment{Text: line})
+			}
+			var lines []string
+			for _, comment := range normalizeDoc(doc, g.funcName, g.methodName).List {
+				lines = append(lines, comment.Text)
+			}
+			if got := strings.Join(lines, "\n"); got != g.want {
+				t.Errorf("doc comment mismatch; expected %q, got %q", g.want, got)
+			}
+			// the original doc comment is left unchanged.
+			if got := doc.List[0].Text; got != strings.Split(g.doc, "\n")[0] {
+				t.Errorf("original doc comment modified; got %q", got)
+			}
+		})
+	}
+}
+
+func TestDocNormalize(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected doc comments, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Window).SetWindowMinimumSize": "// sets the minimum size of the window",
+				"(*Window).Hide":                 "// Hides the window",
+				"(*Window).GetSize":              "// GetWindowSize returns the size of the window.",
+			},
+		},
+		{
+			name: "doc normalize",
+			opts: &GenOptions{DocNormalize: true},
+			want: map[string]string{
+				"(*Window).SetWindowMinimumSize": "// SetWindowMinimumSize sets the minimum size of the window.",
+				"(*Window).Hide":                 "// Hide hides the window.",
+				"(*Window).MaximizeWindow":       "// MaximizeWindow maximizes the window\n// to fill the screen.\n//\n// The window must be resizable",
+				"(*Window).GetSize":              "// GetSize returns the size of the window.",
+				// already normalized.
+				"(*Window).GetWindowOpacity": "// GetWindowOpacity returns the opacity of the window.",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", g.opts)
+			for key, want := range g.want {
+				if doc := got.doc(t, key); doc != want {
+					t.Errorf("doc comment of method %s mismatch; expected %q, got %q", key, want, doc)
+				}
+			}
+		})
+	}
+}
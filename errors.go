@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ErrPackageLoad is returned when a package could not be loaded.
+type ErrPackageLoad struct {
+	// package path or pattern.
+	PkgPath string
+	// underlying error.
+	Err error
+	// actionable hint on how to resolve the error (optional).
+	Hint string
+}
+
+func (e *ErrPackageLoad) Error() string {
+	if len(e.Hint) > 0 {
+		return fmt.Sprintf("unable to load package %q: %v\n\thint: %s", e.PkgPath, e.Err, e.Hint)
+	}
+	return fmt.Sprintf("unable to load package %q: %v", e.PkgPath, e.Err)
+}
+
+func (e *ErrPackageLoad) Unwrap() error {
+	return e.Err
+}
+
+// ErrTypecheckFailure is returned when a loaded package contains parse or type
+// errors.
+type ErrTypecheckFailure struct {
+	// package path.
+	PkgPath string
+	// errors of package.
+	Errors []packages.Error
+}
+
+func (e *ErrTypecheckFailure) Error() string {
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("type-checking package %q failed:\n%s", e.PkgPath, strings.Join(msgs, "\n"))
+}
+
+// ErrDuplicateMethod is returned when two functions are converted to methods
+// with the same name on the same receiver type.
+type ErrDuplicateMethod struct {
+	// receiver type.
+	RecvType types.Type
+	// method name.
+	MethodName string
+	// name of source function.
+	FuncName string
+	// name of source function of previously generated method.
+	PrevFuncName string
+}
+
+func (e *ErrDuplicateMethod) Error() string {
+	return fmt.Sprintf("duplicate method %q on receiver type %v; generated from both %q and %q", e.MethodName, e.RecvType, e.PrevFuncName, e.FuncName)
+}
+
+// ErrInvalidReceiverType is returned when methods cannot be declared on the
+// receiver type of a function, defined in the package of the function (e.g. a
+// defined pointer or interface type).
+type ErrInvalidReceiverType struct {
+	// name of source function.
+	FuncName string
+	// receiver type.
+	RecvType types.Type
+	// reason why receiver type is invalid.
+	Reason string
+}
+
+func (e *ErrInvalidReceiverType) Error() string {
+	return fmt.Sprintf("invalid receiver type %v of function %q; %s", e.RecvType, e.FuncName, e.Reason)
+}
+
+// ErrSkippedFunc is returned in -error-on-skip mode when a function with a
+// valid receiver type is skipped.
+type ErrSkippedFunc struct {
+	// name of source function.
+	FuncName string
+	// reason why the function was skipped.
+	Reason string
+}
+
+func (e *ErrSkippedFunc) Error() string {
+	return fmt.Sprintf("skipped function %q with valid receiver type; %s", e.FuncName, e.Reason)
+}
+
+// ErrSchemaValidation is returned when a config file does not conform to the
+// JSON Schema.
+type ErrSchemaValidation struct {
+	// path of the config file.
+	ConfigPath string
+	// validation errors.
+	Errors []*SchemaError
+}
+
+func (e *ErrSchemaValidation) Error() string {
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("invalid config file %q:\n\t%s", e.ConfigPath, strings.Join(msgs, "\n\t"))
+}
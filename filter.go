@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+)
+
+// runFilter runs the given filter command on the generated Go source of the
+// specified output file, and returns the transformed source.
+//
+// Filter contract:
+//
+//   - the command line is split on white space (no shell expansion).
+//   - the generated Go source is written to standard input of the filter.
+//   - the transformed Go source is read from standard output of the filter.
+//   - a non-zero exit status aborts generation; standard error of the filter
+//     is included in the error message.
+//   - the environment variables GENMETHODS_PKG and GENMETHODS_OUTPUT hold the
+//     package path and output path (empty when writing to standard output)
+//     respectively.
+func (gen *Gen) runFilter(command string, src []byte, output string) ([]byte, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.Errorf("invalid filter command %q", command)
+	}
+	clog.Debugf("running filter %q on %q", command, output)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"GENMETHODS_PKG="+gen.pkg.PkgPath,
+		"GENMETHODS_OUTPUT="+output,
+	)
+	cmd.Stdin = bytes.NewReader(src)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 0 {
+			return nil, errors.Errorf("filter %q failed: %v\n%s", command, err, msg)
+		}
+		return nil, errors.Errorf("filter %q failed: %v", command, err)
+	}
+	data := stdout.Bytes()
+	if _, err := parser.ParseFile(token.NewFileSet(), "", data, parser.PackageClauseOnly); err != nil {
+		return nil, errors.Wrapf(err, "invalid Go source output of filter %q", command)
+	}
+	if gen.opts.NoFormat {
+		return data, nil
+	}
+	formatted, err := format.Source(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Go source output of filter %q", command)
+	}
+	return formatted, nil
+}
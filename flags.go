@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// stringsFlag is a repeatable string flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// fileModeFlag is a file permission bits flag in octal (e.g. 0o600, 0600 or
+// 600).
+type fileModeFlag os.FileMode
+
+func (f *fileModeFlag) String() string {
+	if *f == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%O", uint32(*f))
+}
+
+func (f *fileModeFlag) Set(s string) error {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O")
+	perm, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil {
+		return errors.Errorf("invalid octal file permission bits %q", s)
+	}
+	*f = fileModeFlag(perm)
+	return nil
+}
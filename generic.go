@@ -0,0 +1,51 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/version"
+)
+
+// minGenericMethodsVersion is the minimum Go language version supporting
+// methods with type parameters.
+const minGenericMethodsVersion = "go1.27"
+
+// supportsGenericMethods reports whether the Go language version of the file
+// containing the given position supports methods with type parameters. Files
+// of unknown version are assumed to use the latest language version.
+func (gen *Gen) supportsGenericMethods(pos token.Pos) (string, bool) {
+	for _, file := range gen.pkg.Syntax {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			goVersion := gen.pkg.TypesInfo.FileVersions[file]
+			if len(goVersion) == 0 {
+				return "", true
+			}
+			return goVersion, version.Compare(goVersion, minGenericMethodsVersion) >= 0
+		}
+	}
+	return "", true
+}
+
+// instantiate returns the given function expression explicitly instantiated
+// with the type parameters of the given type parameter list (e.g.
+// `Render[T]`), or fun if the list is empty. Type arguments are passed
+// explicitly, as type parameters used only in results may not be inferred.
+func instantiate(fun ast.Expr, typeParams *ast.FieldList) ast.Expr {
+	if typeParams == nil {
+		return fun
+	}
+	var typeArgs []ast.Expr
+	for _, field := range typeParams.List {
+		for _, name := range field.Names {
+			typeArgs = append(typeArgs, ast.NewIdent(name.Name))
+		}
+	}
+	switch len(typeArgs) {
+	case 0:
+		return fun
+	case 1:
+		return &ast.IndexExpr{X: fun, Index: typeArgs[0]}
+	default:
+		return &ast.IndexListExpr{X: fun, Indices: typeArgs}
+	}
+}
@@ -3,13 +3,16 @@ module github.com/mewspring/genmethods
 go 1.23.5
 
 require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/fsnotify/fsnotify v1.8.0
 	github.com/mewpkg/clog v0.0.0-20241218233822-8cb78664cbfc
 	github.com/pkg/errors v0.9.1
+	golang.org/x/sync v0.10.0
 	golang.org/x/tools v0.29.0
 )
 
 require (
 	github.com/mewpkg/term v0.0.0-20241026122259-37a80af23985 // indirect
 	golang.org/x/mod v0.22.0 // indirect
-	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 )
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// groupFileSuffix is the suffix of output file names in group-by-file mode,
+// replacing the .go extension of the source file name (e.g.
+// "window_methods_gen.go" for "window.go").
+const groupFileSuffix = "_methods_gen.go"
+
+// isMultiFile reports whether the given generation options specify an output
+// mode of one output file per receiver type (-split-by-type) or per source file
+// (-group-by-file), where the output path is interpreted as the output
+// directory.
+func isMultiFile(opts *GenOptions) bool {
+	return opts.SplitByType || opts.GroupByFile
+}
+
+// partitionByFile partitions the generated methods by the source file of their
+// source function, sorted by output path. Each output file is restricted by the
+// build constraints of its source file, as specified by //go:build lines and
+// GOOS and GOARCH file name suffixes (e.g. "window_linux.go").
+func (gen *Gen) partitionByFile(outputDir string) ([]*partition, error) {
+	files := make(map[string]*ast.File)
+	for _, file := range gen.pkg.Syntax {
+		files[gen.pkg.Fset.Position(file.FileStart).Filename] = file
+	}
+	partMap := make(map[string]*partition)
+	for _, method := range gen.methods {
+		filename := gen.pkg.Fset.Position(method.Func.Pos()).Filename
+		if len(filename) == 0 {
+			return nil, errors.Errorf("unable to locate source file of function %q", method.Func.Name())
+		}
+		name := strings.TrimSuffix(filepath.Base(filename), ".go")
+		output := filepath.Join(outputDir, name+groupFileSuffix)
+		part, ok := partMap[output]
+		if !ok {
+			buildExpr, err := fileConstraint(filename, files[filename])
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			part = &partition{output: output, buildExpr: buildExpr}
+			partMap[output] = part
+		}
+		part.methods = append(part.methods, method)
+	}
+	var parts []*partition
+	for _, part := range partMap {
+		parts = append(parts, part)
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].output < parts[j].output
+	})
+	return parts, nil
+}
+
+// fileConstraint returns the build constraint of the given source file, as
+// specified by its //go:build line and GOOS and GOARCH file name suffixes; or
+// nil if unconstrained.
+func fileConstraint(filename string, file *ast.File) (constraint.Expr, error) {
+	var exprs []constraint.Expr
+	if file != nil {
+		for _, group := range file.Comments {
+			if group.Pos() >= file.Package {
+				break
+			}
+			for _, comment := range group.List {
+				if !constraint.IsGoBuild(comment.Text) {
+					continue
+				}
+				expr, err := constraint.Parse(comment.Text)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid build constraint of source file %q", filename)
+				}
+				exprs = append(exprs, expr)
+			}
+		}
+	}
+	for _, tag := range fileNameTags(filename) {
+		exprs = append(exprs, &constraint.TagExpr{Tag: tag})
+	}
+	return andExprs(exprs...), nil
+}
+
+// fileNameTags returns the GOOS and GOARCH build tags implied by the file name
+// suffixes of the given source file (e.g. "linux" and "amd64" for
+// "window_linux_amd64.go"), following the rules of go/build.
+func fileNameTags(filename string) []string {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	name = strings.TrimSuffix(name, "_test")
+	// the first element is never a build tag (e.g. "linux.go").
+	elems := strings.Split(name, "_")[1:]
+	n := len(elems)
+	switch {
+	case n >= 2 && knownOS[elems[n-2]] && knownArch[elems[n-1]]:
+		return []string{elems[n-2], elems[n-1]}
+	case n >= 1 && (knownOS[elems[n-1]] || knownArch[elems[n-1]]):
+		return []string{elems[n-1]}
+	}
+	return nil
+}
+
+// outputConstraint returns the build constraint of a generated file restricted
+// by the given build constraint (or nil), in addition to the build tag
+// combination of the loaded package (-pkg-tag); or nil if unconstrained.
+func (gen *Gen) outputConstraint(buildExpr constraint.Expr) constraint.Expr {
+	var exprs []constraint.Expr
+	if len(gen.opts.PkgTags) > 0 {
+		for _, tag := range strings.Split(gen.opts.PkgTags, ",") {
+			exprs = append(exprs, &constraint.TagExpr{Tag: tag})
+		}
+	}
+	if buildExpr != nil {
+		exprs = append(exprs, buildExpr)
+	}
+	return andExprs(exprs...)
+}
+
+// andExprs returns the conjunction of the given build constraint expressions;
+// or nil if none.
+func andExprs(exprs ...constraint.Expr) constraint.Expr {
+	var expr constraint.Expr
+	for _, x := range exprs {
+		if expr == nil {
+			expr = x
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: x}
+		}
+	}
+	return expr
+}
+
+// knownOS is the set of GOOS values recognized in file name suffixes by
+// go/build.
+var knownOS = map[string]bool{
+	"aix":       true,
+	"android":   true,
+	"darwin":    true,
+	"dragonfly": true,
+	"freebsd":   true,
+	"hurd":      true,
+	"illumos":   true,
+	"ios":       true,
+	"js":        true,
+	"linux":     true,
+	"nacl":      true,
+	"netbsd":    true,
+	"openbsd":   true,
+	"plan9":     true,
+	"solaris":   true,
+	"wasip1":    true,
+	"windows":   true,
+	"zos":       true,
+}
+
+// knownArch is the set of GOARCH values recognized in file name suffixes by
+// go/build.
+var knownArch = map[string]bool{
+	"386":         true,
+	"amd64":       true,
+	"amd64p32":    true,
+	"arm":         true,
+	"armbe":       true,
+	"arm64":       true,
+	"arm64be":     true,
+	"loong64":     true,
+	"mips":        true,
+	"mipsle":      true,
+	"mips64":      true,
+	"mips64le":    true,
+	"mips64p32":   true,
+	"mips64p32le": true,
+	"ppc":         true,
+	"ppc64":       true,
+	"ppc64le":     true,
+	"riscv":       true,
+	"riscv64":     true,
+	"s390":        true,
+	"s390x":       true,
+	"sparc":       true,
+	"sparc64":     true,
+	"wasm":        true,
+}
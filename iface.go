@@ -0,0 +1,58 @@
+package main
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+)
+
+// interfaceRecvTypes returns the configured concrete receiver types of the
+// given interface first parameter type of the specified function.
+func (gen *Gen) interfaceRecvTypes(funcName string, paramType types.Type) ([]types.Type, error) {
+	iface, ok := paramType.Underlying().(*types.Interface)
+	if !ok {
+		return nil, nil
+	}
+	config := gen.opts.Config
+	if config == nil {
+		return nil, nil
+	}
+	var recvTypes []types.Type
+	for _, typStr := range config.Interfaces[paramType.String()] {
+		recvType, err := gen.lookupType(typStr)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !types.Implements(recvType, iface) {
+			return nil, errors.Errorf("concrete type %v does not implement interface %v", recvType, paramType)
+		}
+		clog.Warnf("interface match: function %q with parameter of interface type %v generated as method on %v", funcName, paramType, recvType)
+		recvTypes = append(recvTypes, recvType)
+	}
+	return recvTypes, nil
+}
+
+// lookupType returns the type of the analyzed package with the given fully
+// qualified type string (e.g. "*github.com/foo/sdl.Window").
+func (gen *Gen) lookupType(typStr string) (types.Type, error) {
+	name, isPtr := strings.CutPrefix(typStr, "*")
+	pos := strings.LastIndex(name, ".")
+	if pos == -1 {
+		return nil, errors.Errorf("invalid type %q; expected qualified type (e.g. %q)", typStr, "*example.com/pkg.Type")
+	}
+	pkgPath, name := name[:pos], name[pos+1:]
+	if pkgPath != gen.pkg.PkgPath {
+		return nil, errors.Errorf("type %q not defined in package %q", typStr, gen.pkg.PkgPath)
+	}
+	obj, ok := gen.pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, errors.Errorf("unable to locate type %q in package %q", name, gen.pkg.PkgPath)
+	}
+	typ := obj.Type()
+	if isPtr {
+		typ = types.NewPointer(typ)
+	}
+	return typ, nil
+}
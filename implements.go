@@ -0,0 +1,134 @@
+package main
+
+import (
+	"go/types"
+	"sort"
+	"sync/atomic"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+)
+
+// lookupInterface returns the named interface of the analyzed package (e.g.
+// "Drawable").
+func (gen *Gen) lookupInterface(name string) (*types.Interface, error) {
+	obj, ok := gen.pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, errors.Errorf("unable to locate interface %q in package %q", name, gen.pkg.PkgPath)
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, errors.Errorf("type %q of package %q is not an interface", name, gen.pkg.PkgPath)
+	}
+	return iface, nil
+}
+
+// implementInterface filters and orders the generated methods to implement
+// the named interface of the analyzed package (e.g. "Drawable"). Generated
+// methods not part of the interface are dropped, and interface methods not
+// covered by generated or existing methods are reported per receiver type.
+func (gen *Gen) implementInterface(name string) error {
+	iface, err := gen.lookupInterface(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// interface methods in source order.
+	var ifaceMethods []*types.Func
+	for i := 0; i < iface.NumMethods(); i++ {
+		ifaceMethods = append(ifaceMethods, iface.Method(i))
+	}
+	sort.SliceStable(ifaceMethods, func(i, j int) bool {
+		return ifaceMethods[i].Pos() < ifaceMethods[j].Pos()
+	})
+	order := make(map[string]int)
+	for i, m := range ifaceMethods {
+		order[m.Name()] = i
+	}
+	// target receiver types, i.e. receiver types of generated methods part of
+	// the interface, in order of first generated method.
+	var recvTypes []types.Type
+	recvOrder := make(map[string]int)
+	for _, method := range gen.methods {
+		if _, ok := order[method.Decl.Name.String()]; !ok {
+			continue
+		}
+		if _, ok := recvOrder[method.RecvType.String()]; !ok {
+			recvOrder[method.RecvType.String()] = len(recvTypes)
+			recvTypes = append(recvTypes, method.RecvType)
+		}
+	}
+	// map from receiver type to names of generated methods.
+	covered := make(map[string]map[string]bool)
+	// map from non-target receiver type to number of dropped methods.
+	dropped := make(map[string]int)
+	var methods []*Method
+	for _, method := range gen.methods {
+		recvKey := method.RecvType.String()
+		methodName := method.Decl.Name.String()
+		if _, ok := recvOrder[recvKey]; !ok {
+			if dropped[recvKey] == 0 {
+				clog.Warnf("no generated method on %v is part of interface %q; dropping methods", method.RecvType, name)
+			}
+			dropped[recvKey]++
+			atomic.AddInt64(&gen.Stats.MethodsGenerated, -1)
+			continue
+		}
+		i, ok := order[methodName]
+		if !ok {
+			clog.Warnf("generated method %q on %v is not part of interface %q; dropping method", methodName, method.RecvType, name)
+			atomic.AddInt64(&gen.Stats.MethodsGenerated, -1)
+			continue
+		}
+		if sig, want := methodSig(method), ifaceMethods[i].Type(); !types.Identical(sig, want) {
+			clog.Warnf("generated method %q on %v has signature %v; interface %q requires %v; dropping method", methodName, method.RecvType, sig, name, want)
+			atomic.AddInt64(&gen.Stats.MethodsGenerated, -1)
+			continue
+		}
+		if covered[recvKey] == nil {
+			covered[recvKey] = make(map[string]bool)
+		}
+		covered[recvKey][methodName] = true
+		methods = append(methods, method)
+	}
+	sort.SliceStable(methods, func(i, j int) bool {
+		ri, rj := recvOrder[methods[i].RecvType.String()], recvOrder[methods[j].RecvType.String()]
+		if ri != rj {
+			return ri < rj
+		}
+		return order[methods[i].Decl.Name.String()] < order[methods[j].Decl.Name.String()]
+	})
+	gen.methods = methods
+	if len(recvTypes) == 0 {
+		clog.Warnf("no generated method is part of interface %q", name)
+	}
+	// report coverage gaps.
+	for _, recvType := range recvTypes {
+		mset := types.NewMethodSet(recvType)
+		var missing int
+		for _, m := range ifaceMethods {
+			if covered[recvType.String()][m.Name()] {
+				continue
+			}
+			if sel := mset.Lookup(m.Pkg(), m.Name()); sel != nil {
+				continue // implemented by existing method.
+			}
+			clog.Warnf("interface method %q of %q not covered on %v; no matching source function", m.Name(), name, recvType)
+			missing++
+		}
+		clog.Infof("interface %q: %d of %d methods covered on %v", name, len(ifaceMethods)-missing, len(ifaceMethods), recvType)
+	}
+	return nil
+}
+
+// methodSig returns the signature of the given generated method, as derived
+// from the signature of its source function.
+func methodSig(method *Method) *types.Signature {
+	sig := method.Func.Type().Underlying().(*types.Signature)
+	var params []*types.Var
+	for i := 0; i < sig.Params().Len(); i++ {
+		if i != method.RecvIndex {
+			params = append(params, sig.Params().At(i))
+		}
+	}
+	return types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), sig.Results(), sig.Variadic())
+}
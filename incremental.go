@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// changedFiles returns the set of absolute paths of files changed since the
+// given git commit (e.g. "HEAD~1"), as reported by `git diff --name-only`;
+// including uncommitted changes and untracked files of the working tree of the
+// git repository containing the current directory.
+func changedFiles(commit string) (map[string]bool, error) {
+	root, err := runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	root = strings.TrimSpace(root)
+	diff, err := runGit("diff", "--name-only", commit, "--")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	untracked, err := runGit("ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	changed := make(map[string]bool)
+	for _, name := range strings.Fields(diff + "\n" + untracked) {
+		changed[filepath.Join(root, filepath.FromSlash(name))] = true
+	}
+	return changed, nil
+}
+
+// runGit runs git with the given arguments in the current directory, and
+// returns its standard output.
+func runGit(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 0 {
+			return "", errors.Errorf("git %s failed: %v\n%s", strings.Join(args, " "), err, msg)
+		}
+		return "", errors.Errorf("git %s failed: %v", strings.Join(args, " "), err)
+	}
+	return stdout.String(), nil
+}
+
+// pkgChanged reports whether any Go source file of the given package is in the
+// set of changed files (-since-commit). Packages are regenerated as a whole,
+// since the output file of a package holds the methods of all its source files.
+func pkgChanged(pkg *packages.Package, changed map[string]bool) bool {
+	for _, goFile := range pkg.GoFiles {
+		path, err := filepath.Abs(goFile)
+		if err != nil {
+			path = goFile
+		}
+		// resolve symlinks (e.g. /tmp on macOS), as git reports resolved paths.
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		if changed[path] {
+			return true
+		}
+	}
+	clog.Infof("skipping package %q without source files changed since commit", pkg.PkgPath)
+	return false
+}
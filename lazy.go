@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// isLazyFunc reports whether the given function is listed to generate a lazy
+// method (-lazy-once), caching the result of the first call.
+func (gen *Gen) isLazyFunc(funcName string) bool {
+	return slices.Contains(gen.opts.LazyOnce, funcName)
+}
+
+// checkLazy checks that the given function fits a lazy method; i.e. takes only
+// the receiver, returns at least one result and has no type parameters.
+func checkLazy(funcDecl *ast.FuncDecl) error {
+	if n := len(flatParams(funcDecl.Type.Params)); n != 1 {
+		return errors.Errorf("expected only receiver parameter; got %d parameters", n)
+	}
+	if funcDecl.Type.Results == nil || len(funcDecl.Type.Results.List) == 0 {
+		return errors.New("expected at least one result")
+	}
+	if funcDecl.Type.TypeParams != nil {
+		return errors.New("generic functions not supported")
+	}
+	return nil
+}
+
+// lazyStmts returns the statements of a lazy method of the given forwarded
+// call, and the declaration of the package-level sync.Once and result
+// variables caching the results of the first call. The cache is shared by all
+// receivers of the method.
+//
+// Example:
+//
+//	var (
+//		lazyWindowGetDisplayOnce   sync.Once
+//		lazyWindowGetDisplayResult DisplayID
+//	)
+//
+//	func (window *Window) GetDisplay() DisplayID {
+//		lazyWindowGetDisplayOnce.Do(func() {
+//			lazyWindowGetDisplayResult = GetDisplayForWindow(window)
+//		})
+//		return lazyWindowGetDisplayResult
+//	}
+func (gen *Gen) lazyStmts(callExpr *ast.CallExpr, recvType types.Type, methodName string, results *ast.FieldList) ([]ast.Stmt, *ast.GenDecl, error) {
+	prefix := "lazy" + typeName(recvType) + methodName
+	methodExpr := typeName(recvType) + "." + methodName
+	if isPointer(recvType) {
+		methodExpr = "(*" + typeName(recvType) + ")." + methodName
+	}
+	onceName := prefix + "Once"
+	fields := flatResults(results)
+	var resultNames []string
+	for i := range fields {
+		resultName := prefix + "Result"
+		if len(fields) > 1 {
+			resultName += fmt.Sprint(i)
+		}
+		resultNames = append(resultNames, resultName)
+	}
+	for _, name := range append([]string{onceName}, resultNames...) {
+		if gen.isDeclared(name) {
+			return nil, nil, errors.Errorf("cache variable %q of lazy method %q already declared in package %q", name, methodName, gen.pkg.PkgPath)
+		}
+	}
+	gen.imports["sync"] = true
+	decl := &ast.GenDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// cache of the first call of " + methodExpr + "."},
+			},
+		},
+		Tok:    token.VAR,
+		Lparen: 1, // parenthesized.
+		Specs: []ast.Spec{
+			&ast.ValueSpec{
+				Names: []*ast.Ident{ast.NewIdent(onceName)},
+				Type:  &ast.SelectorExpr{X: ast.NewIdent("sync"), Sel: ast.NewIdent("Once")},
+			},
+		},
+	}
+	var lhs, rets []ast.Expr
+	for i, field := range fields {
+		decl.Specs = append(decl.Specs, &ast.ValueSpec{
+			Names: []*ast.Ident{ast.NewIdent(resultNames[i])},
+			Type:  field.Type,
+		})
+		lhs = append(lhs, ast.NewIdent(resultNames[i]))
+		rets = append(rets, ast.NewIdent(resultNames[i]))
+	}
+	doStmt := &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: ast.NewIdent(onceName), Sel: ast.NewIdent("Do")},
+			Args: []ast.Expr{
+				&ast.FuncLit{
+					Type: &ast.FuncType{Params: &ast.FieldList{}},
+					Body: &ast.BlockStmt{
+						List: []ast.Stmt{
+							&ast.AssignStmt{
+								Lhs: lhs,
+								Tok: token.ASSIGN,
+								Rhs: []ast.Expr{callExpr},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	return []ast.Stmt{doStmt, &ast.ReturnStmt{Results: rets}}, decl, nil
+}
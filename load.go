@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// checkPkgs reports an error with an actionable hint if the loaded packages of
+// the given package path (or patterns) contain load errors, or if no source
+// files were matched.
+func checkPkgs(pkgPath string, pkgs []*packages.Package) error {
+	if len(pkgs) == 0 {
+		return &ErrPackageLoad{
+			PkgPath: pkgPath,
+			Err:     errors.New("no packages matched"),
+			Hint:    "the package path and build tag selection matched no packages; check the package path (-pkg) and build tags (GOFLAGS=-tags=...)",
+		}
+	}
+	nsyntax := 0
+	for _, pkg := range pkgs {
+		genFiles := genmethodsFiles(pkg)
+		var typeErrs []packages.Error
+		for _, err := range pkg.Errors {
+			// ignore errors of previously generated files, as they are replaced
+			// on regeneration (e.g. stale methods of removed functions).
+			if isGenFileError(err, genFiles) {
+				clog.Warnf("ignoring error in previously generated file: %v", err)
+				continue
+			}
+			switch err.Kind {
+			case packages.TypeError:
+				typeErrs = append(typeErrs, err)
+			default:
+				return &ErrPackageLoad{PkgPath: pkg.PkgPath, Err: err, Hint: loadHint(err.Msg)}
+			}
+		}
+		if len(typeErrs) > 0 {
+			return &ErrTypecheckFailure{PkgPath: pkg.PkgPath, Errors: typeErrs}
+		}
+		nsyntax += len(pkg.Syntax)
+	}
+	if nsyntax == 0 {
+		return &ErrPackageLoad{
+			PkgPath: pkgPath,
+			Err:     errors.New("no Go source files matched"),
+			Hint:    "the package path and build tag selection matched no source files; check the package path (-pkg) and build tags (GOFLAGS=-tags=...)",
+		}
+	}
+	return nil
+}
+
+// genmethodsFiles returns the set of file names of the given package which
+// were generated by genmethods.
+func genmethodsFiles(pkg *packages.Package) map[string]bool {
+	genFiles := make(map[string]bool)
+	for _, file := range pkg.Syntax {
+		if len(file.Comments) == 0 || !ast.IsGenerated(file) {
+			continue
+		}
+		if strings.Contains(file.Comments[0].Text(), `"genmethods"`) {
+			filename := pkg.Fset.Position(file.FileStart).Filename
+			genFiles[filename] = true
+		}
+	}
+	return genFiles
+}
+
+// isGenFileError reports whether the given package error is located in one of
+// the given generated files. Errors reported by the go command during listing
+// are of the form:
+//
+//	# example.com/pkg
+//	pkg/methods.go:48:2: undefined: Foo
+func isGenFileError(err packages.Error, genFiles map[string]bool) bool {
+	switch err.Kind {
+	case packages.TypeError, packages.ParseError:
+		filename, _, ok := strings.Cut(err.Pos, ":")
+		return ok && genFiles[filename]
+	case packages.ListError:
+		lines := strings.Split(strings.TrimSpace(err.Msg), "\n")
+		if len(lines) < 2 || !strings.HasPrefix(lines[0], "# ") {
+			return false
+		}
+		for _, line := range lines[1:] {
+			if strings.TrimSpace(line) == "too many errors" {
+				continue
+			}
+			filename, _, ok := strings.Cut(line, ":")
+			if !ok {
+				return false
+			}
+			absFilename, err := filepath.Abs(filename)
+			if err != nil || !genFiles[absFilename] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// loadHints maps from substrings of common load error messages to actionable
+// hints.
+var loadHints = []struct {
+	substr string
+	hint   string
+}{
+	{substr: `executable file not found`, hint: "the go command was not found; ensure that a Go toolchain is installed and in PATH"},
+	{substr: `no required module provides package`, hint: "the package is not part of the main module or its dependencies; run genmethods from within the module, or add the dependency with `go get`"},
+	{substr: `cannot find module`, hint: "the module was not found; run `go mod download` or check the module path"},
+	{substr: `reading https://`, hint: "the module could not be downloaded; check the package path (-pkg), or run genmethods from within the module of the package"},
+	{substr: `no such file or directory`, hint: "the package directory was not found; check the package path (-pkg)"},
+	{substr: `directory not found`, hint: "the package directory was not found; check the package path (-pkg)"},
+	{substr: `is not in std`, hint: "the package path was not found; check the package path (-pkg)"},
+	{substr: `go.mod file not found`, hint: "no go.mod file was found; run genmethods from within a Go module"},
+	{substr: `build constraints exclude all Go files`, hint: "the build tag selection matched no source files; check build tags (GOFLAGS=-tags=...)"},
+	{substr: `no Go files`, hint: "the package directory contains no Go source files; check the package path (-pkg)"},
+	{substr: `updates to go.mod needed`, hint: "go.mod is out of date; run `go mod tidy`"},
+	{substr: `missing go.sum entry`, hint: "go.sum is out of date; run `go mod tidy`"},
+	{substr: `inconsistent vendoring`, hint: "the vendor directory is out of date; run `go mod vendor`"},
+	{substr: `cannot find package`, hint: "the package was not found; if the package is vendored, enable vendor mode (-vendor)"},
+}
+
+// loadHint returns an actionable hint for the given load error message, or the
+// empty string if no hint is known.
+func loadHint(msg string) string {
+	for _, h := range loadHints {
+		if strings.Contains(msg, h.substr) {
+			return h.hint
+		}
+	}
+	return ""
+}
+
+// packagesConfig returns the configuration of the package loader. With
+// verbose debug output (-v), internal diagnostics of the package loader are
+// logged as debug messages.
+//
+// Packages are loaded in vendor mode (-mod=vendor) if opts.Vendor is set, or
+// if the working directory contains a vendor directory; unless GOFLAGS
+// specifies the module download mode. Only files matching the build tag
+// combination opts.PkgTags (if any) are loaded. The additional environment
+// variables opts.PkgEnv (if any) are passed to the go command.
+func packagesConfig(opts *GenOptions) *packages.Config {
+	cfg := &packages.Config{
+		Mode: packages.LoadSyntax,
+	}
+	if len(opts.PkgTags) > 0 {
+		cfg.BuildFlags = append(cfg.BuildFlags, "-tags", opts.PkgTags)
+	}
+	if len(opts.PkgEnv) > 0 {
+		cfg.Env = append(os.Environ(), opts.PkgEnv...)
+	}
+	vendor := opts.Vendor
+	if !vendor && hasVendorDir() {
+		clog.Debugln("vendor directory detected; loading packages in vendor mode")
+		vendor = true
+	}
+	if vendor && !strings.Contains(os.Getenv("GOFLAGS"), "-mod=") {
+		cfg.BuildFlags = append(cfg.BuildFlags, "-mod=vendor")
+	}
+	if level, ok := clog.PathLevel("main"); !ok || level <= clog.LevelDebug {
+		cfg.Logf = func(format string, args ...any) {
+			clog.Debugf("packages: "+format, args...)
+		}
+	}
+	return cfg
+}
+
+// loadPackages loads the packages of the given patterns using the given
+// package loader configuration, bounded by the package load timeout of the
+// specified generation options (-pkg-load-timeout) if any. Load errors are
+// reported as ErrPackageLoad of the given package path, with an actionable
+// hint.
+func loadPackages(cfg *packages.Config, opts *GenOptions, pkgPath string, patterns ...string) ([]*packages.Package, error) {
+	if opts.PkgLoadTimeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.PkgLoadTimeout)
+		defer cancel()
+		cfg.Context = ctx
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		hint := loadHint(err.Error())
+		if cfg.Context != nil && errors.Is(cfg.Context.Err(), context.DeadlineExceeded) {
+			hint = fmt.Sprintf("loading packages exceeded the package load timeout of %v; increase the timeout (-pkg-load-timeout), or use -no-method-set-check to reduce work", opts.PkgLoadTimeout)
+		}
+		return nil, errors.WithStack(&ErrPackageLoad{PkgPath: pkgPath, Err: err, Hint: hint})
+	}
+	return pkgs, nil
+}
+
+// hasVendorDir reports whether the working directory contains a vendor
+// directory of a module (i.e. with a vendor/modules.txt file).
+func hasVendorDir() bool {
+	info, err := os.Stat(filepath.Join("vendor", "modules.txt"))
+	return err == nil && !info.IsDir()
+}
+
+// checkBuildTags checks that the given comma-separated build tag combination
+// is a valid build constraint.
+func checkBuildTags(tags string) error {
+	for _, tag := range strings.Split(tags, ",") {
+		if _, err := constraint.Parse("//go:build " + tag); err != nil || len(tag) == 0 || strings.ContainsAny(tag, "!&|() ") {
+			return errors.Errorf("invalid build tag %q of build tag combination %q", tag, tags)
+		}
+	}
+	return nil
+}
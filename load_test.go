@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestLoadPkgErrors(t *testing.T) {
+	golden := []struct {
+		// fixture module of testdata.
+		fixture string
+		pkgPath string
+		opts    *GenOptions
+		// expected hint of ErrPackageLoad; empty if loaded.
+		hint string
+	}{
+		{
+			fixture: "tagged",
+			pkgPath: fixturePkgPath,
+			opts:    &GenOptions{},
+			hint:    "the build tag selection matched no source files; check build tags (GOFLAGS=-tags=...)",
+		},
+		{
+			fixture: "tagged",
+			pkgPath: fixturePkgPath,
+			opts:    &GenOptions{PkgTags: "sdl_tagged"},
+		},
+		{
+			fixture: "sdl",
+			pkgPath: "./nosuch",
+			opts:    &GenOptions{},
+			hint:    "the package directory was not found; check the package path (-pkg)",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.fixture+"/"+g.pkgPath+"/"+g.opts.PkgTags, func(t *testing.T) {
+			if len(g.hint) == 0 {
+				got := genFixture(t, g.fixture, g.opts)
+				want := "func (window *Window) Show() bool {\n\treturn ShowWindow(window)\n}"
+				if method := got.method(t, "(*Window).Show"); method != want {
+					t.Errorf("method mismatch; expected %q, got %q", want, method)
+				}
+				return
+			}
+			newFixture(t, g.fixture)
+			_, err := loadPkg(g.pkgPath, g.opts)
+			var e *ErrPackageLoad
+			if !errors.As(err, &e) {
+				t.Fatalf("expected ErrPackageLoad, got %v", err)
+			}
+			if e.Hint != g.hint {
+				t.Errorf("hint mismatch; expected %q, got %q", g.hint, e.Hint)
+			}
+		})
+	}
+}
+
+func TestCheckPkgs(t *testing.T) {
+	golden := []struct {
+		name string
+		pkgs []*packages.Package
+		// expected error; empty if valid.
+		err string
+	}{
+		{
+			name: "no packages",
+			err:  "unable to load package \"./...\": no packages matched\n\thint: the package path and build tag selection matched no packages; check the package path (-pkg) and build tags (GOFLAGS=-tags=...)",
+		},
+		{
+			name: "empty syntax",
+			pkgs: []*packages.Package{{PkgPath: fixturePkgPath}, {PkgPath: fixturePkgPath + "/sub"}},
+			err:  "unable to load package \"./...\": no Go source files matched\n\thint: the package path and build tag selection matched no source files; check the package path (-pkg) and build tags (GOFLAGS=-tags=...)",
+		},
+		{
+			name: "list error",
+			pkgs: []*packages.Package{{PkgPath: fixturePkgPath, Errors: []packages.Error{{Msg: "go.mod file not found in current directory or any parent directory", Kind: packages.ListError}}}},
+			err:  "unable to load package \"" + fixturePkgPath + "\": -: go.mod file not found in current directory or any parent directory\n\thint: no go.mod file was found; run genmethods from within a Go module",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			err := checkPkgs("./...", g.pkgs)
+			if err == nil {
+				t.Fatalf("expected error %q, got nil", g.err)
+			}
+			if got := err.Error(); got != g.err {
+				t.Errorf("error mismatch; expected %q, got %q", g.err, got)
+			}
+		})
+	}
+}
@@ -2,13 +2,28 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
 	"go/types"
+	"io/fs"
 	"log"
 	"os"
+	"path"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mewpkg/clog"
 	"github.com/pkg/errors"
@@ -16,207 +31,2573 @@ import (
 )
 
 func main() {
+	if isVetTool(os.Args[1:]) {
+		runVetTool()
+		return
+	}
 	var (
-		output  string
-		pkgPath string
-		verbose bool
+		output     string
+		pkgPath    string
+		verbose    bool
+		lint       bool
+		watch      bool
+		stats      bool
+		statsOnly  bool
+		globals    bool
+		explain    bool
+		maxResults int
+		variants   []string
+		pluginPath string
+		configPath string
+		schemaPath string
+		opts       GenOptions
 	)
-	flag.StringVar(&output, "o", "", "output path")
-	flag.StringVar(&pkgPath, "pkg", "github.com/jupiterrider/purego-sdl3/sdl", "package path")
+	flag.BoolVar(&opts.SanitizeNames, "sanitize-names", false, "convert snake_case function names to CamelCase method names (e.g. render_clear to RenderClear)")
+	flag.Var((*stringsFlag)(&opts.RenameRules), "rename-rule", "sed-style substitution rule applied to method names after renames, e.g. `s/^SDL_//` (repeatable; applied in order)")
+	flag.BoolVar(&opts.CheckNames, "check-names", false, "check that method names are valid Go identifiers, falling back to the function name otherwise")
+	flag.StringVar(&configPath, "config", "", "path to JSON or TOML config file (default genmethods.toml of the current directory, its parents up to the module root, or $XDG_CONFIG_HOME/genmethods)")
+	flag.StringVar(&schemaPath, "schema", "", "path to JSON Schema validating the config file (default embedded schema)")
+	flag.StringVar(&output, "o", "", "output path; standard output if empty or \"-\"")
+	flag.StringVar(&pkgPath, "pkg", "github.com/jupiterrider/purego-sdl3/sdl", "package path (comma-separated list or pattern for multi-package mode)")
+	flag.BoolVar(&opts.FileDoc, "file-doc", false, "emit package comment in the generated file (as non-doc comment if package doc already exists)")
+	flag.BoolVar(&opts.GenReadme, "gen-readme", false, "update table of generated methods in README.md of the package")
+	flag.StringVar(&opts.EmitDocs, "emit-docs", "", "write Markdown API table of generated methods to the given path (e.g. api.md)")
+	flag.BoolVar(&opts.EmitVerify, "emit-verify", false, "write standalone verification program (verify_gen.go, //go:build ignore) of generated methods next to the output file")
+	flag.BoolVar(&opts.GenMocks, "gen-mocks", false, "generate testify mocks (e.g. MockWindow) of receiver types in a _mocks_gen_test.go file next to the output file")
+	flag.BoolVar(&opts.InterfaceRecv, "interface-recv", false, "generate methods on configured concrete types satisfying interface first parameters")
+	flag.BoolVar(&opts.SplitByType, "split-by-type", false, "generate one output file per receiver type, in the output directory (-o) or package directory")
+	flag.BoolVar(&opts.GroupByFile, "group-by-file", false, "generate one output file per source file (e.g. window_methods_gen.go for window.go) with the build constraints of the source file, in the output directory (-o) or package directory")
+	flag.StringVar(&opts.OutputPattern, "output-pattern", "", "output path template in Go template syntax with fields .PkgDir, .PkgName, .TypeName and .TypeShortName, evaluated per receiver type in split mode (e.g. `{{.PkgDir}}/{{.TypeShortName}}_gen.go`)")
+	flag.StringVar(&opts.SplitTemplate, "split-template", defaultSplitTemplate, "output file name template of split mode; placeholders {type}, {type_lower} and {type_snake} (e.g. `{type_snake}_methods.go`)")
+	flag.BoolVar(&opts.DocNormalize, "doc-normalize", false, "normalize copied doc comments to start with the method name and end the first paragraph with a period")
+	flag.BoolVar(&opts.MergeDoc, "merge-doc", false, "append the additional documentation of the docs section of the config file to the doc comments of generated methods, after a blank comment line")
+	flag.IntVar(&opts.FormatWidth, "format-width", 0, "maximum line length of generated doc comments; long comment lines are re-flowed (best-effort, code lines are not affected)")
+	flag.BoolVar(&opts.NoFormat, "no-format", false, "skip formatting of generated source, for faster generation (run gofmt separately)")
+	flag.BoolVar(&opts.AdaptRecv, "adapt-recv", false, "adapt receiver pointer-ness to valid method types (e.g. *Window methods for functions taking Window)")
+	flag.BoolVar(&opts.SummaryComment, "summary-comment", false, "emit comment summarizing the number of methods per receiver type")
+	flag.BoolVar(&opts.GenDeepCopy, "gen-deepcopy", false, "generate DeepCopy methods for copy functions (e.g. CopySurface(s *Surface) (*Surface, error))")
+	flag.BoolVar(&opts.PreserveAliases, "preserve-aliases", false, "preserve type aliases of receiver parameters instead of normalizing them to the aliased type")
+	flag.BoolVar(&opts.AnyPosition, "any-position", false, "convert functions to methods on any parameter with a valid method type, not only the first")
+	flag.StringVar(&opts.RecvPriority, "recv-priority", "first", "receiver parameter priority in any-position mode (first or last)")
+	flag.BoolVar(&opts.ErrorOnSkip, "error-on-skip", false, "report an error when skipping functions with a valid receiver type")
+	flag.IntVar(&opts.MinResults, "min-results", 0, "skip functions with fewer results")
+	flag.IntVar(&maxResults, "max-results", -1, "skip functions with more results (-1 for no limit)")
+	flag.BoolVar(&opts.MustReturnError, "must-return-error", false, "skip functions whose last result is not of type error")
+	flag.StringVar(&opts.SkipDocKeyword, "skip-doc-keyword", "", "skip functions whose doc comment contains the given keyword, case-insensitive (e.g. \"internal use only\")")
+	flag.Var((*stringsFlag)(&opts.RewriteImports), "rewrite-import", "rewrite import path of generated file, of the form `old/path=new/path` (repeatable)")
+	flag.Func("types", "comma-separated list of receiver type names resolved within the package, including unexported (e.g. `*Window,*renderer`)", func(s string) error {
+		opts.Types = append(opts.Types, strings.Split(s, ",")...)
+		return nil
+	})
+	flag.Func("types-file", "file listing receiver type names resolved like -types, one per line; blank lines and # comments are ignored (repeatable)", func(s string) error {
+		typeNames, err := readTypesFile(s)
+		if err != nil {
+			return err
+		}
+		opts.Types = append(opts.Types, typeNames...)
+		return nil
+	})
+	flag.StringVar(&opts.Implements, "implements", "", "interface of the package which generated methods are filtered and ordered to implement (e.g. Drawable)")
+	flag.StringVar(&pluginPath, "plugin", "", "Go plugin (.so) exporting a GenMethodBody function generating the forwarding statement of each method, of type func(*ast.FuncDecl, ast.Stmt) ast.Stmt")
+	flag.StringVar(&opts.Filter, "filter", "", "filter command post-processing the generated Go source; reads source from stdin and writes transformed source to stdout")
+	flag.BoolVar(&opts.ExpandResults, "expand-results", false, "split forwarded calls into an assignment and a return statement (e.g. result := Foo(recv); return result)")
+	flag.BoolVar(&opts.GenMetrics, "gen-metrics", false, "record Prometheus metrics of generated methods (call duration summary, call and error counters), registered as package-level variables named after package, receiver and method (e.g. sdl_window_set_title_duration_seconds)")
+	flag.StringVar(&opts.Metrics, "metrics", "", "metrics call template of generated methods, called before forwarding, e.g. `metrics.Inc(\"{func}\")`; placeholders {recv}, {func} and {method}")
+	flag.StringVar(&opts.MetricsDefer, "metrics-defer", "", "metrics call template of generated methods, deferred to run after the forwarded call returns, e.g. `metrics.Since(\"{func}\", time.Now())`; placeholders as of -metrics")
+	flag.Var((*stringsFlag)(&opts.MetricsImports), "metrics-import", "import path of package referenced by the metrics call templates, e.g. `example.com/metrics` (repeatable)")
+	flag.Func("lazy-once", "comma-separated list of functions whose methods call the function once and return the cached result on subsequent calls, using package-level sync.Once and result variables shared by all receivers (e.g. `GetWindowDisplay`)", func(s string) error {
+		opts.LazyOnce = append(opts.LazyOnce, strings.Split(s, ",")...)
+		return nil
+	})
+	flag.Var((*stringsFlag)(&opts.AddInterfaceAssertions), "add-interface-assertion", "emit compile-time assertion of the given interface of the package for each receiver type of generated methods, e.g. `Drawable` (repeatable)")
+	flag.BoolVar(&opts.AssertInterfaces, "assert-interfaces", false, "emit compile-time assertions for fmt.Stringer and io.Closer implemented by generated methods (e.g. var _ io.Closer = (*Window)(nil))")
+	flag.BoolVar(&opts.InjectContext, "inject-context", false, "add a ctx context.Context first parameter to generated methods (not passed to the forwarded call)")
+	flag.BoolVar(&opts.InjectContext, "inject-ctx", false, "alias of -inject-context")
+	flag.BoolVar(&opts.AutoFluent, "auto-fluent", false, "document methods of functions returning their receiver (e.g. func ResetWindow(w *Window) *Window) as chainable")
+	flag.BoolVar(&opts.WrapErrors, "wrap-errors", false, "wrap errors returned by forwarded calls of error-returning methods with the name of the forwarded function (e.g. fmt.Errorf(\"Foo: %w\", err))")
+	flag.BoolVar(&opts.Recover, "recover", false, "recover panics of forwarded calls in error-returning methods, returning them as errors")
+	flag.IntVar(&opts.Jobs, "j", runtime.GOMAXPROCS(0), "maximum number of packages generated concurrently")
+	flag.BoolVar(&stats, "stats", false, "print generation statistics as JSON to standard error")
+	flag.BoolVar(&globals, "report-global", false, "print exported functions without parameters of valid receiver types (candidates for a singleton or global wrapper) to standard output, without generating")
+	flag.Var((*stringsFlag)(&variants), "check-variants", "compare generated methods across build tag combinations of the package, e.g. `cgo` and `!cgo` (repeatable; the pseudo-tags cgo and !cgo set CGO_ENABLED), reporting divergences without generating")
+	flag.BoolVar(&explain, "explain", false, "print the configuration source (directive, flag, config file or default) determining the name and inclusion of each generated method to standard output, without generating")
+	flag.BoolVar(&statsOnly, "stats-only", false, "print audit of the method-ability of package functions to standard output, without generating")
+	flag.BoolVar(&lint, "lint", false, "check that the output file is up to date, without regenerating it")
+	flag.BoolVar(&watch, "watch", false, "watch the source files of the package and regenerate the output on each change")
+	flag.BoolVar(&opts.StubNilChecks, "stub-nil-checks", false, "insert nil-receiver guard at the start of each method")
+	flag.BoolVar(&opts.NilGuard, "nil-guard", false, "insert nil-receiver guard panicking at the start of each pointer-receiver method (e.g. panic(\"nil Renderer\"))")
+	flag.StringVar(&opts.NilGuardMessage, "nil-guard-message", defaultNilGuardMessage, "panic message of nil-receiver guards (-nil-guard); placeholders {type} and {method}")
+	flag.Var((*fileModeFlag)(&opts.FilePerm), "file-perm", "file permission bits of generated Go files in octal, e.g. `0o600` (default 0o644)")
+	flag.BoolVar(&opts.Force, "force", false, "overwrite output files lacking the \"Code generated ... DO NOT EDIT.\" marker (e.g. modified by hand)")
+	flag.StringVar(&opts.PackageName, "package", "", "package name of generated files (default name of the loaded package)")
+	flag.BoolVar(&opts.GenWrap, "gen-wrap", false, "generate a wrapper package at the output path, with a wrapper type of each receiver type holding the wrapped value, its Wrap constructor (e.g. WrapWindow) and methods forwarding to the functions of the package")
+	flag.BoolVar(&opts.GenConstructors, "gen-constructors", false, "generate constructors of wrapper types (-gen-wrap) forwarding to the constructors of the package, i.e. functions without receiver-typed parameters returning a receiver type, optionally followed by an error (e.g. CreateWindow(title string) (*Window, error))")
+	flag.StringVar(&opts.UnwrapField, "unwrap-field", defaultUnwrapField, "name of the wrapped value field of wrapper types (-gen-wrap)")
+	flag.StringVar(&opts.SinceCommit, "since-commit", "", "only generate methods of packages with source files changed since the given git commit (e.g. HEAD~1)")
+	flag.BoolVar(&opts.Merge, "merge", false, "merge generated methods into the region between \"// genmethods:begin\" and \"// genmethods:end\" of the output file")
+	flag.StringVar(&opts.PkgTags, "pkg-tag", "", "comma-separated build tag combination used to load the package, also emitted as build constraint of the generated file (e.g. `linux,amd64`)")
+	flag.BoolVar(&opts.AllowBuiltinShadow, "allow-builtin-shadow", false, "allow method names of predeclared identifiers (e.g. len or copy) without warning")
+	flag.BoolVar(&opts.NoMethodSetCheck, "no-method-set-check", false, "skip the check for generated methods shadowing methods promoted from embedded fields (faster for types with deep embedding)")
+	flag.BoolVar(&opts.Vendor, "vendor", false, "load packages in vendor mode (-mod=vendor); auto-detected if the working directory contains a vendor directory")
+	flag.DurationVar(&opts.PkgLoadTimeout, "pkg-load-timeout", 0, "maximum duration of loading packages, e.g. `30s` (no limit if zero)")
 	flag.BoolVar(&verbose, "v", false, "enable verbose debug output")
-	flag.Parse()
+	// check subcommand (e.g. `genmethods check -o sdl/methods.go`).
+	args := os.Args[1:]
+	check := len(args) > 0 && args[0] == "check"
+	if check {
+		args = args[1:]
+	}
+	flag.CommandLine.Parse(args)
 	if !verbose {
 		clog.SetPathLevel("main", clog.LevelWarn)
 	}
-	if err := genMethods(pkgPath, output); err != nil {
+	if maxResults >= 0 {
+		opts.MaxResults = &maxResults
+	}
+	if output == stdoutOutput {
+		if isMultiFile(&opts) {
+			log.Fatal("standard output (-o -) is not supported in split mode (-split-by-type) or group-by-file mode (-group-by-file)")
+		}
+		output = ""
+	}
+	if err := opts.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if len(pluginPath) > 0 {
+		methodBody, err := loadPlugin(pluginPath)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		opts.MethodBody = methodBody
+	}
+	if len(configPath) == 0 {
+		path, err := findConfig()
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		if len(path) > 0 {
+			clog.Debugf("using config file %q", path)
+			configPath = path
+		}
+	}
+	if check {
+		code, err := runCheck(os.Stderr, pkgPath, output, configPath, schemaPath, &opts)
+		if err != nil {
+			// distinguish failures to run the checks from failed checks.
+			log.Printf("%+v", err)
+		}
+		os.Exit(code)
+	}
+	if len(configPath) > 0 {
+		config, err := loadConfig(configPath, schemaPath)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		opts.Config = config
+	}
+	if globals {
+		gen, err := newGen(pkgPath, output, &opts)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		gen.printGlobals(os.Stdout)
+		return
+	}
+	if len(variants) > 0 {
+		code, err := runCheckVariants(os.Stderr, pkgPath, variants, &opts)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		os.Exit(code)
+	}
+	if explain {
+		gen, err := newGen(pkgPath, output, &opts)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		gen.printExplain(os.Stdout)
+		return
+	}
+	if statsOnly {
+		gen, err := newGen(pkgPath, output, &opts)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		gen.printAudit(os.Stdout)
+		return
+	}
+	if lint {
+		if len(output) == 0 {
+			log.Fatalln("lint mode requires an output path (-o)")
+		}
+		upToDate, err := checkUpToDate(pkgPath, output, &opts)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		if !upToDate {
+			fmt.Fprintf(os.Stderr, "%s is not up to date; re-run genmethods\n", output)
+			os.Exit(1)
+		}
+		return
+	}
+	if watch {
+		if err := watchPkg(os.Stderr, pkgPath, output, &opts); err != nil {
+			log.Fatalf("%+v", err)
+		}
+		return
+	}
+	genStats, err := genMethods(pkgPath, output, &opts)
+	if err != nil {
 		log.Fatalf("%+v", err)
 	}
+	if stats {
+		enc := json.NewEncoder(os.Stderr)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(genStats); err != nil {
+			log.Fatalf("%+v", errors.WithStack(err))
+		}
+	}
+}
+
+// GenOptions specifies the options used during method generation.
+type GenOptions struct {
+	// insert nil-receiver guard at the start of each pointer-receiver method.
+	StubNilChecks bool
+	// insert nil-receiver guard panicking at the start of each pointer-receiver
+	// method.
+	NilGuard bool
+	// panic message of nil-receiver guards (e.g. "nil {type}"); defaults to
+	// "nil {type}".
+	NilGuardMessage string
+	// merge generated methods into the marked region of the output file.
+	Merge bool
+	// overwrite output files lacking the generated code marker.
+	Force bool
+	// append the additional documentation of the config file (docs section) to
+	// the doc comments of generated methods.
+	MergeDoc bool
+	// file permission bits of generated Go files (optional); 0o644 if zero.
+	FilePerm os.FileMode
+	// package name of the package clause of generated files (optional); the
+	// name of the loaded package by default.
+	PackageName string
+	// generate a wrapper package at the output path, with wrapper types of the
+	// receiver types holding the wrapped value, their Wrap constructors and
+	// methods forwarding to the package functions.
+	GenWrap bool
+	// generate constructors of wrapper types (-gen-wrap) forwarding to the
+	// constructors of the package.
+	GenConstructors bool
+	// name of the wrapped value field of wrapper types (optional); "inner" if
+	// empty.
+	UnwrapField string
+	// only generate methods of packages with source files changed since the
+	// given git commit (e.g. "HEAD~1").
+	SinceCommit string
+	// emit package comment in the generated file; as non-doc comment if the
+	// package already has a package doc comment.
+	FileDoc bool
+	// update table of generated methods in the README.md of the package.
+	GenReadme bool
+	// path of Markdown API table of generated methods to write.
+	EmitDocs string
+	// write a standalone verification program of the generated methods
+	// (verify_gen.go), excluded from builds by a `//go:build ignore`
+	// constraint.
+	EmitVerify bool
+	// generate testify mocks of receiver types in a _mocks_gen_test.go file.
+	GenMocks bool
+	// generate methods on the configured concrete types satisfying interface
+	// first parameters.
+	InterfaceRecv bool
+	// generate one output file per receiver type.
+	SplitByType bool
+	// generate one output file per source file (e.g. "window_methods_gen.go"
+	// for "window.go").
+	GroupByFile bool
+	// output file name template of split mode (e.g. "{type_snake}_methods.go");
+	// defaults to "{type_lower}_methods.go".
+	SplitTemplate string
+	// output path template in Go template syntax (e.g.
+	// `{{.PkgDir}}/{{.TypeShortName}}_gen.go`), with OutputPatternData as data;
+	// evaluated per receiver type in split mode. Takes precedence over the
+	// split template.
+	OutputPattern string
+	// maximum line length of generated doc comments (best-effort); long doc
+	// comment lines are re-flowed at word boundaries. Zero disables re-flow.
+	FormatWidth int
+	// normalize copied doc comments of generated methods to start with the
+	// method name and end the first paragraph with a period.
+	DocNormalize bool
+	// skip formatting of the generated source (e.g. when formatted by a
+	// subsequent gofmt pass).
+	NoFormat bool
+	// adapt the pointer-ness of receivers to valid method types (e.g. generate
+	// *Window methods for functions taking Window by value).
+	AdaptRecv bool
+	// emit comment summarizing the number of methods per receiver type.
+	SummaryComment bool
+	// generate DeepCopy methods for copy functions (e.g. `func
+	// CopySurface(s *Surface) (*Surface, error)`).
+	GenDeepCopy bool
+	// preserve type aliases of receiver parameters (e.g. `func (w WinPtr)
+	// Foo()` for `type WinPtr = *Window`) instead of normalizing them to the
+	// aliased type (e.g. `func (w *Window) Foo()`).
+	PreserveAliases bool
+	// convert functions to methods on any parameter with a valid method type,
+	// not only the first.
+	AnyPosition bool
+	// receiver parameter priority in any-position mode; either "first"
+	// (default) or "last".
+	RecvPriority string
+	// report an error when skipping functions with a valid receiver type.
+	ErrorOnSkip bool
+	// skip functions whose doc comment contains the given keyword, matched
+	// case-insensitively (e.g. "internal use only").
+	SkipDocKeyword string
+	// skip functions with fewer results.
+	MinResults int
+	// skip functions with more results (optional); no limit if nil.
+	MaxResults *int
+	// skip functions whose last result is not of type error.
+	MustReturnError bool
+	// import path rewrites applied to the generated file, each of the form
+	// "old/path=new/path".
+	RewriteImports []string
+	// receiver type names (e.g. "*Window" or "renderer") resolved within the
+	// scope of the analyzed package, including unexported type names; of -types
+	// and -types-file.
+	Types []string
+	// interface of the analyzed package (e.g. "Drawable") which generated
+	// methods are filtered and ordered to implement.
+	Implements string
+	// filter command post-processing the generated Go source, read from
+	// standard input and written to standard output (e.g. "./my-transform").
+	Filter string
+	// split forwarded calls of methods with results into an assignment and a
+	// return statement (e.g. `result := Foo(recv); return result`).
+	ExpandResults bool
+	// metrics call template of generated methods, called before forwarding
+	// (optional; e.g. `metrics.Inc("{func}")`). Placeholders as of forwarding
+	// templates.
+	Metrics string
+	// metrics call template of generated methods, deferred before forwarding so
+	// that it runs after the forwarded call returns (optional; e.g.
+	// `metrics.Since("{func}", time.Now())`).
+	MetricsDefer string
+	// import paths of packages referenced by the metrics call templates (e.g.
+	// "example.com/metrics").
+	MetricsImports []string
+	// record Prometheus metrics of call counts, durations and errors of
+	// generated methods in package-level variables.
+	GenMetrics bool
+	// names of functions whose methods cache the results of the first call in
+	// package-level variables (e.g. "GetWindowDisplay"); the functions must take
+	// only the receiver and return at least one result.
+	LazyOnce []string
+	// emit compile-time assertions of standard library interfaces implemented
+	// by generated methods (e.g. `var _ io.Closer = (*Window)(nil)`).
+	AssertInterfaces bool
+	// interfaces of the package asserted for each receiver type of generated
+	// methods (e.g. `var _ Drawable = (*Window)(nil)`).
+	AddInterfaceAssertions []string
+	// add a `ctx context.Context` first parameter to generated methods, not
+	// passed to the forwarded call.
+	InjectContext bool
+	// recover panics of forwarded calls in error-returning methods, returning
+	// them as errors.
+	Recover bool
+	// document generated methods of functions returning their receiver (e.g.
+	// `func ResetWindow(w *Window) *Window`) as chainable, with results of the
+	// receiver type of the method.
+	AutoFluent bool
+	// wrap errors returned by forwarded calls of error-returning methods with
+	// the name of the forwarded function (e.g. `fmt.Errorf("Foo: %w", err)`).
+	WrapErrors bool
+	// post-process each generated method declaration (optional); returning nil
+	// drops the method, and its function is reported as skipped.
+	AfterMethod func(*ast.FuncDecl) *ast.FuncDecl
+	// generate the forwarding statement of each generated method (optional),
+	// given the source function and the default forwarding statement (e.g.
+	// `return Foo(recv, args)`); returning nil keeps the default statement. Set
+	// by the GenMethodBody symbol of a Go plugin (-plugin).
+	MethodBody func(funcDecl *ast.FuncDecl, stmt ast.Stmt) ast.Stmt
+	// user-provided configuration (optional).
+	Config *Config
+	// check that method names are valid identifiers, falling back to the
+	// function name otherwise.
+	CheckNames bool
+	// sed-style substitution rules applied in order to method names after
+	// renames (e.g. `s/^SDL_//`).
+	RenameRules []string
+	// convert snake_case function names to CamelCase method names (e.g.
+	// render_clear to RenderClear).
+	SanitizeNames bool
+	// build tag combination used to load packages (e.g. "linux,amd64"); also
+	// the build constraint of the generated file.
+	PkgTags string
+	// additional environment variables used to load packages (e.g.
+	// "CGO_ENABLED=0").
+	PkgEnv []string
+	// load packages in vendor mode (-mod=vendor); auto-detected if the working
+	// directory contains a vendor directory.
+	Vendor bool
+	// maximum duration of loading packages (optional); no limit if zero.
+	PkgLoadTimeout time.Duration
+	// skip the check for generated methods shadowing methods promoted from
+	// embedded fields of the receiver type.
+	NoMethodSetCheck bool
+	// allow method names of predeclared identifiers (e.g. len or copy) without
+	// warning.
+	AllowBuiltinShadow bool
+	// maximum number of packages generated concurrently in multi-package mode.
+	Jobs int
+}
+
+// warnExecPermOnce guards the warning about execute bits of file permission
+// bits (-file-perm).
+var warnExecPermOnce sync.Once
+
+// Validate checks the generation options for invalid values and contradictory
+// combinations (e.g. -group-by-file and -split-by-type), which would otherwise
+// fail late or silently produce no output. All violations are reported in a
+// single error.
+func (opts *GenOptions) Validate() error {
+	var errs []string
+	switch opts.RecvPriority {
+	case "", "first":
+	case "last":
+		if !opts.AnyPosition {
+			errs = append(errs, "receiver priority (-recv-priority=last) requires any-position mode (-any-position)")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("invalid receiver priority %q; expected first or last", opts.RecvPriority))
+	}
+	if opts.MinResults < 0 {
+		errs = append(errs, fmt.Sprintf("invalid minimum result count (-min-results=%d); expected non-negative count", opts.MinResults))
+	}
+	if opts.MaxResults != nil {
+		switch maxResults := *opts.MaxResults; {
+		case maxResults < 0:
+			errs = append(errs, fmt.Sprintf("invalid maximum result count (-max-results=%d); expected non-negative count", maxResults))
+		case opts.MinResults > maxResults:
+			errs = append(errs, fmt.Sprintf("invalid result count range; minimum (-min-results=%d) exceeds maximum (-max-results=%d)", opts.MinResults, maxResults))
+		case opts.MustReturnError && maxResults == 0:
+			errs = append(errs, "error-returning functions (-must-return-error) are skipped by maximum result count of zero (-max-results=0)")
+		}
+	}
+	if opts.StubNilChecks && opts.NilGuard {
+		errs = append(errs, "invalid nil-receiver guard; -stub-nil-checks and -nil-guard are mutually exclusive")
+	}
+	if opts.SplitByType && opts.GroupByFile {
+		errs = append(errs, "invalid output mode; -group-by-file and -split-by-type are mutually exclusive")
+	}
+	if opts.GroupByFile && len(opts.OutputPattern) > 0 {
+		errs = append(errs, "output pattern (-output-pattern) is not supported in group-by-file mode (-group-by-file)")
+	}
+	if opts.Merge && isMultiFile(opts) {
+		errs = append(errs, "merge mode (-merge) is not supported in split mode (-split-by-type) or group-by-file mode (-group-by-file)")
+	}
+	if opts.PkgLoadTimeout < 0 {
+		errs = append(errs, fmt.Sprintf("invalid package load timeout (-pkg-load-timeout=%v); expected non-negative duration", opts.PkgLoadTimeout))
+	}
+	for _, spec := range opts.RenameRules {
+		if _, err := parseRenameRule(spec); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	for _, template := range []string{opts.Metrics, opts.MetricsDefer} {
+		if len(template) == 0 {
+			continue
+		}
+		if _, err := metricsCall(template, "recv", "Func", "Method"); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(opts.MetricsImports) > 0 && len(opts.Metrics) == 0 && len(opts.MetricsDefer) == 0 {
+		errs = append(errs, "metrics import (-metrics-import) requires a metrics call template (-metrics or -metrics-defer)")
+	}
+	if opts.FilePerm != 0 {
+		switch perm := opts.FilePerm; {
+		case perm&^0o777 != 0:
+			errs = append(errs, fmt.Sprintf("invalid file permission bits (-file-perm=%O); expected at most 0o777", uint32(perm)))
+		case perm&0o600 != 0o600:
+			errs = append(errs, fmt.Sprintf("invalid file permission bits (-file-perm=%O); generated files must be readable and writable by their owner to be regenerated", uint32(perm)))
+		case perm&0o111 != 0:
+			// options are validated by each generation pass; warn once.
+			warnExecPermOnce.Do(func() {
+				clog.Warnf("file permission bits (-file-perm=%O) include execute bits; generated Go files are not executable, consider dropping them (e.g. %O)", uint32(perm), uint32(perm&^0o111))
+			})
+		}
+	}
+	if opts.GenWrap {
+		errs = append(errs, opts.validateGenWrap()...)
+	} else if opts.GenConstructors {
+		errs = append(errs, "-gen-constructors requires wrapper package mode (-gen-wrap)")
+	}
+	if len(opts.PackageName) > 0 && (!token.IsIdentifier(opts.PackageName) || opts.PackageName == "_") {
+		errs = append(errs, fmt.Sprintf("invalid package name %q (-package)", opts.PackageName))
+	}
+	if len(opts.PkgTags) > 0 {
+		if err := checkBuildTags(opts.PkgTags); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errors.New(errs[0])
+	default:
+		return errors.Errorf("invalid generation options:\n\t%s", strings.Join(errs, "\n\t"))
+	}
+}
+
+// ParsedFunc is a function parsed for conversion to a method.
+type ParsedFunc struct {
+	// function declaration.
+	Decl *ast.FuncDecl
+	// resolved receiver type; nil if skipped.
+	ReceiverType types.Type
+	// computed method name; empty if skipped.
+	MethodName string
+	// function skipped for conversion to a method.
+	Skipped bool
+	// reason for skipping the function.
+	SkipReason string
+	// configuration source determining the method name (e.g. "config file
+	// (rename)").
+	NameSource string
+	// configuration source determining the inclusion of the function (e.g.
+	// "flag (-types)").
+	InclusionSource string
+}
+
+// Method is a generated method.
+type Method struct {
+	// method declaration.
+	Decl *ast.FuncDecl
+	// receiver type.
+	RecvType types.Type
+	// source function wrapped by the method; either a function or a variable of
+	// function type.
+	Func types.Object
+	// index of the receiver parameter of the source function.
+	RecvIndex int
+	// package-level declarations used by the method, emitted before the method
+	// (e.g. cache variables of lazy methods).
+	Decls []ast.Decl
+	// Prometheus metrics recorded by the method (-gen-metrics); nil if not
+	// recorded.
+	metrics *promMetrics
+}
+
+// GenerationStats records statistics of method generation. Counters are
+// updated atomically.
+type GenerationStats struct {
+	// number of source files processed.
+	FilesProcessed int64 `json:"files_processed"`
+	// number of functions scanned.
+	FuncsScanned int64 `json:"funcs_scanned"`
+	// number of functions skipped (i.e. not converted to methods).
+	FuncsSkipped int64 `json:"funcs_skipped"`
+	// number of methods generated.
+	MethodsGenerated int64 `json:"methods_generated"`
+	// number of method renames applied.
+	RenamesApplied int64 `json:"renames_applied"`
+	// number of generated methods returning the receiver for chaining
+	// (-auto-fluent).
+	FluentMethods int64 `json:"fluent_methods"`
+}
+
+// add atomically adds the counters of other to stats.
+func (stats *GenerationStats) add(other *GenerationStats) {
+	atomic.AddInt64(&stats.FilesProcessed, atomic.LoadInt64(&other.FilesProcessed))
+	atomic.AddInt64(&stats.FuncsScanned, atomic.LoadInt64(&other.FuncsScanned))
+	atomic.AddInt64(&stats.FuncsSkipped, atomic.LoadInt64(&other.FuncsSkipped))
+	atomic.AddInt64(&stats.MethodsGenerated, atomic.LoadInt64(&other.MethodsGenerated))
+	atomic.AddInt64(&stats.RenamesApplied, atomic.LoadInt64(&other.RenamesApplied))
+	atomic.AddInt64(&stats.FluentMethods, atomic.LoadInt64(&other.FluentMethods))
+}
+
+// SkippedFunc is a function for which no method was generated.
+type SkippedFunc struct {
+	// function name.
+	Name string
+	// reason why the function was skipped.
+	Reason string
 }
 
 type Gen struct {
+	// Statistics of method generation.
+	Stats GenerationStats
+	// Functions parsed for conversion to methods, in source order; including
+	// skipped functions.
+	ParsedFuncs []ParsedFunc
 	// package to analyze
 	pkg *packages.Package
+	// generation options
+	opts *GenOptions
+	// output path of generated methods
+	output string
 	// generated methods
-	methods []*ast.FuncDecl
+	methods []*Method
+	// import paths available to generated methods; only referenced imports are
+	// emitted in each generated file.
+	imports map[string]bool
+	// map from import path to package names of imports referenced by names
+	// other than the last element of the import path (e.g. `stdio "io"`).
+	importNames map[string]map[string]bool
+	// generated methods return ErrNilReceiver
+	useErrNilReceiver bool
+	// receiver types of -types resolved within package scope.
+	resolvedTypes map[string]bool
+	// functions skipped during generation.
+	skipped []*SkippedFunc
+	// map from skipped function name to reason why the function was skipped.
+	skipReasons map[string]string
+	// output paths of generated files in split and group-by-file mode.
+	splitOutputs []string
+	// map from method key (receiver type and method name) to source function
+	// name of generated methods.
+	methodFuncs map[string]string
+	// map from non-preferred variant to preferred function name.
+	variantSkips map[string]string
+	// map from receiver base type to method set, for the shadowing check.
+	methodSets map[string]*types.MethodSet
+	// rename rules of method names (-rename-rule), in order of application.
+	renameRules []*renameRule
+	// raw handle accessors of wrapper receiver types, sorted by receiver type.
+	accessors []*accessor
+	// map from receiver base type to type of the wrapped value field of its
+	// wrapper type (-gen-wrap).
+	wrappedTypes map[string]types.Type
+	// map from wrapper type name to generated constructors of the wrapper type
+	// (-gen-constructors).
+	constructors map[string][]*ast.FuncDecl
+}
+
+// genMethods generates methods for the given package (or packages in
+// multi-package mode), and returns statistics of the generation.
+func genMethods(pkgPath, output string, opts *GenOptions) (*GenerationStats, error) {
+	// validate before loading packages.
+	if err := opts.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if isMultiPkg(pkgPath) {
+		return genMultiPkg(pkgPath, output, opts)
+	}
+	pkg, err := loadPkg(pkgPath, opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(opts.OutputPattern) > 0 {
+		if len(output) > 0 {
+			return nil, errors.New("output path (-o) and output pattern (-output-pattern) are mutually exclusive")
+		}
+		if output, err = pkgOutput(pkg, opts); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if len(opts.SinceCommit) > 0 {
+		changed, err := changedFiles(opts.SinceCommit)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !pkgChanged(pkg, changed) {
+			return &GenerationStats{}, nil
+		}
+	}
+	gen, err := newGenFromPkg(pkg, output, opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := gen.printMethods(output); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &gen.Stats, nil
+}
+
+// pkgOutput returns the output path of the methods of the given package, as
+// specified by the output pattern (-output-pattern); or an empty output path in
+// split mode, where the output path of each receiver type is specified by the
+// output pattern, and in group-by-file mode.
+func pkgOutput(pkg *packages.Package, opts *GenOptions) (string, error) {
+	if isMultiFile(opts) {
+		return "", nil
+	}
+	return patternOutput(opts.OutputPattern, pkg, nil)
+}
+
+// newGen loads the given package and generates methods for its functions,
+// using the specified output path and generation options.
+func newGen(pkgPath, output string, opts *GenOptions) (*Gen, error) {
+	pkg, err := loadPkg(pkgPath, opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newGenFromPkg(pkg, output, opts)
+}
+
+// newGenFromPkg generates methods for the functions of the given loaded
+// package, using the specified output path and generation options.
+func newGenFromPkg(pkg *packages.Package, output string, opts *GenOptions) (*Gen, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gen := &Gen{
+		pkg:    pkg,
+		opts:   opts,
+		output: output,
+	}
+	gen.Reset()
+	if err := gen.generate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return gen, nil
+}
+
+// Reset clears the generated methods, statistics and other state of previous
+// generation passes, keeping the loaded package. Reset allows for multiple
+// generation passes (e.g. with different generation options) on the same
+// package without reloading it.
+func (gen *Gen) Reset() {
+	gen.Stats = GenerationStats{}
+	gen.ParsedFuncs = nil
+	gen.methods = nil
+	gen.imports = make(map[string]bool)
+	gen.importNames = make(map[string]map[string]bool)
+	gen.useErrNilReceiver = false
+	gen.resolvedTypes = nil
+	gen.skipped = nil
+	gen.skipReasons = make(map[string]string)
+	gen.splitOutputs = nil
+	gen.methodFuncs = make(map[string]string)
+	gen.variantSkips = nil
+	gen.methodSets = make(map[string]*types.MethodSet)
+	gen.renameRules = nil
+	gen.accessors = nil
+	gen.wrappedTypes = make(map[string]types.Type)
+	gen.constructors = make(map[string][]*ast.FuncDecl)
+}
+
+// Regenerate resets the state of previous generation passes, and regenerates
+// methods for the loaded package using the given generation options.
+func (gen *Gen) Regenerate(opts *GenOptions) error {
+	if err := opts.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+	gen.Reset()
+	gen.opts = opts
+	if err := gen.generate(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// generate generates methods for the functions of the loaded package, using
+// the current generation options.
+func (gen *Gen) generate() error {
+	if gen.opts.GenWrap {
+		if err := gen.checkWrapOutput(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for _, spec := range gen.opts.RenameRules {
+		rule, err := parseRenameRule(spec)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		gen.renameRules = append(gen.renameRules, rule)
+	}
+	if err := gen.resolveTypes(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := gen.resolveVariants(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := gen.resolveAccessors(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := gen.checkAssertInterfaces(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := gen.parsePkg(); err != nil {
+		return errors.WithStack(err)
+	}
+	if len(gen.opts.Implements) > 0 {
+		if err := gen.implementInterface(gen.opts.Implements); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if gen.opts.GenConstructors {
+		gen.parseConstructors()
+	}
+	gen.addDeclImports()
+	return nil
+}
+
+// addDeclImports adds the imports of package-level declarations emitted
+// alongside the generated methods (e.g. ErrNilReceiver) to the imports
+// available to generated files. Imports are added up front, as generated files
+// are printed concurrently in split mode.
+func (gen *Gen) addDeclImports() {
+	if gen.useErrNilReceiver {
+		gen.imports["errors"] = true
+	}
+	if gen.opts.AssertInterfaces {
+		for _, iface := range stdInterfaces {
+			gen.imports[iface.pkgPath] = true
+		}
+	}
+	if config := gen.opts.Config; config != nil && len(config.SafeWrappers) > 0 {
+		gen.imports["sync"] = true
+	}
+}
+
+// resolveTypes resolves the receiver type names of -types and -types-file
+// within the scope of the analyzed package, including unexported type names.
+// All unresolved type names are reported in a single error.
+func (gen *Gen) resolveTypes() error {
+	gen.resolvedTypes = make(map[string]bool)
+	var unresolved []string
+	for _, typeName := range gen.opts.Types {
+		name, isPtr := strings.CutPrefix(typeName, "*")
+		obj, ok := gen.pkg.Types.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			unresolved = append(unresolved, strconv.Quote(name))
+			continue
+		}
+		typ := obj.Type()
+		if isPtr {
+			typ = types.NewPointer(typ)
+		}
+		gen.resolvedTypes[typ.String()] = true
+	}
+	switch len(unresolved) {
+	case 0:
+		return nil
+	case 1:
+		return errors.Errorf("unable to locate receiver type %s in scope of package %q", unresolved[0], gen.pkg.PkgPath)
+	default:
+		return errors.Errorf("unable to locate receiver types %s in scope of package %q", strings.Join(unresolved, ", "), gen.pkg.PkgPath)
+	}
+}
+
+// readTypesFile returns the receiver type names listed in the given types file
+// (-types-file), one per line. Blank lines and comments starting with '#' are
+// ignored, as are leading and trailing white space of each line.
+//
+// Example:
+//
+//	# windows
+//	*Window
+//	*renderer # unexported
+func readTypesFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var typeNames []string
+	for i, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if name := strings.TrimPrefix(line, "*"); !token.IsIdentifier(name) {
+			return nil, errors.Errorf("invalid receiver type name %q on line %d of types file %q", line, i+1, path)
+		}
+		typeNames = append(typeNames, line)
+	}
+	return typeNames, nil
+}
+
+// CheckUpToDate reports whether the given output file is identical to the
+// methods generated for the given package, using default generation options.
+func CheckUpToDate(pkgPath, outputFile string) (bool, error) {
+	return checkUpToDate(pkgPath, outputFile, &GenOptions{})
+}
+
+// checkUpToDate reports whether the given output file is identical to the
+// methods generated for the given package, using the specified generation
+// options.
+func checkUpToDate(pkgPath, outputFile string, opts *GenOptions) (bool, error) {
+	gen, err := newGen(pkgPath, outputFile, opts)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return gen.upToDate(outputFile)
+}
+
+// upToDate reports whether the given output file is identical to the
+// generated methods.
+func (gen *Gen) upToDate(outputFile string) (bool, error) {
+	want, err := gen.outputSource()
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	got, err := os.ReadFile(outputFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return bytes.Equal(got, want), nil
+}
+
+func (gen *Gen) parsePkg() error {
+	for _, file := range gen.pkg.Syntax {
+		if err := gen.parseFile(file); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (gen *Gen) parseFile(file *ast.File) error {
+	pos := gen.pkg.Fset.Position(file.FileStart)
+	clog.Debugln("file:", pos.Filename)
+	atomic.AddInt64(&gen.Stats.FilesProcessed, 1)
+	for _, decl := range file.Decls {
+		if err := gen.parseDecl(decl); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (gen *Gen) parseDecl(decl ast.Decl) error {
+	switch decl := decl.(type) {
+	case *ast.GenDecl:
+		//clog.Debugf("gen decl (%s): %#v", decl.Tok, decl)
+		if decl.Tok == token.VAR {
+			if err := gen.parseVarDecl(decl); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	case *ast.FuncDecl:
+		if err := gen.parseFuncDecl(decl); err != nil {
+			return errors.WithStack(err)
+		}
+	default:
+		panic(fmt.Errorf("support for declaration type %T not yet implemented", decl))
+	}
+	return nil
+}
+
+// parseVarDecl parses the given variable declaration, generating methods for
+// exported variables of function type (e.g. `var RenderClear func(renderer
+// *Renderer) bool`), as used by purego-style packages. Generated methods call
+// the function variable.
+func (gen *Gen) parseVarDecl(decl *ast.GenDecl) error {
+	for _, spec := range decl.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		funcType, ok := valueSpec.Type.(*ast.FuncType)
+		if !ok {
+			continue
+		}
+		doc := valueSpec.Doc
+		if doc == nil && len(decl.Specs) == 1 {
+			doc = decl.Doc
+		}
+		for _, name := range valueSpec.Names {
+			if !name.IsExported() {
+				continue // skip unexported function variables.
+			}
+			clog.Debugln("func var:", name)
+			funcDecl := &ast.FuncDecl{
+				Doc:  doc,
+				Name: name,
+				Type: &ast.FuncType{
+					Params:  nameParams(funcType.Params),
+					Results: funcType.Results,
+				},
+			}
+			if err := gen.parseFuncDecl(funcDecl); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	return nil
+}
+
+// nameParams returns a copy of the given parameter list, where unnamed and
+// blank parameters are named p0, p1, etc, so they may be forwarded.
+func nameParams(params *ast.FieldList) *ast.FieldList {
+	if params == nil {
+		return nil
+	}
+	newParams := &ast.FieldList{}
+	i := 0
+	for _, field := range params.List {
+		newField := &ast.Field{
+			Type: field.Type,
+		}
+		if len(field.Names) == 0 {
+			newField.Names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("p%d", i))}
+			i++
+		}
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				name = ast.NewIdent(fmt.Sprintf("p%d", i))
+			}
+			newField.Names = append(newField.Names, name)
+			i++
+		}
+		newParams.List = append(newParams.List, newField)
+	}
+	return newParams
+}
+
+func (gen *Gen) parseFuncDecl(decl *ast.FuncDecl) error {
+	if decl.Recv != nil {
+		return nil // skip methods (already generated).
+	}
+	atomic.AddInt64(&gen.Stats.FuncsScanned, 1)
+	// skips in order of precedence; directive, flag, config file.
+	if skipDirective(decl) {
+		gen.skipFunc(decl, "function has a //genmethods:skip directive")
+		return nil
+	}
+	if keyword := gen.opts.SkipDocKeyword; len(keyword) > 0 && strings.Contains(strings.ToLower(decl.Doc.Text()), strings.ToLower(keyword)) {
+		gen.skipFunc(decl, fmt.Sprintf("doc comment contains keyword %q", keyword))
+		return nil // skip functions annotated in doc comment.
+	}
+	if reason, ok := gen.skipResults(decl); ok {
+		gen.skipFunc(decl, reason)
+		return nil // skip functions not matching result filters.
+	}
+	if reason, ok := gen.skipVariant(decl.Name.String()); ok {
+		gen.skipFunc(decl, reason)
+		return nil // skip non-preferred variants.
+	}
+	if typeName, ok := recvDirective(decl); ok {
+		return gen.parseRecvDirective(decl, typeName)
+	}
+	if gen.opts.AnyPosition {
+		return gen.parseAnyPosition(decl)
+	}
+	var params []*ast.Field
+	// Params may be nil in synthetic ASTs.
+	if decl.Type.Params != nil {
+		params = decl.Type.Params.List
+	}
+	if len(params) == 0 {
+		gen.skipFunc(decl, "function has no parameters")
+		return nil // skip functions without parameters.
+	}
+	firstParam := params[0]
+	if len(firstParam.Names) != 1 {
+		// TODO: add support for `a, b T` parameter lists.
+		reason := "first parameter is part of an `a, b T` parameter list"
+		if _, ok := gen.methodRecvType(gen.pkg.TypesInfo.TypeOf(firstParam.Type)); ok {
+			return gen.skipMatchingFunc(decl, reason)
+		}
+		gen.skipFunc(decl, reason)
+		return nil // skip `a, b T` parameter lists for now.
+	}
+	clog.Debugln("func:", decl.Name)
+	firstParamName := firstParam.Names[0]
+	firstParamType := gen.pkg.TypesInfo.Types[firstParam.Type].Type
+	clog.Debugln("first param name:", firstParamName)
+	clog.Debugln("first param type:", firstParamType)
+	// if first parameter is the raw handle of wrapper types with accessors,
+	// convert to methods on the wrapper types.
+	if recvTypes := gen.accessorRecvTypes(firstParamType); len(recvTypes) > 0 {
+		for _, recvType := range recvTypes {
+			if err := gen.convertFunc(decl, 0, recvType); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	// if first parameter has valid type (e.g. *Window) convert to method.
+	recvType, ok := gen.methodRecvType(firstParamType)
+	if !ok {
+		// if first parameter is an interface satisfied by configured concrete
+		// types, convert to methods on the concrete types.
+		if gen.opts.InterfaceRecv {
+			recvTypes, err := gen.interfaceRecvTypes(decl.Name.String(), firstParamType)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			for _, recvType := range recvTypes {
+				if err := gen.convertFunc(decl, 0, recvType); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			if len(recvTypes) > 0 {
+				return nil
+			}
+		}
+		reason := fmt.Sprintf("first parameter type %s is not a valid method type", types.TypeString(firstParamType, types.RelativeTo(gen.pkg.Types)))
+		if types.IsInterface(firstParamType) {
+			reason += "; methods may not be declared on interface types (see -interface-recv)"
+		}
+		gen.skipFunc(decl, reason)
+		return nil // skip non-supported receiver type.
+	}
+	if err := gen.convertFunc(decl, 0, recvType); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// skipResults reports whether to skip the given function as its results do not
+// match the result filters (-min-results, -max-results and -must-return-error),
+// and returns the reason for skipping it.
+func (gen *Gen) skipResults(decl *ast.FuncDecl) (string, bool) {
+	n := 0
+	if results := decl.Type.Results; results != nil {
+		n = len(flatResults(results))
+	}
+	switch {
+	case n < gen.opts.MinResults:
+		return fmt.Sprintf("function has %d results; fewer than minimum of %d (-min-results)", n, gen.opts.MinResults), true
+	case gen.opts.MaxResults != nil && n > *gen.opts.MaxResults:
+		return fmt.Sprintf("function has %d results; more than maximum of %d (-max-results)", n, *gen.opts.MaxResults), true
+	case gen.opts.MustReturnError && !gen.returnsError(decl.Type.Results):
+		return "last result of function is not of type error (-must-return-error)", true
+	}
+	return "", false
+}
+
+// parseAnyPosition parses the given function declaration, converting it to a
+// method on the first (or last, as specified by -recv-priority) parameter
+// with a valid method type. The receiver parameter may be overridden per
+// function in the user-provided config.
+func (gen *Gen) parseAnyPosition(decl *ast.FuncDecl) error {
+	params := flatParams(decl.Type.Params)
+	if len(params) == 0 {
+		gen.skipFunc(decl, "function has no named parameters")
+		return nil
+	}
+	for _, field := range decl.Type.Params.List {
+		if len(field.Names) == 0 {
+			gen.skipFunc(decl, "function has unnamed parameters")
+			return nil
+		}
+	}
+	var (
+		candidates []int
+		recvTypes  = make(map[int]types.Type)
+	)
+	for i, param := range params {
+		if recvType, ok := gen.methodRecvType(gen.pkg.TypesInfo.TypeOf(param.field.Type)); ok {
+			candidates = append(candidates, i)
+			recvTypes[i] = recvType
+		}
+	}
+	if len(candidates) == 0 {
+		gen.skipFunc(decl, "no parameter has a valid method type")
+		return nil
+	}
+	recvIndex := candidates[0]
+	if gen.opts.RecvPriority == "last" {
+		recvIndex = candidates[len(candidates)-1]
+	}
+	if recvParamName, ok := gen.recvParamOverride(decl.Name.String()); ok {
+		i := slices.IndexFunc(params, func(param param) bool {
+			return param.name.Name == recvParamName
+		})
+		if i == -1 {
+			return errors.Errorf("receiver parameter %q of function %q not found", recvParamName, decl.Name)
+		}
+		if _, ok := recvTypes[i]; !ok {
+			return errors.Errorf("receiver parameter %q of function %q does not have a valid method type", recvParamName, decl.Name)
+		}
+		recvIndex = i
+	}
+	if err := gen.convertFunc(decl, recvIndex, recvTypes[recvIndex]); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// recvParamOverride returns the receiver parameter name of the given function,
+// as specified by the user-provided config.
+func (gen *Gen) recvParamOverride(funcName string) (string, bool) {
+	if config := gen.opts.Config; config != nil {
+		recvParamName, ok := config.RecvParams[funcName]
+		return recvParamName, ok
+	}
+	return "", false
+}
+
+// skipFunc records that no method is generated for the given function, for
+// the specified reason.
+func (gen *Gen) skipFunc(decl *ast.FuncDecl, reason string) {
+	clog.Debugf("skipping function %q; %s", decl.Name, reason)
+	atomic.AddInt64(&gen.Stats.FuncsSkipped, 1)
+	skipped := &SkippedFunc{
+		Name:   decl.Name.String(),
+		Reason: reason,
+	}
+	gen.skipped = append(gen.skipped, skipped)
+	gen.skipReasons[skipped.Name] = reason
+	parsed := ParsedFunc{
+		Decl:       decl,
+		Skipped:    true,
+		SkipReason: reason,
+	}
+	gen.ParsedFuncs = append(gen.ParsedFuncs, parsed)
+}
+
+// ExplainSkip returns a human-readable explanation of why no method was
+// generated for the given function (e.g. "function Foo was skipped because its
+// first parameter type *Foo is not a valid method type"). ExplainSkip is
+// intended for debugging after methods have been generated.
+func (gen *Gen) ExplainSkip(funcName string) string {
+	if reason, ok := gen.skipReasons[funcName]; ok {
+		return fmt.Sprintf("function %s was skipped because %s", funcName, reason)
+	}
+	for _, method := range gen.methods {
+		if method.Func != nil && method.Func.Name() == funcName {
+			recvType := types.TypeString(method.RecvType, types.RelativeTo(gen.pkg.Types))
+			return fmt.Sprintf("function %s was not skipped; method %s generated on %s", funcName, method.Decl.Name, recvType)
+		}
+	}
+	if gen.pkg.Types.Scope().Lookup(funcName) == nil {
+		return fmt.Sprintf("%s is not declared at package scope of %q", funcName, gen.pkg.PkgPath)
+	}
+	return fmt.Sprintf("%s was not parsed for conversion or its method was dropped (e.g. not a function, or not part of the -implements interface)", funcName)
+}
+
+// skipMatchingFunc records that no method is generated for the given function
+// with a valid receiver type, for the specified reason. In -error-on-skip
+// mode, an error is returned instead.
+func (gen *Gen) skipMatchingFunc(decl *ast.FuncDecl, reason string) error {
+	if gen.opts.ErrorOnSkip {
+		return errors.WithStack(&ErrSkippedFunc{FuncName: decl.Name.String(), Reason: reason})
+	}
+	gen.skipFunc(decl, reason)
+	return nil
+}
+
+// methodRecvType returns the method receiver type of functions with the given
+// first parameter type, and reports whether methods are generated for the
+// parameter type.
+//
+// The pointer-ness of the receiver type is adapted to a pinned receiver kind
+// of the user-provided config, or with -adapt-recv, to the valid method type
+// (e.g. parameter type Window is adapted to receiver type *Window if *Window is
+// a valid method type).
+func (gen *Gen) methodRecvType(paramType types.Type) (types.Type, bool) {
+	// match aliases (e.g. `type WinPtr = *Window`) by their aliased type.
+	paramType = types.Unalias(paramType)
+	// counterpart of parameter type with opposite pointer-ness.
+	var counterpart types.Type
+	if ptr, ok := paramType.(*types.Pointer); ok {
+		counterpart = ptr.Elem()
+	} else if _, ok := types.Unalias(paramType).(*types.Named); ok {
+		counterpart = types.NewPointer(paramType)
+	}
+	valid := gen.isValidMethodType(paramType)
+	validCounterpart := counterpart != nil && gen.isValidMethodType(counterpart)
+	if !valid && !validCounterpart {
+		return nil, false
+	}
+	if config := gen.opts.Config; config != nil && counterpart != nil {
+		base := paramType
+		if ptr, ok := paramType.(*types.Pointer); ok {
+			base = ptr.Elem()
+		}
+		switch config.Receivers[base.String()] {
+		case "pointer":
+			return types.NewPointer(base), true
+		case "value":
+			return base, true
+		}
+	}
+	if valid {
+		return paramType, true
+	}
+	if gen.opts.AdaptRecv {
+		return counterpart, true
+	}
+	return nil, false
+}
+
+// recvArg returns the forwarded argument of the given receiver expression (e.g.
+// the receiver name), adapting the pointer-ness of the receiver type to the
+// parameter type (e.g. `*recv` for a pointer receiver forwarded to a value
+// parameter). Aliases are compared by their aliased types (e.g. `type
+// WindowPtr = *Window`).
+func recvArg(recvName ast.Expr, recvType, paramType types.Type) ast.Expr {
+	recvType, paramType = types.Unalias(recvType), types.Unalias(paramType)
+	if ptr, ok := recvType.(*types.Pointer); ok && types.Identical(ptr.Elem(), paramType) {
+		return &ast.StarExpr{X: recvName}
+	}
+	if ptr, ok := paramType.(*types.Pointer); ok && types.Identical(ptr.Elem(), recvType) {
+		return &ast.UnaryExpr{Op: token.AND, X: recvName}
+	}
+	return recvName
+}
+
+// convertFunc records the given function as parsed for conversion to a method
+// on the specified receiver type, and generates the method.
+func (gen *Gen) convertFunc(decl *ast.FuncDecl, recvIndex int, recvType types.Type) error {
+	if reason, ok := gen.foreignRecvType(recvType); ok {
+		return gen.skipMatchingFunc(decl, reason)
+	}
+	if gen.opts.GenWrap {
+		if reason, ok := gen.unwrappableFunc(decl, recvType); ok {
+			return gen.skipMatchingFunc(decl, reason)
+		}
+	}
+	if decl.Type.TypeParams != nil {
+		if goVersion, ok := gen.supportsGenericMethods(decl.Pos()); !ok {
+			reason := fmt.Sprintf("generic methods require %s or later (file uses %s)", minGenericMethodsVersion, goVersion)
+			return gen.skipMatchingFunc(decl, reason)
+		}
+	}
+	recvType = gen.funcRecvType(decl.Name.String(), recvType)
+	methodName, nameSource, err := gen.resolveMethodName(decl, recvType)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	parsed := ParsedFunc{
+		Decl:            decl,
+		ReceiverType:    recvType,
+		MethodName:      methodName,
+		NameSource:      nameSource,
+		InclusionSource: gen.inclusionSource(decl, recvType),
+	}
+	gen.ParsedFuncs = append(gen.ParsedFuncs, parsed)
+	if err := gen.genMethod(decl, recvIndex, recvType, methodName); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// funcRecvType returns the receiver type of the given function with the
+// pointer-ness pinned as specified by the func_receivers section of the
+// user-provided config. Exact function names take precedence over patterns,
+// and longer patterns over shorter ones.
+func (gen *Gen) funcRecvType(funcName string, recvType types.Type) types.Type {
+	config := gen.opts.Config
+	if config == nil || len(config.FuncReceivers) == 0 {
+		return recvType
+	}
+	kind, ok := config.FuncReceivers[funcName]
+	if !ok {
+		var best string
+		for pattern, k := range config.FuncReceivers {
+			if match, err := path.Match(pattern, funcName); err != nil || !match {
+				continue
+			}
+			if len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+				best, kind = pattern, k
+			}
+		}
+	}
+	base := recvType
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		base = ptr.Elem()
+	}
+	switch kind {
+	case "pointer":
+		return types.NewPointer(base)
+	case "value":
+		return base
+	default:
+		return recvType
+	}
+}
+
+// isStringerFunc reports whether the given function is mapped to a String
+// method by the user-provided config.
+func (gen *Gen) isStringerFunc(funcName string) bool {
+	if config := gen.opts.Config; config != nil {
+		return slices.Contains(config.Stringers, funcName)
+	}
+	return false
+}
+
+// checkStringer checks that the given function fits the String method of
+// fmt.Stringer; i.e. takes only the receiver and returns exactly one string.
+func (gen *Gen) checkStringer(decl *ast.FuncDecl) error {
+	sig, ok := gen.pkg.TypesInfo.TypeOf(decl.Name).(*types.Signature)
+	if !ok {
+		return errors.Errorf("unable to locate signature of function %q", decl.Name)
+	}
+	if sig.Params().Len() != 1 {
+		return errors.Errorf("expected only receiver parameter; got %d parameters", sig.Params().Len())
+	}
+	if sig.Results().Len() != 1 || !types.Identical(sig.Results().At(0).Type(), types.Typ[types.String]) {
+		return errors.Errorf("expected exactly one string result; got %v", sig.Results())
+	}
+	return nil
+}
+
+// isCopyFunc reports whether the given function copies values of the given
+// receiver type (e.g. `func CopySurface(s *Surface) (*Surface, error)`); i.e.
+// a "Copy" prefixed function with a single parameter of the receiver type,
+// returning the receiver type and an optional error.
+func (gen *Gen) isCopyFunc(decl *ast.FuncDecl, recvType types.Type) bool {
+	if !strings.HasPrefix(decl.Name.String(), "Copy") {
+		return false
+	}
+	sig, ok := gen.pkg.TypesInfo.TypeOf(decl.Name).(*types.Signature)
+	if !ok || sig.Params().Len() != 1 {
+		return false
+	}
+	results := sig.Results()
+	switch results.Len() {
+	case 1:
+		// (*Surface)
+	case 2:
+		// (*Surface, error)
+		if !isError(results.At(1).Type()) {
+			return false
+		}
+	default:
+		return false
+	}
+	return types.Identical(results.At(0).Type(), recvType)
+}
+
+// isFluentFunc reports whether the given function returns its receiver for
+// chaining (e.g. `func ResetWindow(w *Window) *Window`); i.e. a function with a
+// single result of the type of the given receiver parameter type.
+func (gen *Gen) isFluentFunc(decl *ast.FuncDecl, paramType types.Type) bool {
+	sig, ok := gen.pkg.TypesInfo.TypeOf(decl.Name).(*types.Signature)
+	if !ok || sig.Results().Len() != 1 {
+		return false
+	}
+	return types.Identical(sig.Results().At(0).Type(), paramType)
+}
+
+// methodName returns the method name of the given function, after name
+// sanitization, renames and rename rules.
+func (gen *Gen) methodName(funcName string) string {
+	methodName, renamed := gen.renamedMethodName(funcName)
+	if renamed {
+		atomic.AddInt64(&gen.Stats.RenamesApplied, 1)
+	}
+	if newMethodName, ok := gen.applyRenameRules(methodName); ok {
+		methodName = newMethodName
+		atomic.AddInt64(&gen.Stats.RenamesApplied, 1)
+	}
+	if gen.opts.CheckNames {
+		if err := checkMethodName(methodName, funcName); err != nil {
+			clog.Warnf("invalid method name of function %q: %v; falling back to function name", funcName, err)
+			methodName = funcName
+		}
+	}
+	return methodName
+}
+
+// renamedMethodName returns the method name of the given function after name
+// sanitization and renames, before rename rules, and reports whether a rename
+// was applied.
+func (gen *Gen) renamedMethodName(funcName string) (string, bool) {
+	methodName := funcName
+	if gen.opts.SanitizeNames {
+		methodName = gen.camelCase(funcName)
+	}
+	if newMethodName, ok := gen.renameMethod(funcName); ok {
+		return newMethodName, true
+	}
+	return methodName, false
+}
+
+// genMethod generates a method with the given name on the given receiver type,
+// forwarding to the given function.
+func (gen *Gen) genMethod(funcDecl *ast.FuncDecl, recvIndex int, recvType types.Type, methodName string) error {
+	clog.Infoln("generating method:", funcDecl.Name)
+	params := flatParams(funcDecl.Type.Params)
+	recvParam := params[recvIndex]
+	recvName := recvParam.name
+	recvTypeExpr := recvParam.field.Type
+	paramType := gen.pkg.TypesInfo.TypeOf(recvTypeExpr)
+	if !types.Identical(recvType, paramType) {
+		recvTypeExpr = gen.typeExpr(recvType)
+	} else if _, ok := paramType.(*types.Alias); ok && !gen.opts.PreserveAliases {
+		// normalize alias receivers (e.g. `func (w WinPtr) Foo()`) to the
+		// aliased type (e.g. `func (w *Window) Foo()`).
+		recvTypeExpr = gen.typeExpr(recvType)
+	}
+	// package name of the wrapped package (-gen-wrap).
+	var wrapPkg string
+	if gen.opts.GenWrap {
+		wrapPkg = gen.wrapImport()
+		recvTypeExpr = &ast.StarExpr{X: ast.NewIdent(typeName(recvType))}
+	}
+	funcName := funcDecl.Name.String()
+	if err := gen.checkRecvType(funcName, recvType); err != nil {
+		return errors.WithStack(err)
+	}
+	// methods on T and *T share a namespace.
+	baseType := recvType
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		baseType = ptr.Elem()
+	}
+	methodKey := baseType.String() + "." + methodName
+	if prevFuncName, ok := gen.methodFuncs[methodKey]; ok {
+		return errors.WithStack(&ErrDuplicateMethod{
+			RecvType:     recvType,
+			MethodName:   methodName,
+			FuncName:     funcName,
+			PrevFuncName: prevFuncName,
+		})
+	}
+	gen.methodFuncs[methodKey] = funcName
+	if !gen.opts.NoMethodSetCheck && !gen.opts.GenWrap {
+		gen.checkShadowed(baseType, methodName)
+	}
+	if !gen.opts.AllowBuiltinShadow && types.Universe.Lookup(methodName) != nil {
+		clog.Warnf("method name %q of function %q is a predeclared identifier of Go (e.g. a built-in function)", methodName, funcName)
+	}
+	doc := &ast.CommentGroup{}
+	noescape := false
+	if funcDecl.Doc != nil {
+		for _, comment := range funcDecl.Doc.List {
+			if strings.HasPrefix(comment.Text, directivePrefix) {
+				continue // skip genmethods directives.
+			}
+			if isNoescape(comment) {
+				// misplaced on methods with body.
+				noescape = true
+				continue
+			}
+			newComment := &ast.Comment{
+				Slash: 0,
+				Text:  comment.Text,
+			}
+			doc.List = append(doc.List, newComment)
+		}
+	}
+	if gen.opts.DocNormalize {
+		doc = normalizeDoc(doc, funcName, methodName)
+	}
+	if gen.opts.MergeDoc {
+		doc = gen.mergeDoc(doc, funcName)
+	}
+	fluent := gen.opts.AutoFluent && gen.isFluentFunc(funcDecl, paramType)
+	if fluent {
+		if len(doc.List) > 0 {
+			doc.List = append(doc.List, &ast.Comment{Text: "//"})
+		}
+		doc.List = append(doc.List, &ast.Comment{Text: "// " + methodName + " returns the receiver, allowing for chained method calls."})
+		atomic.AddInt64(&gen.Stats.FluentMethods, 1)
+	}
+	if gen.opts.FormatWidth > 0 {
+		doc = reflowDoc(doc, gen.opts.FormatWidth)
+	}
+	if noescape {
+		clog.Warnf("//go:noescape pragma of function %q cannot be propagated to method %q", funcName, methodName)
+		doc.List = append(doc.List, &ast.Comment{Text: noescapeWarning})
+	}
+	pairs, err := gen.slicePairs(funcDecl, params, recvIndex)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	srcParams := funcDecl.Type.Params
+	if renames := gen.paramRenames(funcName); len(renames) > 0 {
+		if srcParams, err = renameParams(funcName, srcParams, recvIndex, renames); err != nil {
+			return errors.WithStack(err)
+		}
+		params = flatParams(srcParams)
+	}
+	if name, ok := gen.recvNameOverride(recvType); ok && name != recvName.Name {
+		newParams, ok := renameRecv(srcParams, recvIndex, name)
+		if ok {
+			srcParams = newParams
+			params = flatParams(srcParams)
+			recvName = params[recvIndex].name
+		} else {
+			clog.Warnf("configured receiver name %q of function %q collides with another parameter; using %q", name, funcName, recvName)
+		}
+	}
+	methodParams := removeParam(srcParams, recvIndex) // skip receiver parameter
+	if len(pairs) > 0 {
+		methodParams = collapseParams(params, recvIndex, pairs)
+	}
+	// name of injected context parameter.
+	var ctxName string
+	if gen.opts.InjectContext && !gen.hasContextParam(methodParams) {
+		methodParams = injectContext(methodParams, params)
+		ctxName = methodParams.List[0].Names[0].Name
+		gen.imports["context"] = true
+	}
+	// qualified types of parameters and results (e.g. `io.Reader`).
+	gen.addTypeImports(funcDecl.Type)
+	methodDecl := &ast.FuncDecl{
+		Doc: doc,
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				&ast.Field{
+					Names: []*ast.Ident{
+						// source position of the receiver parameter, so that
+						// methods with synthesized receiver types (e.g.
+						// adapted or wrapper types) print without blank lines.
+						&ast.Ident{NamePos: recvName.NamePos, Name: recvName.Name},
+					},
+					Type: recvTypeExpr,
+				},
+			},
+		},
+		Name: ast.NewIdent(methodName),
+		Type: &ast.FuncType{
+			// type parameters of generic functions are carried over to the
+			// method (e.g. `func (r *Renderer) Render[T Drawable](item T)`).
+			TypeParams: funcDecl.Type.TypeParams,
+			Params:     methodParams,
+			Results:    funcDecl.Type.Results,
+		},
+	}
+	if gen.opts.GenWrap {
+		methodDecl.Type.Params = gen.qualifyFields(methodDecl.Type.Params, wrapPkg)
+		methodDecl.Type.Results = gen.qualifyFields(methodDecl.Type.Results, wrapPkg)
+	}
+	if fluent && types.Identical(recvType, paramType) {
+		// spell the result as the receiver type (e.g. `*Window` of `WinPtr`
+		// results), keeping result names.
+		result := funcDecl.Type.Results.List[0]
+		methodDecl.Type.Results = &ast.FieldList{
+			List: []*ast.Field{{Names: result.Names, Type: recvTypeExpr}},
+		}
+	}
+	var args []ast.Expr
+	for i, param := range params {
+		var arg ast.Expr = param.name
+		if i == recvIndex {
+			if acc, ok := gen.accessorOf(recvType, paramType); ok {
+				// forward raw handle of wrapper receiver.
+				if arg, err = forwardFunc(acc.template, param.name.Name, funcName, methodName); err != nil {
+					return errors.WithStack(err)
+				}
+			} else if gen.opts.GenWrap {
+				// forward wrapped value of wrapper type.
+				field := &ast.SelectorExpr{X: param.name, Sel: ast.NewIdent(gen.unwrapField())}
+				arg = recvArg(field, gen.wrappedType(recvType), gen.pkg.TypesInfo.TypeOf(param.field.Type))
+			} else {
+				arg = recvArg(param.name, recvType, gen.pkg.TypesInfo.TypeOf(param.field.Type))
+			}
+		}
+		args = append(args, arg)
+	}
+	sliceStmts := collapseArgs(args, params, pairs)
+	callExpr := &ast.CallExpr{
+		Fun:  instantiate(funcDecl.Name, funcDecl.Type.TypeParams),
+		Args: args,
+	}
+	if gen.opts.GenWrap {
+		callExpr.Fun = &ast.SelectorExpr{X: ast.NewIdent(wrapPkg), Sel: funcDecl.Name}
+	}
+	if template, ok := gen.forwardTemplate(recvType); ok {
+		fun, err := forwardFunc(template, recvName.String(), funcName, methodName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		callExpr.Fun = fun
+		// receiver is part of forwarding target.
+		callExpr.Args = slices.Delete(slices.Clone(args), recvIndex, recvIndex+1)
+	}
+	hasReturn := funcDecl.Type.Results != nil && len(funcDecl.Type.Results.List) > 0
+	var stmt ast.Stmt
+	if hasReturn {
+		stmt = &ast.ReturnStmt{
+			Results: []ast.Expr{callExpr},
+		}
+	} else {
+		stmt = &ast.ExprStmt{
+			X: callExpr,
+		}
+	}
+	var stmts []ast.Stmt
+	if gen.opts.StubNilChecks && isPointer(recvType) {
+		stmts = append(stmts, gen.nilGuard(recvName.String(), funcDecl.Type.Results))
+	}
+	if gen.opts.NilGuard && isPointer(recvType) {
+		stmts = append(stmts, panicNilGuard(recvName.String(), gen.nilGuardMessage(recvType, methodName)))
+	}
+	if gen.opts.Recover && gen.returnsError(funcDecl.Type.Results) {
+		results, errName := namedResults(funcDecl.Type.Results, params)
+		methodDecl.Type.Results = results
+		gen.imports["fmt"] = true
+		stmts = append(stmts, recoverStmt(errName, funcName))
+	}
+	stmts = append(stmts, sliceStmts...)
+	if config := gen.opts.Config; config != nil && len(ctxName) > 0 && len(config.Trace) > 0 {
+		traceStmt, err := gen.traceStmt(config, ctxName, recvName.String(), funcName, methodName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		stmts = append(stmts, traceStmt)
+	}
+	metricsStmts, err := gen.metricsStmts(recvName.String(), funcName, methodName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	stmts = append(stmts, metricsStmts...)
+	var decls []ast.Decl
+	lazy := false
+	if gen.isLazyFunc(funcName) {
+		if err := checkLazy(funcDecl); err != nil {
+			clog.Warnf("unable to generate lazy method %q of function %q; generating a regular method: %v", methodName, funcName, err)
+		} else {
+			lazy = true
+		}
+	}
+	var metrics *promMetrics
+	if gen.opts.GenMetrics {
+		// errors of lazy methods are only returned by the first call.
+		m, metricDecl, err := gen.promMetricDecls(recvType, methodName, gen.returnsError(funcDecl.Type.Results) && !lazy)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		metrics = m
+		decls = append(decls, metricDecl)
+		stmts = append(stmts, metrics.callStmts()...)
+	}
+	countErrors := metrics != nil && len(metrics.errors) > 0
+	wrapErrors := gen.opts.WrapErrors && gen.returnsError(funcDecl.Type.Results)
+	if lazy {
+		lazyStmts, decl, err := gen.lazyStmts(callExpr, recvType, methodName, methodDecl.Type.Results)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		decls = append(decls, decl)
+		if len(doc.List) > 0 {
+			doc.List = append(doc.List, &ast.Comment{Text: "//"})
+		}
+		doc.List = append(doc.List, &ast.Comment{Text: "// The result of the first call of " + funcName + " is cached for all receivers."})
+		stmts = append(stmts, lazyStmts[:len(lazyStmts)-1]...)
+		stmt = lazyStmts[len(lazyStmts)-1]
+	} else if hasReturn && (gen.opts.ExpandResults || wrapErrors || countErrors) {
+		assignStmt, returnStmt := gen.expandResults(callExpr, methodDecl.Type.Results, params)
+		stmts = append(stmts, assignStmt)
+		errName := returnStmt.Results[len(returnStmt.Results)-1].(*ast.Ident).Name
+		if countErrors {
+			stmts = append(stmts, metrics.errorStmt(errName))
+		}
+		if wrapErrors {
+			gen.imports["fmt"] = true
+			stmts = append(stmts, wrapErrorStmt(errName, funcName))
+		}
+		stmt = returnStmt
+	}
+	if gen.opts.MethodBody != nil {
+		if body := gen.opts.MethodBody(funcDecl, stmt); body != nil {
+			stmt = body
+		}
+	}
+	stmts = append(stmts, stmt)
+	methodDecl.Body = &ast.BlockStmt{
+		List: stmts,
+	}
+	if gen.opts.AfterMethod != nil {
+		methodDecl = gen.opts.AfterMethod(methodDecl)
+		if methodDecl == nil {
+			// method dropped by hook; release the method name and record the
+			// function as skipped.
+			delete(gen.methodFuncs, methodKey)
+			gen.ParsedFuncs = slices.DeleteFunc(gen.ParsedFuncs, func(parsed ParsedFunc) bool {
+				return parsed.Decl == funcDecl && parsed.ReceiverType == recvType && !parsed.Skipped
+			})
+			gen.skipFunc(funcDecl, "generated method dropped by AfterMethod hook")
+			return nil
+		}
+	}
+	method := &Method{
+		Decl:      methodDecl,
+		RecvType:  recvType,
+		Func:      gen.pkg.TypesInfo.Defs[funcDecl.Name],
+		RecvIndex: recvIndex,
+		Decls:     decls,
+		metrics:   metrics,
+	}
+	gen.methods = append(gen.methods, method)
+	atomic.AddInt64(&gen.Stats.MethodsGenerated, 1)
+	return nil
+}
+
+// param is a named function parameter.
+type param struct {
+	// parameter name.
+	name *ast.Ident
+	// parameter field (possibly shared by multiple parameters of `a, b T`
+	// parameter lists).
+	field *ast.Field
+}
+
+// flatParams returns the named parameters of the given parameter list, with
+// `a, b T` parameter lists flattened.
+func flatParams(params *ast.FieldList) []param {
+	var ps []param
+	if params == nil {
+		return nil
+	}
+	for _, field := range params.List {
+		for _, name := range field.Names {
+			ps = append(ps, param{name: name, field: field})
+		}
+	}
+	return ps
+}
+
+// removeParam returns a copy of the given parameter list without the parameter
+// at the specified flat parameter index.
+func removeParam(params *ast.FieldList, index int) *ast.FieldList {
+	newParams := &ast.FieldList{}
+	i := 0
+	for _, field := range params.List {
+		var names []*ast.Ident
+		for _, name := range field.Names {
+			if i != index {
+				names = append(names, name)
+			}
+			i++
+		}
+		if len(names) == 0 {
+			continue
+		}
+		if len(names) == len(field.Names) {
+			newParams.List = append(newParams.List, field)
+			continue
+		}
+		newField := &ast.Field{
+			Names: names,
+			Type:  field.Type,
+		}
+		newParams.List = append(newParams.List, newField)
+	}
+	return newParams
+}
+
+// renameParams returns a copy of the given parameter list with parameters
+// renamed as specified by the map from original to new parameter name. The
+// receiver parameter at the specified flat parameter index may not be renamed,
+// and new names may not collide with other parameter names.
+func renameParams(funcName string, params *ast.FieldList, recvIndex int, renames map[string]string) (*ast.FieldList, error) {
+	oldParams := flatParams(params)
+	names := make(map[string]bool)
+	for from, to := range renames {
+		i := slices.IndexFunc(oldParams, func(param param) bool {
+			return param.name.Name == from
+		})
+		if i == -1 {
+			return nil, errors.Errorf("renamed parameter %q of function %q not found", from, funcName)
+		}
+		if i == recvIndex {
+			return nil, errors.Errorf("unable to rename receiver parameter %q of function %q", from, funcName)
+		}
+		if !token.IsIdentifier(to) {
+			return nil, errors.Errorf("invalid new name %q of parameter %q of function %q", to, from, funcName)
+		}
+	}
+	newParams := &ast.FieldList{}
+	for _, field := range params.List {
+		newField := &ast.Field{
+			Type: field.Type,
+		}
+		for _, name := range field.Names {
+			newName := name.Name
+			if to, ok := renames[name.Name]; ok {
+				newName = to
+			}
+			if newName != "_" && names[newName] {
+				if newName == oldParams[recvIndex].name.Name {
+					return nil, errors.Errorf("renamed parameter %q of function %q collides with the receiver name", newName, funcName)
+				}
+				return nil, errors.Errorf("renamed parameter %q of function %q collides with another parameter", newName, funcName)
+			}
+			names[newName] = true
+			newField.Names = append(newField.Names, ast.NewIdent(newName))
+		}
+		newParams.List = append(newParams.List, newField)
+	}
+	return newParams, nil
+}
+
+// typeExpr returns a type expression of the given type, qualified relative to
+// the analyzed package.
+func (gen *Gen) typeExpr(typ types.Type) ast.Expr {
+	s := types.TypeString(typ, types.RelativeTo(gen.pkg.Types))
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		panic(fmt.Errorf("unable to parse type expression %q: %v", s, err))
+	}
+	return stripPos(expr)
+}
+
+// checkRecvType reports an error if methods cannot be declared on the given
+// receiver type of the specified function. The base type of the receiver type
+// is a defined type of the package of the source functions (see
+// foreignRecvType).
+func (gen *Gen) checkRecvType(funcName string, recvType types.Type) error {
+	base := recvType
+	if ptr, ok := base.(*types.Pointer); ok {
+		base = ptr.Elem()
+	}
+	switch base.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return &ErrInvalidReceiverType{FuncName: funcName, RecvType: recvType, Reason: "receiver base type must not be a pointer or interface type"}
+	}
+	return nil
+}
+
+// foreignRecvType reports whether methods cannot be declared on the given
+// receiver type as its base type is not a defined type of the package of the
+// source functions, and returns the reason for skipping the function.
+func (gen *Gen) foreignRecvType(recvType types.Type) (string, bool) {
+	if gen.isLocalType(recvType) {
+		return "", false
+	}
+	base := recvType
+	if ptr, ok := base.(*types.Pointer); ok {
+		base = ptr.Elem()
+	}
+	named, ok := types.Unalias(base).(*types.Named)
+	if !ok {
+		return fmt.Sprintf("receiver base type %v is not a defined type", base), true
+	}
+	// methods may only be declared in the home package of the receiver type,
+	// and forwarding from there to this package would create an import cycle,
+	// since this package imports the home package.
+	reason := fmt.Sprintf("receiver type %v is not defined in package %q", recvType, gen.pkg.PkgPath)
+	if home := named.Obj().Pkg(); home != nil {
+		reason += fmt.Sprintf("; methods forwarding to this package cannot be generated into the home package %q of the receiver type, as it would create an import cycle", home.Path())
+	}
+	return reason, true
+}
+
+// checkMethodName reports an error if the given method name is not a valid Go
+// identifier (i.e. empty, starts with a digit or is a keyword), or if the
+// method name is unexported while its source function is exported.
+func checkMethodName(methodName, funcName string) error {
+	if !token.IsIdentifier(methodName) {
+		if token.IsKeyword(methodName) {
+			return errors.Errorf("method name %q is a Go keyword", methodName)
+		}
+		return errors.Errorf("method name %q is not a valid Go identifier", methodName)
+	}
+	if token.IsExported(funcName) && !token.IsExported(methodName) {
+		return errors.Errorf("method name %q of exported function is unexported", methodName)
+	}
+	return nil
+}
+
+// nilGuard returns an if-statement which returns early if the receiver with
+// the given name is nil. Zero values are returned for all results, except
+// for a trailing error result which is set to ErrNilReceiver.
+func (gen *Gen) nilGuard(recvName string, results *ast.FieldList) *ast.IfStmt {
+	var zeros []ast.Expr
+	var lastType types.Type
+	if results != nil {
+		for _, field := range results.List {
+			typ := gen.pkg.TypesInfo.TypeOf(field.Type)
+			n := max(1, len(field.Names))
+			for i := 0; i < n; i++ {
+				zeros = append(zeros, zeroValue(typ, field.Type))
+			}
+			lastType = typ
+		}
+	}
+	if lastType != nil && isError(lastType) {
+		zeros[len(zeros)-1] = ast.NewIdent("ErrNilReceiver")
+		gen.useErrNilReceiver = true
+	}
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X:  ast.NewIdent(recvName),
+			Op: token.EQL,
+			Y:  ast.NewIdent("nil"),
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: zeros,
+				},
+			},
+		},
+	}
+}
+
+// defaultNilGuardMessage is the default panic message template of nil-receiver
+// guards (-nil-guard).
+const defaultNilGuardMessage = "nil {type}"
+
+// nilGuardMessage returns the panic message of the nil-receiver guard of the
+// given method on the given receiver type, as specified by the panic message
+// template (-nil-guard-message).
+//
+// Placeholders:
+//
+//	{type}    base receiver type name (e.g. "Renderer")
+//	{method}  generated method name (e.g. "Clear")
+func (gen *Gen) nilGuardMessage(recvType types.Type, methodName string) string {
+	template := gen.opts.NilGuardMessage
+	if len(template) == 0 {
+		template = defaultNilGuardMessage
+	}
+	r := strings.NewReplacer(
+		"{type}", typeName(recvType),
+		"{method}", methodName,
+	)
+	return r.Replace(template)
+}
+
+// panicNilGuard returns an if-statement which panics with the given message if
+// the receiver with the given name is nil.
+//
+// Example:
+//
+//	if r == nil {
+//		panic("nil Renderer")
+//	}
+func panicNilGuard(recvName, msg string) *ast.IfStmt {
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X:  ast.NewIdent(recvName),
+			Op: token.EQL,
+			Y:  ast.NewIdent("nil"),
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun:  ast.NewIdent("panic"),
+						Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(msg)}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// zeroValue returns an expression evaluating to the zero value of the given
+// type, where expr is the type expression of typ.
+func zeroValue(typ types.Type, expr ast.Expr) ast.Expr {
+	if _, ok := typ.(*types.TypeParam); ok {
+		return newZero(expr)
+	}
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsBoolean != 0:
+			return ast.NewIdent("false")
+		case t.Info()&types.IsNumeric != 0:
+			return &ast.BasicLit{Kind: token.INT, Value: "0"}
+		case t.Info()&types.IsString != 0:
+			return &ast.BasicLit{Kind: token.STRING, Value: `""`}
+		case t.Kind() == types.UnsafePointer:
+			return ast.NewIdent("nil")
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return ast.NewIdent("nil")
+	case *types.Struct, *types.Array:
+		return &ast.CompositeLit{Type: expr}
+	}
+	return newZero(expr)
+}
+
+// newZero returns the expression `*new(T)` for the given type expression.
+func newZero(expr ast.Expr) ast.Expr {
+	return &ast.StarExpr{
+		X: &ast.CallExpr{
+			Fun:  ast.NewIdent("new"),
+			Args: []ast.Expr{expr},
+		},
+	}
+}
+
+// isPointer reports whether the given type is a pointer type.
+func isPointer(typ types.Type) bool {
+	_, ok := typ.Underlying().(*types.Pointer)
+	return ok
 }
 
-func genMethods(pkgPath, output string) error {
-	pkg, err := loadPkg(pkgPath)
+// isError reports whether the given type is the predeclared error type.
+func isError(typ types.Type) bool {
+	return types.Identical(typ, types.Universe.Lookup("error").Type())
+}
+
+const pre = `// Code generated by "genmethods"; DO NOT EDIT.
+`
+
+// stdoutOutput is the output path denoting standard output (-o -), for use in
+// pipelines where an empty output path is awkward to pass.
+const stdoutOutput = "-"
+
+func (gen *Gen) printMethods(output string) error {
+	if isMultiFile(gen.opts) {
+		if err := gen.printSplitMethods(output); err != nil {
+			return errors.WithStack(err)
+		}
+		return gen.printExtras(output)
+	}
+	data, err := gen.outputSource()
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	gen := &Gen{
-		pkg: pkg,
+	if len(output) > 0 {
+		if err := gen.checkOverwrite(output); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := gen.writeFile(output, data); err != nil {
+			return errors.WithStack(err)
+		}
+	} else {
+		fmt.Print(string(data))
 	}
-	if err := gen.parsePkg(); err != nil {
+	return gen.printExtras(output)
+}
+
+// writeFile writes the given generated Go source to the given path, with the
+// file permission bits of the generation options (-file-perm). As os.WriteFile
+// only applies permission bits to new files, the permission bits of existing
+// files are updated explicitly when configured.
+func (gen *Gen) writeFile(path string, data []byte) error {
+	clog.Debugf("writing to %q", path)
+	if gen.opts.FilePerm == 0 {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return errors.WithStack(err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, data, gen.opts.FilePerm); err != nil {
 		return errors.WithStack(err)
 	}
-	if err := gen.printMethods(output); err != nil {
+	// the permission bits of new files are masked by umask.
+	if err := os.Chmod(path, gen.opts.FilePerm); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
-func (gen *Gen) parsePkg() error {
-	for _, file := range gen.pkg.Syntax {
-		if err := gen.parseFile(file); err != nil {
-			return errors.WithStack(err)
+// checkOverwrite returns an error if the given existing output file lacks the
+// "Code generated ... DO NOT EDIT." marker (e.g. as the file was written or
+// modified by hand), unless overwriting is forced (-force). Output files are
+// merged rather than overwritten in merge mode.
+func (gen *Gen) checkOverwrite(output string) error {
+	if gen.opts.Force || gen.opts.Merge {
+		return nil
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
 		}
+		return errors.WithStack(err)
 	}
-	return nil
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), output, data, parser.PackageClauseOnly|parser.ParseComments)
+	if err == nil && ast.IsGenerated(file) {
+		return nil
+	}
+	return errors.Errorf("refusing to overwrite %q without generated code marker (%q); use -force to overwrite", output, "// Code generated ... DO NOT EDIT.")
 }
 
-func (gen *Gen) parseFile(file *ast.File) error {
-	pos := gen.pkg.Fset.Position(file.FileStart)
-	clog.Debugln("file:", pos.Filename)
-	for _, decl := range file.Decls {
-		if err := gen.parseDecl(decl); err != nil {
+// printExtras writes the documentation, mocks and verification program of the
+// generated methods, as requested by the generator options, for the given
+// output path of the generated methods.
+func (gen *Gen) printExtras(output string) error {
+	if err := gen.printDocs(); err != nil {
+		return errors.WithStack(err)
+	}
+	if gen.opts.GenMocks {
+		if err := gen.printMocks(output); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if gen.opts.EmitVerify {
+		if err := gen.printVerify(output); err != nil {
 			return errors.WithStack(err)
 		}
 	}
 	return nil
 }
 
-func (gen *Gen) parseDecl(decl ast.Decl) error {
-	switch decl := decl.(type) {
-	case *ast.GenDecl:
-		//clog.Debugf("gen decl (%s): %#v", decl.Tok, decl)
-	case *ast.FuncDecl:
-		if err := gen.parseFuncDecl(decl); err != nil {
+// printDocs updates the README.md of the package and writes the Markdown API
+// table of the generated methods, as requested by the generator options.
+func (gen *Gen) printDocs() error {
+	if gen.opts.GenReadme {
+		if err := gen.updateReadme(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if len(gen.opts.EmitDocs) > 0 {
+		table, err := gen.apiTable()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		clog.Debugf("writing to %q", gen.opts.EmitDocs)
+		if err := os.WriteFile(gen.opts.EmitDocs, []byte(table), 0o644); err != nil {
 			return errors.WithStack(err)
 		}
-	default:
-		panic(fmt.Errorf("support for declaration type %T not yet implemented", decl))
 	}
 	return nil
 }
 
-func (gen *Gen) parseFuncDecl(decl *ast.FuncDecl) error {
-	if decl.Recv != nil {
-		return nil // skip methods (already generated).
+// outputSource returns the contents of the output file; either the generated
+// methods file, or in merge mode, the existing output file with generated
+// methods merged into its marked region.
+func (gen *Gen) outputSource() ([]byte, error) {
+	data, err := gen.source()
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
-	params := decl.Type.Params.List
-	if len(params) == 0 {
-		return nil // skip functions without parameters.
+	if data, err = gen.postProcess(data, gen.output); err != nil {
+		return nil, errors.WithStack(err)
 	}
-	firstParam := params[0]
-	if len(firstParam.Names) != 1 {
-		// TODO: add support for `a, b T` parameter lists.
-		return nil // skip `a, b T` parameter lists for now.
+	if gen.opts.Merge {
+		if len(gen.output) == 0 {
+			return nil, errors.New("merge mode requires an output path (-o)")
+		}
+		if data, err = mergeFile(gen.output, data); err != nil {
+			return nil, errors.WithStack(err)
+		}
 	}
-	clog.Debugln("func:", decl.Name)
-	firstParamName := firstParam.Names[0]
-	firstParamType := gen.pkg.TypesInfo.Types[firstParam.Type].Type
-	clog.Debugln("first param name:", firstParamName)
-	clog.Debugln("first param type:", firstParamType)
-	// if first parameter has valid type (e.g. *Window) convert to method.
-	if !gen.isValidMethodType(firstParamType) {
-		return nil // skip non-supported receiver type.
+	return data, nil
+}
+
+// postProcess applies the import path rewrites and the filter command (if any)
+// to the generated Go source of the specified output file.
+func (gen *Gen) postProcess(data []byte, output string) ([]byte, error) {
+	var err error
+	if len(gen.opts.RewriteImports) > 0 {
+		if data, err = rewriteImports(data, gen.opts.RewriteImports); err != nil {
+			return nil, errors.WithStack(err)
+		}
 	}
-	if err := gen.genMethod(decl); err != nil {
-		return errors.WithStack(err)
+	if len(gen.opts.Filter) > 0 {
+		if data, err = gen.runFilter(gen.opts.Filter, data, output); err != nil {
+			return nil, errors.WithStack(err)
+		}
 	}
-	return nil
+	return data, nil
 }
 
-func (gen *Gen) genMethod(funcDecl *ast.FuncDecl) error {
-	clog.Infoln("generating method:", funcDecl.Name)
-	params := funcDecl.Type.Params.List
-	firstParam := params[0]
-	firstParamName := firstParam.Names[0]
-	firstParamType := firstParam.Type
-	funcName := funcDecl.Name.String()
-	methodName := funcName
-	if newMethodName, ok := renameMethod[funcName]; ok {
-		methodName = newMethodName
+// source returns the formatted Go source of the generated methods file.
+func (gen *Gen) source() ([]byte, error) {
+	return gen.sourceOf(gen.methods, true, nil)
+}
+
+// pkgName returns the package name of the package clause of generated files;
+// the name of the loaded package unless overridden (-package).
+func (gen *Gen) pkgName() (string, error) {
+	name := gen.pkg.Name
+	if len(gen.opts.PackageName) > 0 {
+		name = gen.opts.PackageName
 	}
-	doc := &ast.CommentGroup{}
-	if funcDecl.Doc != nil {
-		for _, comment := range funcDecl.Doc.List {
-			newComment := &ast.Comment{
-				Slash: 0,
-				Text:  comment.Text,
-			}
-			doc.List = append(doc.List, newComment)
+	if gen.opts.GenWrap {
+		wrapName, err := gen.wrapPkgName()
+		if err != nil {
+			return "", errors.WithStack(err)
 		}
+		name = wrapName
 	}
-	methodDecl := &ast.FuncDecl{
-		Doc: doc,
-		Recv: &ast.FieldList{
-			List: []*ast.Field{
-				&ast.Field{
-					Names: []*ast.Ident{
-						ast.NewIdent(firstParamName.String()),
-					},
-					Type: firstParamType,
-				},
-			},
-		},
-		Name: ast.NewIdent(methodName),
-		Type: &ast.FuncType{
-			Params: &ast.FieldList{
-				List: funcDecl.Type.Params.List[1:], // skip first parameter (now receiver)
-			},
-			Results: funcDecl.Type.Results,
-		},
+	if !token.IsIdentifier(name) || name == "_" {
+		// e.g. packages of overlays or synthetic packages.
+		return "", errors.Errorf("invalid package name %q of package %q; use -package to override", name, gen.pkg.PkgPath)
 	}
-	var args []ast.Expr
-	for _, paramField := range funcDecl.Type.Params.List {
-		for _, paramName := range paramField.Names {
-			arg := paramName
-			args = append(args, arg)
+	return name, nil
+}
+
+// sourceOf returns the formatted Go source of a generated file containing the
+// given methods. The primary generated file also contains package-level
+// declarations used by generated methods (e.g. ErrNilReceiver) and the
+// package comment. The generated file is restricted by the given build
+// constraint expression if non-nil, in addition to the build tags of the loaded
+// package.
+func (gen *Gen) sourceOf(methods []*Method, primary bool, buildExpr constraint.Expr) ([]byte, error) {
+	pkgName, err := gen.pkgName()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	file := &ast.File{
+		Name: ast.NewIdent(pkgName),
+	}
+	var decls []ast.Decl
+	if primary && gen.useErrNilReceiver && !gen.isDeclared("ErrNilReceiver") {
+		decls = append(decls, errNilReceiverDecl())
+	}
+	if gen.opts.AssertInterfaces {
+		decls = append(decls, gen.assertDecls(methods)...)
+	}
+	decls = append(decls, gen.customAssertDecls(methods)...)
+	if gen.opts.GenWrap {
+		decls = append(decls, gen.wrapDecls(methods)...)
+	}
+	for _, method := range methods {
+		decls = append(decls, method.Decls...)
+		decls = append(decls, method.Decl)
+	}
+	if initDecl := promInitDecl(methods); initDecl != nil {
+		decls = append(decls, initDecl)
+	}
+	wrapperDecls, err := gen.safeWrapperDecls(methods)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	decls = append(decls, wrapperDecls...)
+	scopeDecls, err := gen.scopeDecls(methods, primary)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	decls = append(decls, scopeDecls...)
+	if importDecl := gen.importDecl(decls); importDecl != nil {
+		file.Decls = append(file.Decls, importDecl)
+	}
+	file.Decls = append(file.Decls, decls...)
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s\n", pre)
+	if expr := gen.outputConstraint(buildExpr); expr != nil {
+		// restrict generated methods to the build tag combination of the loaded
+		// package and the source file.
+		fmt.Fprintf(buf, "//go:build %s\n\n", expr)
+	}
+	if gen.opts.SummaryComment && len(methods) > 0 {
+		fmt.Fprintf(buf, "%s\n", gen.summaryComment(methods))
+	}
+	if primary && gen.opts.FileDoc {
+		if gen.hasPkgDoc() {
+			// place as non-doc comment to not conflict with existing package doc.
+			fmt.Fprintf(buf, "// This file contains generated methods of package %s.\n\n", pkgName)
+		} else {
+			fmt.Fprintf(buf, "// Package %s provides methods forwarding to package functions (generated methods).\n", pkgName)
 		}
 	}
-	callExpr := &ast.CallExpr{
-		Fun:  funcDecl.Name,
-		Args: args,
+	if gen.opts.NoFormat {
+		// print raw AST; valid Go source but unformatted.
+		cfg := &printer.Config{Mode: printer.RawFormat}
+		if err := cfg.Fprint(buf, gen.pkg.Fset, file); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return buf.Bytes(), nil
 	}
-	hasReturn := funcDecl.Type.Results != nil && len(funcDecl.Type.Results.List) > 0
-	var stmt ast.Stmt
-	if hasReturn {
-		stmt = &ast.ReturnStmt{
-			Results: []ast.Expr{callExpr},
+	if err := format.Node(buf, gen.pkg.Fset, file); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	data, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
+
+// summaryComment returns a comment summarizing the number of generated methods
+// per receiver type (e.g. "// 14 methods on *Renderer, 8 on *Surface."),
+// sorted by receiver type.
+func (gen *Gen) summaryComment(methods []*Method) string {
+	qualifier := types.RelativeTo(gen.pkg.Types)
+	counts := make(map[string]int)
+	for _, method := range methods {
+		counts[types.TypeString(method.RecvType, qualifier)]++
+	}
+	var recvTypes []string
+	for recvType := range counts {
+		recvTypes = append(recvTypes, recvType)
+	}
+	sort.Strings(recvTypes)
+	var parts []string
+	for i, recvType := range recvTypes {
+		n := counts[recvType]
+		switch {
+		case i == 0 && n == 1:
+			parts = append(parts, fmt.Sprintf("%d method on %s", n, recvType))
+		case i == 0:
+			parts = append(parts, fmt.Sprintf("%d methods on %s", n, recvType))
+		default:
+			parts = append(parts, fmt.Sprintf("%d on %s", n, recvType))
 		}
-	} else {
-		stmt = &ast.ExprStmt{
-			X: callExpr,
+	}
+	// wrap comment lines at 80 columns.
+	const maxWidth = 80
+	buf := &strings.Builder{}
+	line := "//"
+	for i, part := range parts {
+		if i < len(parts)-1 {
+			part += ","
+		} else {
+			part += "."
+		}
+		if len(line)+1+len(part) > maxWidth && line != "//" {
+			buf.WriteString(line + "\n")
+			line = "//"
 		}
+		line += " " + part
 	}
-	methodDecl.Body = &ast.BlockStmt{
-		List: []ast.Stmt{stmt},
+	buf.WriteString(line + "\n")
+	return buf.String()
+}
+
+// hasPkgDoc reports whether the analyzed package has a package doc comment,
+// outside of the output files.
+func (gen *Gen) hasPkgDoc() bool {
+	for _, file := range gen.pkg.Syntax {
+		if file.Doc == nil {
+			continue
+		}
+		filename := gen.pkg.Fset.Position(file.FileStart).Filename
+		if gen.isOutputFile(filename) {
+			continue
+		}
+		return true
 	}
-	gen.methods = append(gen.methods, methodDecl)
-	return nil
+	return false
 }
 
-const pre = `// Code generated by "genmethods"; DO NOT EDIT.
-`
+// isDeclared reports whether the given identifier is declared at package scope
+// of the analyzed package, outside of the output files.
+func (gen *Gen) isDeclared(name string) bool {
+	obj := gen.pkg.Types.Scope().Lookup(name)
+	if obj == nil {
+		return false
+	}
+	filename := gen.pkg.Fset.Position(obj.Pos()).Filename
+	return !gen.isOutputFile(filename)
+}
 
-func (gen *Gen) printMethods(output string) error {
-	file := &ast.File{
-		Name: ast.NewIdent(gen.pkg.Name),
+// isOutputFile reports whether the given file is an output file of the
+// generated methods.
+func (gen *Gen) isOutputFile(filename string) bool {
+	if len(gen.output) > 0 && sameFile(filename, gen.output) {
+		return true
 	}
-	for _, method := range gen.methods {
-		file.Decls = append(file.Decls, method)
+	for _, output := range gen.splitOutputs {
+		if sameFile(filename, output) {
+			return true
+		}
 	}
-	buf := &bytes.Buffer{}
-	fmt.Fprintln(buf, pre)
-	if err := format.Node(buf, gen.pkg.Fset, file); err != nil {
-		return errors.WithStack(err)
+	return false
+}
+
+// sameFile reports whether the given paths refer to the same file.
+func sameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
 	}
-	data, err := format.Source(buf.Bytes())
+	bInfo, err := os.Stat(b)
 	if err != nil {
-		return errors.WithStack(err)
+		return false
 	}
-	if len(output) > 0 {
-		clog.Debugf("writing to %q", output)
-		if err := os.WriteFile(output, data, 0o644); err != nil {
-			return errors.WithStack(err)
+	return os.SameFile(aInfo, bInfo)
+}
+
+// addTypeImports adds the imports of packages referenced by the given type
+// expression of the analyzed package (e.g. `io.Reader` of a parameter or
+// result type) to the imports available to generated methods, using the
+// package names of the source file.
+func (gen *Gen) addTypeImports(expr ast.Node) {
+	if expr == nil {
+		return
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
 		}
-	} else {
-		fmt.Print(string(data))
+		pkgName, ok := gen.pkg.TypesInfo.Uses[ident].(*types.PkgName)
+		if !ok {
+			return true
+		}
+		importPath := pkgName.Imported().Path()
+		gen.imports[importPath] = true
+		if name := pkgName.Name(); name != path.Base(importPath) {
+			if gen.importNames[importPath] == nil {
+				gen.importNames[importPath] = make(map[string]bool)
+			}
+			gen.importNames[importPath][name] = true
+		}
+		return true
+	})
+}
+
+// importDecl returns an import declaration of the import paths referenced by
+// the given generated declarations, or nil if no imports are used.
+func (gen *Gen) importDecl(decls []ast.Decl) *ast.GenDecl {
+	return gen.importDeclOf(gen.imports, decls)
+}
+
+// importDeclOf returns the import declaration of the given import paths used by
+// the given declarations, or nil if none is used.
+func (gen *Gen) importDeclOf(imports map[string]bool, decls []ast.Decl) *ast.GenDecl {
+	used := make(map[string]bool)
+	for _, decl := range decls {
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	var specs []*ast.ImportSpec
+	for importPath := range imports {
+		lit := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(importPath)}
+		if used[path.Base(importPath)] {
+			specs = append(specs, &ast.ImportSpec{Path: lit})
+		}
+		// the same import path may be imported by multiple names.
+		for name := range gen.importNames[importPath] {
+			if used[name] {
+				specs = append(specs, &ast.ImportSpec{Name: ast.NewIdent(name), Path: lit})
+			}
+		}
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+	// sort by import path, then name; unnamed imports first.
+	specName := func(spec *ast.ImportSpec) string {
+		if spec.Name == nil {
+			return ""
+		}
+		return spec.Name.Name
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		if specs[i].Path.Value != specs[j].Path.Value {
+			return specs[i].Path.Value < specs[j].Path.Value
+		}
+		return specName(specs[i]) < specName(specs[j])
+	})
+	importDecl := &ast.GenDecl{
+		Tok:    token.IMPORT,
+		Lparen: 1, // force parenthesized import list.
+	}
+	for _, spec := range specs {
+		importDecl.Specs = append(importDecl.Specs, spec)
+	}
+	return importDecl
+}
+
+// errNilReceiverDecl returns the declaration of ErrNilReceiver, as returned
+// by nil-receiver guards of generated methods.
+func errNilReceiverDecl() *ast.GenDecl {
+	return &ast.GenDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// ErrNilReceiver is returned when a method is invoked on a nil receiver."},
+			},
+		},
+		Tok: token.VAR,
+		Specs: []ast.Spec{
+			&ast.ValueSpec{
+				Names: []*ast.Ident{ast.NewIdent("ErrNilReceiver")},
+				Values: []ast.Expr{
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   ast.NewIdent("errors"),
+							Sel: ast.NewIdent("New"),
+						},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("nil receiver")},
+						},
+					},
+				},
+			},
+		},
 	}
-	return nil
 }
 
 var validMethodTypes = map[string]bool{
@@ -259,22 +2640,148 @@ var renameMethod = map[string]string{
 	"UpdateWindowSurface": "UpdateSurface",
 }
 
+// isValidMethodType reports whether methods are generated for the given
+// receiver type. Valid method types are matched exactly against the fully
+// qualified type string, and otherwise as path.Match patterns against both the
+// fully qualified type string (e.g. "*github.com/foo/sdl.Window") and the type
+// string qualified by package name (e.g. "*sdl.Window"). Patterns only match
+// types defined in the package of the source functions; thus the pattern
+// "*.Window" matches "*sdl.Window" but not "*other.Window" of an imported
+// package.
 func (gen *Gen) isValidMethodType(typ types.Type) bool {
-	return validMethodTypes[typ.String()]
+	typStr := typ.String()
+	var configTypes []string
+	if config := gen.opts.Config; config != nil {
+		configTypes = config.Types
+	}
+	// fast path; exact match.
+	if validMethodTypes[typStr] || gen.resolvedTypes[typStr] || slices.Contains(configTypes, typStr) {
+		return true
+	}
+	// slow path; pattern match.
+	if !gen.isLocalType(typ) {
+		return false
+	}
+	shortTypStr := types.TypeString(typ, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+	match := func(pattern string) bool {
+		return matchType(pattern, typStr) || matchType(pattern, shortTypStr)
+	}
+	if slices.ContainsFunc(configTypes, match) {
+		return true
+	}
+	for validType := range validMethodTypes {
+		if match(validType) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocalType reports whether the base type of the given type (e.g. Window of
+// *Window) is a defined type of the package of the source functions.
+func (gen *Gen) isLocalType(typ types.Type) bool {
+	if ptr, ok := types.Unalias(typ).(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := types.Unalias(typ).(*types.Named)
+	return ok && named.Obj().Pkg() == gen.pkg.Types
+}
+
+// matchType reports whether the given type string matches the path.Match
+// pattern. A leading "*" of the pattern only matches pointer types. Malformed
+// patterns never match.
+func matchType(pattern, typStr string) bool {
+	if strings.HasPrefix(pattern, "*") != strings.HasPrefix(typStr, "*") {
+		return false
+	}
+	match, err := path.Match(pattern, typStr)
+	return err == nil && match
+}
+
+// renameMethod returns the method name of the given function, as specified by
+// the user-provided config or the built-in rename table.
+func (gen *Gen) renameMethod(funcName string) (string, bool) {
+	if config := gen.opts.Config; config != nil {
+		if methodName, ok := config.Rename[funcName]; ok {
+			return methodName, true
+		}
+	}
+	methodName, ok := renameMethod[funcName]
+	return methodName, ok
+}
+
+// paramRenames returns the map from original to new parameter name of the
+// given function, as specified by the user-provided config.
+func (gen *Gen) paramRenames(funcName string) map[string]string {
+	if config := gen.opts.Config; config != nil {
+		return config.ParamNames[funcName]
+	}
+	return nil
+}
+
+// recvNameOverride returns the receiver name of methods on the given receiver
+// type, as specified by the user-provided config.
+func (gen *Gen) recvNameOverride(recvType types.Type) (string, bool) {
+	if config := gen.opts.Config; config != nil {
+		recvName, ok := config.RecvNames[recvType.String()]
+		return recvName, ok
+	}
+	return "", false
+}
+
+// renameRecv returns a copy of the given parameter list, where the receiver
+// parameter at the specified flat parameter index is renamed to the given
+// receiver name. The parameter list is returned unchanged if the receiver name
+// collides with another parameter.
+func renameRecv(params *ast.FieldList, recvIndex int, recvName string) (*ast.FieldList, bool) {
+	for i, param := range flatParams(params) {
+		if i != recvIndex && param.name.Name == recvName {
+			return params, false
+		}
+	}
+	newParams := &ast.FieldList{}
+	i := 0
+	for _, field := range params.List {
+		newField := &ast.Field{
+			Type: field.Type,
+		}
+		for _, name := range field.Names {
+			if i == recvIndex {
+				name = ast.NewIdent(recvName)
+			}
+			newField.Names = append(newField.Names, name)
+			i++
+		}
+		newParams.List = append(newParams.List, newField)
+	}
+	return newParams, true
 }
 
-func loadPkg(pkgPath string) (*packages.Package, error) {
-	cfg := &packages.Config{
-		Mode: packages.LoadSyntax,
+// forwardTemplate returns the forwarding target template of the given receiver
+// type, as specified by the user-provided config.
+func (gen *Gen) forwardTemplate(recvType types.Type) (string, bool) {
+	if config := gen.opts.Config; config != nil {
+		template, ok := config.Forward[recvType.String()]
+		return template, ok
 	}
-	pkgs, err := packages.Load(cfg, pkgPath)
+	return "", false
+}
+
+func loadPkg(pkgPath string, opts *GenOptions) (*packages.Package, error) {
+	pkgs, err := loadPackages(packagesConfig(opts), opts, pkgPath, pkgPath)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if err := checkPkgs(pkgPath, pkgs); err != nil {
+		return nil, errors.WithStack(err)
+	}
 	for _, pkg := range pkgs {
 		if pkg.PkgPath == pkgPath {
 			return pkg, nil
 		}
 	}
-	return nil, errors.Errorf("unable to locate pkg %q in %#v", pkgs)
+	err = errors.Errorf("unable to locate pkg %q in %#v", pkgPath, pkgs)
+	return nil, errors.WithStack(&ErrPackageLoad{PkgPath: pkgPath, Err: err})
 }
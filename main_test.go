@@ -0,0 +1,1222 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mewpkg/clog"
+	"golang.org/x/tools/go/packages"
+)
+
+// fixturePkgPath is the import path of the fixture packages of testdata (e.g.
+// testdata/sdl/sdl).
+const fixturePkgPath = "github.com/jupiterrider/purego-sdl3/sdl"
+
+// testdataDir is the absolute path of the testdata directory, as tests change
+// the working directory to copies of fixture modules.
+var testdataDir string
+
+// runMainEnv is the environment variable which, when set, makes the test
+// binary run the genmethods command (main) with its command line arguments
+// instead of the tests; as used by runMain.
+const runMainEnv = "GENMETHODS_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if len(os.Getenv(runMainEnv)) > 0 {
+		main()
+		os.Exit(0)
+	}
+	clog.SetPathLevel("github.com/mewspring/genmethods", clog.LevelWarn)
+	wd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	testdataDir = filepath.Join(wd, "testdata")
+	os.Exit(m.Run())
+}
+
+// newFixture copies the given fixture module of testdata (e.g. "sdl" of
+// testdata/sdl) into a temporary directory, and changes the working directory
+// to the copied module for the remainder of the test. The module directory is
+// returned.
+func newFixture(t testing.TB, name string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	src := filepath.Join(testdataDir, name)
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel), data, 0o644)
+	})
+	if err != nil {
+		t.Fatalf("unable to copy fixture %q; %v", name, err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return dir
+}
+
+// generated is the generated source file of a fixture package.
+type generated struct {
+	fset *token.FileSet
+	file *ast.File
+	// generated source.
+	src []byte
+}
+
+// genFixture generates methods of the fixture package of the given fixture
+// module of testdata into methods_gen.go using the given generation options,
+// checks that the fixture package compiles with the generated methods, and
+// returns the generated source file.
+func genFixture(t *testing.T, name string, opts *GenOptions) *generated {
+	t.Helper()
+	dir := newFixture(t, name)
+	output := filepath.Join(dir, "sdl", "methods_gen.go")
+	if _, err := genMethods(fixturePkgPath, output, opts); err != nil {
+		t.Fatalf("unable to generate methods of fixture %q; %+v", name, err)
+	}
+	src, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flags []string
+	if len(opts.PkgTags) > 0 {
+		flags = append(flags, "-tags="+opts.PkgTags)
+	}
+	checkCompiles(t, dir, flags...)
+	return parseGenerated(t, src)
+}
+
+// checkCompiles checks that the packages of the given module directory compile
+// and pass go vet, using the given build flags (e.g. "-tags=linux").
+func checkCompiles(t testing.TB, dir string, flags ...string) {
+	t.Helper()
+	args := append([]string{"vet"}, flags...)
+	cmd := exec.Command("go", append(args, "./...")...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated methods do not compile; %v\n%s", err, out)
+	}
+}
+
+// runFixtureTests runs the tests of the fixture module of the working
+// directory (e.g. exercising the generated methods).
+func runFixtureTests(t testing.TB) {
+	t.Helper()
+	cmd := exec.Command("go", "test", "./...")
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("tests of fixture failed; %v\n%s", err, out)
+	}
+}
+
+// runMain runs the genmethods command with the given command line arguments in
+// the given directory, by re-executing the test binary, and returns its
+// standard output and standard error.
+func runMain(t testing.TB, dir string, args ...string) (stdout, stderr []byte, err error) {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMainEnv+"=1", "GOWORK=off", "GOFLAGS=-mod=mod")
+	outBuf, errBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = outBuf, errBuf
+	err = cmd.Run()
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
+
+// parseGenerated parses the given generated source file.
+func parseGenerated(t testing.TB, src []byte) *generated {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "methods_gen.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("unable to parse generated source; %v\n%s", err, src)
+	}
+	return &generated{fset: fset, file: file, src: src}
+}
+
+// methodExprOf returns the method expression of the given method declaration
+// (e.g. "(*Window).GetSize" or "Color.String").
+func methodExprOf(decl *ast.FuncDecl) string {
+	recvType := types.ExprString(decl.Recv.List[0].Type)
+	if strings.HasPrefix(recvType, "*") {
+		return "(" + recvType + ")." + decl.Name.Name
+	}
+	return recvType + "." + decl.Name.Name
+}
+
+// methods returns the method expressions of the generated methods, in order
+// of declaration.
+func (g *generated) methods() []string {
+	var keys []string
+	for _, decl := range g.file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv != nil {
+			keys = append(keys, methodExprOf(funcDecl))
+		}
+	}
+	return keys
+}
+
+// lookup returns the generated method declaration of the given method
+// expression (e.g. "(*Window).GetSize").
+func (g *generated) lookup(t testing.TB, key string) *ast.FuncDecl {
+	t.Helper()
+	for _, decl := range g.file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv != nil && methodExprOf(funcDecl) == key {
+			return funcDecl
+		}
+	}
+	t.Fatalf("method %s not generated; got %v\n%s", key, g.methods(), g.src)
+	return nil
+}
+
+// method returns the formatted source of the generated method declaration of
+// the given method expression, without doc comment.
+func (g *generated) method(t testing.TB, key string) string {
+	t.Helper()
+	decl := *g.lookup(t, key)
+	decl.Doc = nil
+	buf := &bytes.Buffer{}
+	if err := format.Node(buf, g.fset, &decl); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// checkMethods checks the formatted source of the generated method
+// declarations against the given expected methods, by method expression.
+func (g *generated) checkMethods(t testing.TB, want map[string]string) {
+	t.Helper()
+	for _, key := range sortedKeys(want) {
+		if method := g.method(t, key); method != want[key] {
+			t.Errorf("method %s mismatch; expected %q, got %q", key, want[key], method)
+		}
+	}
+}
+
+// doc returns the doc comment of the generated method declaration of the
+// given method expression.
+func (g *generated) doc(t testing.TB, key string) string {
+	t.Helper()
+	decl := g.lookup(t, key)
+	if decl.Doc == nil {
+		return ""
+	}
+	var lines []string
+	for _, comment := range decl.Doc.List {
+		lines = append(lines, comment.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hasImport reports whether the generated source file imports the given
+// import path.
+func (g *generated) hasImport(importPath string) bool {
+	for _, spec := range g.file.Imports {
+		if spec.Path.Value == strconv.Quote(importPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMethod reports whether the method of the given method expression is
+// generated.
+func (g *generated) hasMethod(key string) bool {
+	for _, k := range g.methods() {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckNames(t *testing.T) {
+	golden := []struct {
+		// method name of GetWindowTitle after renames.
+		rename string
+		// expected method of GetWindowTitle.
+		want string
+	}{
+		{rename: "Title", want: "(*Window).Title"},
+		{rename: "", want: "(*Window).GetWindowTitle"},
+		{rename: "2Title", want: "(*Window).GetWindowTitle"},
+		{rename: "func", want: "(*Window).GetWindowTitle"},
+		{rename: "title", want: "(*Window).GetWindowTitle"},
+		{rename: "Get-Title", want: "(*Window).GetWindowTitle"},
+	}
+	for _, g := range golden {
+		t.Run(g.rename, func(t *testing.T) {
+			opts := &GenOptions{
+				CheckNames: true,
+				Config: &Config{
+					Rename: map[string]string{"GetWindowTitle": g.rename},
+				},
+			}
+			got := genFixture(t, "sdl", opts)
+			want := "func (window *Window) " + strings.TrimPrefix(g.want, "(*Window).") + "() string {\n\treturn GetWindowTitle(window)\n}"
+			if method := got.method(t, g.want); method != want {
+				t.Errorf("method mismatch; expected %q, got %q", want, method)
+			}
+		})
+	}
+}
+
+func TestCheckMethodName(t *testing.T) {
+	golden := []struct {
+		methodName string
+		funcName   string
+		// expected error; empty if valid.
+		err string
+	}{
+		{methodName: "GetSize", funcName: "GetWindowSize"},
+		{methodName: "getSize", funcName: "getWindowSize"},
+		{methodName: "", funcName: "GetWindowSize", err: `method name "" is not a valid Go identifier`},
+		{methodName: "2D", funcName: "Render2D", err: `method name "2D" is not a valid Go identifier`},
+		{methodName: "type", funcName: "GetWindowType", err: `method name "type" is a Go keyword`},
+		{methodName: "size", funcName: "GetWindowSize", err: `method name "size" of exported function is unexported`},
+	}
+	for _, g := range golden {
+		err := checkMethodName(g.methodName, g.funcName)
+		switch {
+		case err == nil && len(g.err) > 0:
+			t.Errorf("%q: expected error %q, got nil", g.methodName, g.err)
+		case err != nil && err.Error() != g.err:
+			t.Errorf("%q: error mismatch; expected %q, got %q", g.methodName, g.err, err)
+		}
+	}
+}
+
+func TestAdaptRecv(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected methods, by method expression.
+		want map[string]string
+		// method expressions of methods not generated.
+		skip []string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Window).GetWindowTitle": "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}",
+			},
+			skip: []string{"(*Window).GetWindowID", "Window.GetWindowID"},
+		},
+		{
+			name: "adapt value to pointer",
+			opts: &GenOptions{AdaptRecv: true},
+			want: map[string]string{
+				"(*Window).GetWindowTitle": "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}",
+				"(*Window).GetWindowID":    "func (window *Window) GetWindowID() int32 {\n\treturn GetWindowID(*window)\n}",
+			},
+		},
+		{
+			name: "pinned pointer",
+			opts: &GenOptions{Config: &Config{Receivers: map[string]string{fixturePkgPath + ".Window": "pointer"}}},
+			want: map[string]string{
+				"(*Window).GetWindowTitle": "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}",
+				"(*Window).GetWindowID":    "func (window *Window) GetWindowID() int32 {\n\treturn GetWindowID(*window)\n}",
+			},
+		},
+		{
+			name: "pinned value",
+			opts: &GenOptions{Config: &Config{Receivers: map[string]string{fixturePkgPath + ".Window": "value"}}},
+			want: map[string]string{
+				"Window.GetWindowTitle": "func (window Window) GetWindowTitle() string {\n\treturn GetWindowTitle(&window)\n}",
+				"Window.GetWindowID":    "func (window Window) GetWindowID() int32 {\n\treturn GetWindowID(window)\n}",
+				"Window.RaiseWindow":    "func (window Window) RaiseWindow() bool {\n\treturn RaiseWindow(&window)\n}",
+			},
+			skip: []string{"(*Window).GetWindowTitle"},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", g.opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			for _, key := range g.skip {
+				if got.hasMethod(key) {
+					t.Errorf("unexpected method %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestRecvPriority(t *testing.T) {
+	const (
+		first = "func (src *Surface) Blit(dst *Surface) bool { return BlitSurface(src, dst) }"
+		last  = "func (dst *Surface) Blit(src *Surface) bool { return BlitSurface(src, dst) }"
+	)
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected method of BlitSurface.
+		want string
+	}{
+		{name: "first", opts: &GenOptions{AnyPosition: true}, want: first},
+		{name: "explicit first", opts: &GenOptions{AnyPosition: true, RecvPriority: "first"}, want: first},
+		{name: "last", opts: &GenOptions{AnyPosition: true, RecvPriority: "last"}, want: last},
+		{
+			name: "override",
+			opts: &GenOptions{AnyPosition: true, Config: &Config{RecvParams: map[string]string{"BlitSurface": "dst"}}},
+			want: last,
+		},
+		{
+			name: "override last",
+			opts: &GenOptions{AnyPosition: true, RecvPriority: "last", Config: &Config{RecvParams: map[string]string{"BlitSurface": "src"}}},
+			want: first,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", g.opts)
+			if method := got.method(t, "(*Surface).Blit"); method != g.want {
+				t.Errorf("method mismatch; expected %q, got %q", g.want, method)
+			}
+		})
+	}
+}
+
+func TestResolveTypes(t *testing.T) {
+	golden := []struct {
+		types []string
+		// expected methods, by method expression.
+		want map[string]string
+		// expected error; empty if generated.
+		err string
+	}{
+		{
+			types: []string{"*device"},
+			want: map[string]string{
+				"(*device).deviceFlags": "func (d *device) deviceFlags() uint32 {\n\treturn deviceFlags(d)\n}",
+				"(*device).ResetDevice": "func (d *device) ResetDevice() {\n\tResetDevice(d)\n}",
+			},
+		},
+		{
+			types: []string{"*device", "*Window"},
+			want: map[string]string{
+				"(*device).ResetDevice":    "func (d *device) ResetDevice() {\n\tResetDevice(d)\n}",
+				"(*Window).GetWindowTitle": "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}",
+			},
+		},
+		{
+			types: []string{"*renderer"},
+			err:   `unable to locate receiver type "renderer" in scope of package "github.com/jupiterrider/purego-sdl3/sdl"`,
+		},
+		{
+			types: []string{"*device", "*renderer", "surface"},
+			err:   `unable to locate receiver types "renderer", "surface" in scope of package "github.com/jupiterrider/purego-sdl3/sdl"`,
+		},
+	}
+	for _, g := range golden {
+		t.Run(strings.Join(g.types, ","), func(t *testing.T) {
+			opts := &GenOptions{Types: g.types}
+			if len(g.err) > 0 {
+				dir := newFixture(t, "sdl")
+				_, err := genMethods(fixturePkgPath, filepath.Join(dir, "sdl", "methods_gen.go"), opts)
+				if err == nil {
+					t.Fatalf("expected error %q, got nil", g.err)
+				}
+				if err.Error() != g.err {
+					t.Errorf("error mismatch; expected %q, got %q", g.err, err)
+				}
+				return
+			}
+			got := genFixture(t, "sdl", opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+		})
+	}
+}
+
+func TestPreserveAliases(t *testing.T) {
+	golden := []struct {
+		opts *GenOptions
+		// method expression of RaiseWindow.
+		key string
+		// expected method of RaiseWindow.
+		want string
+	}{
+		{
+			opts: &GenOptions{},
+			key:  "(*Window).RaiseWindow",
+			want: "func (window *Window) RaiseWindow() bool {\n\treturn RaiseWindow(window)\n}",
+		},
+		{
+			opts: &GenOptions{PreserveAliases: true},
+			key:  "WindowPtr.RaiseWindow",
+			want: "func (window WindowPtr) RaiseWindow() bool {\n\treturn RaiseWindow(window)\n}",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.key, func(t *testing.T) {
+			got := genFixture(t, "sdl", g.opts)
+			if method := got.method(t, g.key); method != g.want {
+				t.Errorf("method mismatch; expected %q, got %q", g.want, method)
+			}
+			// methods of functions taking *Window are unaffected.
+			const want = "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}"
+			if method := got.method(t, "(*Window).GetWindowTitle"); method != want {
+				t.Errorf("method mismatch; expected %q, got %q", want, method)
+			}
+		})
+	}
+}
+
+func TestFuncReceivers(t *testing.T) {
+	golden := []struct {
+		name          string
+		funcReceivers map[string]string
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name:          "exact",
+			funcReceivers: map[string]string{"GetWindowTitle": "value"},
+			want: map[string]string{
+				"Window.GetWindowTitle": "func (window Window) GetWindowTitle() string {\n\treturn GetWindowTitle(&window)\n}",
+				"(*Window).Destroy":     "func (window *Window) Destroy() {\n\tDestroyWindow(window)\n}",
+			},
+		},
+		{
+			name:          "pattern",
+			funcReceivers: map[string]string{"GetWindow*": "value", "GetWindowSize": "pointer"},
+			want: map[string]string{
+				"Window.GetWindowTitle": "func (window Window) GetWindowTitle() string {\n\treturn GetWindowTitle(&window)\n}",
+				"(*Window).GetSize":     "func (window *Window) GetSize(w, h *int32) bool { return GetWindowSize(window, w, h) }",
+				"(*Window).Destroy":     "func (window *Window) Destroy() {\n\tDestroyWindow(window)\n}",
+			},
+		},
+		{
+			name:          "longest pattern",
+			funcReceivers: map[string]string{"*Window*": "value", "DestroyWin*": "pointer"},
+			want: map[string]string{
+				"Window.GetWindowTitle": "func (window Window) GetWindowTitle() string {\n\treturn GetWindowTitle(&window)\n}",
+				"(*Window).Destroy":     "func (window *Window) Destroy() {\n\tDestroyWindow(window)\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			opts := &GenOptions{Config: &Config{FuncReceivers: g.funcReceivers}}
+			got := genFixture(t, "sdl", opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+		})
+	}
+}
+
+func TestNilParams(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected skip reason of the synthetic function.
+		want string
+	}{
+		{
+			name: "first position",
+			opts: &GenOptions{},
+			want: "function has no parameters",
+		},
+		{
+			name: "any position",
+			opts: &GenOptions{AnyPosition: true},
+			want: "function has no named parameters",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "sdl")
+			output := filepath.Join(dir, "sdl", "methods_gen.go")
+			gen, err := newGen(fixturePkgPath, output, g.opts)
+			if err != nil {
+				t.Fatalf("unable to generate methods of fixture; %+v", err)
+			}
+			// synthetic function with nil parameter list (e.g. of partial
+			// parses).
+			decl := &ast.FuncDecl{
+				Name: ast.NewIdent("Synthetic"),
+				Type: &ast.FuncType{},
+				Body: &ast.BlockStmt{},
+			}
+			if err := gen.parseFuncDecl(decl); err != nil {
+				t.Fatalf("unable to parse synthetic function; %+v", err)
+			}
+			if reason := gen.skipReasons["Synthetic"]; reason != g.want {
+				t.Errorf("skip reason mismatch; expected %q, got %q", g.want, reason)
+			}
+			if err := gen.printMethods(output); err != nil {
+				t.Fatalf("unable to print methods; %+v", err)
+			}
+			checkCompiles(t, dir)
+			src, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := parseGenerated(t, src)
+			if !got.hasMethod("(*Window).Destroy") {
+				t.Errorf("method (*Window).Destroy not generated")
+			}
+		})
+	}
+}
+
+func TestAfterMethodDrop(t *testing.T) {
+	dir := newFixture(t, "sdl")
+	output := filepath.Join(dir, "sdl", "methods_gen.go")
+	// HideWindow and DestroyWindow are both converted to (*Window).Destroy; the
+	// name is released by dropping the first of the two.
+	var dropped string
+	opts := &GenOptions{
+		Config: &Config{Rename: map[string]string{"HideWindow": "Destroy"}},
+		AfterMethod: func(decl *ast.FuncDecl) *ast.FuncDecl {
+			if len(dropped) == 0 && methodExprOf(decl) == "(*Window).Destroy" {
+				// name of forwarded function.
+				ast.Inspect(decl.Body, func(n ast.Node) bool {
+					if call, ok := n.(*ast.CallExpr); ok && len(dropped) == 0 {
+						dropped = types.ExprString(call.Fun)
+					}
+					return len(dropped) == 0
+				})
+				return nil
+			}
+			return decl
+		},
+	}
+	gen, err := newGen(fixturePkgPath, output, opts)
+	if err != nil {
+		t.Fatalf("unable to generate methods of fixture; %+v", err)
+	}
+	if len(dropped) == 0 {
+		t.Fatal("method (*Window).Destroy not passed to AfterMethod hook")
+	}
+	for _, parsed := range gen.ParsedFuncs {
+		if parsed.Decl.Name.Name == dropped && !parsed.Skipped {
+			t.Errorf("function %s of dropped method recorded as converted to method %s", dropped, parsed.MethodName)
+		}
+	}
+	if want := "function " + dropped + " was skipped because generated method dropped by AfterMethod hook"; gen.ExplainSkip(dropped) != want {
+		t.Errorf("skip explanation mismatch; expected %q, got %q", want, gen.ExplainSkip(dropped))
+	}
+	if err := gen.printMethods(output); err != nil {
+		t.Fatalf("unable to print methods; %+v", err)
+	}
+	checkCompiles(t, dir)
+	src, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parseGenerated(t, src)
+	if method := got.method(t, "(*Window).Destroy"); strings.Contains(method, dropped+"(") {
+		t.Errorf("dropped method (*Window).Destroy generated; got %q", method)
+	}
+}
+
+func TestSkipDocKeyword(t *testing.T) {
+	golden := []struct {
+		name    string
+		keyword string
+		// expected generated method.
+		want bool
+	}{
+		{name: "no keyword", keyword: "", want: true},
+		{name: "keyword", keyword: "internal use only", want: false},
+		{name: "case insensitive", keyword: "INTERNAL USE", want: false},
+		{name: "keyword not in doc", keyword: "deprecated", want: true},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", &GenOptions{SkipDocKeyword: g.keyword})
+			if ok := got.hasMethod("(*Window).SyncWindow"); ok != g.want {
+				t.Errorf("method (*Window).SyncWindow generated mismatch; expected %v, got %v", g.want, ok)
+			}
+			// unrelated functions are not skipped.
+			if !got.hasMethod("(*Window).GetSize") {
+				t.Errorf("method (*Window).GetSize not generated")
+			}
+		})
+	}
+}
+
+func TestRecvNames(t *testing.T) {
+	golden := []struct {
+		name      string
+		recvNames map[string]string
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "source names",
+			want: map[string]string{
+				"(*Window).SetWindowTitle": "func (window *Window) SetWindowTitle(title string) bool { return SetWindowTitle(window, title) }",
+				"(*Renderer).Clear":        "func (renderer *Renderer) Clear() bool {\n\treturn RenderClear(renderer)\n}",
+			},
+		},
+		{
+			name: "per type",
+			recvNames: map[string]string{
+				"*" + fixturePkgPath + ".Window":   "w",
+				"*" + fixturePkgPath + ".Renderer": "r",
+			},
+			want: map[string]string{
+				"(*Window).SetWindowTitle": "func (w *Window) SetWindowTitle(title string) bool { return SetWindowTitle(w, title) }",
+				"(*Window).Destroy":        "func (w *Window) Destroy() {\n\tDestroyWindow(w)\n}",
+				"(*Renderer).Clear":        "func (r *Renderer) Clear() bool {\n\treturn RenderClear(r)\n}",
+				// collides with parameter w; source name kept.
+				"(*Window).GetSize": "func (window *Window) GetSize(w, h *int32) bool { return GetWindowSize(window, w, h) }",
+				// unconfigured receiver type.
+				"(*Surface).Lock": "func (surface *Surface) Lock() bool {\n\treturn LockSurface(surface)\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", &GenOptions{Config: &Config{RecvNames: g.recvNames}})
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+		})
+	}
+}
+
+func TestResultFilters(t *testing.T) {
+	count := func(n int) *int {
+		return &n
+	}
+	// methods on *Window of functions with zero, one and two results.
+	keys := []string{"(*Window).Destroy", "(*Window).GetSize", "(*Window).CreateRenderer"}
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected generated methods of keys.
+		want []string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: []string{"(*Window).Destroy", "(*Window).GetSize", "(*Window).CreateRenderer"},
+		},
+		{
+			name: "min results",
+			opts: &GenOptions{MinResults: 1},
+			want: []string{"(*Window).GetSize", "(*Window).CreateRenderer"},
+		},
+		{
+			name: "max results",
+			opts: &GenOptions{MaxResults: count(1)},
+			want: []string{"(*Window).Destroy", "(*Window).GetSize"},
+		},
+		{
+			name: "exactly one result",
+			opts: &GenOptions{MinResults: 1, MaxResults: count(1)},
+			want: []string{"(*Window).GetSize"},
+		},
+		{
+			name: "max zero results",
+			opts: &GenOptions{MaxResults: count(0)},
+			want: []string{"(*Window).Destroy"},
+		},
+		{
+			name: "must return error",
+			opts: &GenOptions{MustReturnError: true},
+			want: []string{"(*Window).CreateRenderer"},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", g.opts)
+			var methods []string
+			for _, key := range keys {
+				if got.hasMethod(key) {
+					methods = append(methods, key)
+				}
+			}
+			if !slices.Equal(methods, g.want) {
+				t.Errorf("methods mismatch; expected %v, got %v", g.want, methods)
+			}
+		})
+	}
+}
+
+func TestResultFiltersValidate(t *testing.T) {
+	count := func(n int) *int {
+		return &n
+	}
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected substring of error.
+		want string
+	}{
+		{
+			name: "negative min results",
+			opts: &GenOptions{MinResults: -1},
+			want: "invalid minimum result count (-min-results=-1)",
+		},
+		{
+			name: "negative max results",
+			opts: &GenOptions{MaxResults: count(-1)},
+			want: "invalid maximum result count (-max-results=-1)",
+		},
+		{
+			name: "min exceeds max",
+			opts: &GenOptions{MinResults: 2, MaxResults: count(1)},
+			want: "minimum (-min-results=2) exceeds maximum (-max-results=1)",
+		},
+		{
+			name: "must return error of zero results",
+			opts: &GenOptions{MustReturnError: true, MaxResults: count(0)},
+			want: "skipped by maximum result count of zero (-max-results=0)",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			err := g.opts.Validate()
+			if err == nil || !strings.Contains(err.Error(), g.want) {
+				t.Errorf("error mismatch; expected %q, got %v", g.want, err)
+			}
+		})
+	}
+}
+
+func TestAutoFluent(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected methods, by method expression.
+		want map[string]string
+		// expected doc comments, by method expression.
+		docs map[string]string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Window).ResetWindow": "func (window *Window) ResetWindow() *Window {\n\treturn ResetWindow(window)\n}",
+				"(*Window).FocusWindow": "func (window *Window) FocusWindow() WindowPtr {\n\treturn FocusWindow(window)\n}",
+			},
+			docs: map[string]string{
+				"(*Window).ResetWindow": "// ResetWindow resets the window.",
+			},
+		},
+		{
+			name: "auto fluent",
+			opts: &GenOptions{AutoFluent: true},
+			want: map[string]string{
+				"(*Window).ResetWindow": "func (window *Window) ResetWindow() *Window {\n\treturn ResetWindow(window)\n}",
+				// result of alias type spelled as receiver type.
+				"(*Window).FocusWindow": "func (window *Window) FocusWindow() *Window {\n\treturn FocusWindow(window)\n}",
+			},
+			docs: map[string]string{
+				"(*Window).ResetWindow": "// ResetWindow resets the window.\n//\n// ResetWindow returns the receiver, allowing for chained method calls.",
+				"(*Window).FocusWindow": "// FocusWindow focuses the window.\n//\n// FocusWindow returns the receiver, allowing for chained method calls.",
+				// result of other type.
+				"(*Window).CreateRenderer": "// CreateRenderer creates a renderer of the window.",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "sdl", g.opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			for key, want := range g.docs {
+				if doc := got.doc(t, key); doc != want {
+					t.Errorf("doc comment of method %s mismatch; expected %q, got %q", key, want, doc)
+				}
+			}
+		})
+	}
+}
+
+func TestTypesFile(t *testing.T) {
+	golden := []struct {
+		name string
+		// contents of types file.
+		content string
+		// receiver types of -types, merged with types file.
+		types []string
+		// expected receiver type names of types file.
+		names []string
+		// expected methods, by method expression.
+		want map[string]string
+		// expected error; empty if generated.
+		err string
+	}{
+		{
+			name:    "comments",
+			content: "# receiver types of package sdl.\n\n*device # internal device\n\t*Window\n#*Renderer\n",
+			names:   []string{"*device", "*Window"},
+			want: map[string]string{
+				"(*device).ResetDevice":    "func (d *device) ResetDevice() {\n\tResetDevice(d)\n}",
+				"(*Window).GetWindowTitle": "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}",
+			},
+		},
+		{
+			name:    "merged with flag",
+			content: "*device\n",
+			types:   []string{"*Window"},
+			names:   []string{"*device"},
+			want: map[string]string{
+				"(*device).deviceFlags":    "func (d *device) deviceFlags() uint32 {\n\treturn deviceFlags(d)\n}",
+				"(*Window).GetWindowTitle": "func (window *Window) GetWindowTitle() string {\n\treturn GetWindowTitle(window)\n}",
+			},
+		},
+		{
+			name:    "invalid name",
+			content: "# comment\n*device\nsdl.Window\n",
+			err:     `invalid receiver type name "sdl.Window" on line 3 of types file`,
+		},
+		{
+			name:    "unresolved names",
+			content: "*device\n*renderer # not declared\nsurface\n",
+			names:   []string{"*device", "*renderer", "surface"},
+			err:     `unable to locate receiver types "renderer", "surface" in scope of package "github.com/jupiterrider/purego-sdl3/sdl"`,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			typesPath := filepath.Join(t.TempDir(), "types.txt")
+			if err := os.WriteFile(typesPath, []byte(g.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			names, err := readTypesFile(typesPath)
+			if err != nil {
+				if len(g.err) == 0 || !strings.Contains(err.Error(), g.err) {
+					t.Fatalf("error mismatch; expected %q, got %v", g.err, err)
+				}
+				return
+			}
+			if !slices.Equal(names, g.names) {
+				t.Errorf("receiver type names mismatch; expected %q, got %q", g.names, names)
+			}
+			opts := &GenOptions{Types: append(g.types, names...)}
+			if len(g.err) > 0 {
+				dir := newFixture(t, "sdl")
+				_, err := genMethods(fixturePkgPath, filepath.Join(dir, "sdl", "methods_gen.go"), opts)
+				if err == nil || err.Error() != g.err {
+					t.Errorf("error mismatch; expected %q, got %v", g.err, err)
+				}
+				return
+			}
+			got := genFixture(t, "sdl", opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+		})
+	}
+}
+
+func TestStdoutOutput(t *testing.T) {
+	golden := []struct {
+		name string
+		args []string
+		// expected error; empty if generated.
+		err string
+	}{
+		{name: "dash", args: []string{"-o", "-"}},
+		{name: "empty", args: []string{"-o", ""}},
+		{name: "no output flag", args: nil},
+		{
+			name: "split mode",
+			args: []string{"-o", "-", "-split-by-type"},
+			err:  "standard output (-o -) is not supported in split mode (-split-by-type) or group-by-file mode (-group-by-file)",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "sdl")
+			args := append([]string{"-pkg", fixturePkgPath}, g.args...)
+			stdout, stderr, err := runMain(t, dir, args...)
+			if len(g.err) > 0 {
+				if err == nil || !bytes.Contains(stderr, []byte(g.err)) {
+					t.Errorf("error mismatch; expected %q, got %v\n%s", g.err, err, stderr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to run genmethods; %v\n%s", err, stderr)
+			}
+			// no file named "-" is created.
+			for _, path := range []string{filepath.Join(dir, "-"), filepath.Join(dir, "sdl", "-")} {
+				if _, err := os.Lstat(path); !os.IsNotExist(err) {
+					t.Errorf("unexpected output file %q; %v", path, err)
+				}
+			}
+			// the generated methods written to standard output compile.
+			if err := os.WriteFile(filepath.Join(dir, "sdl", "methods_gen.go"), stdout, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			checkCompiles(t, dir)
+			got := parseGenerated(t, stdout)
+			const key = "(*Window).GetSize"
+			if method, want := got.method(t, key), "func (window *Window) GetSize(w, h *int32) bool { return GetWindowSize(window, w, h) }"; method != want {
+				t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+			}
+		})
+	}
+}
+
+func TestNilGuard(t *testing.T) {
+	// value receiver of GetWindowTitle.
+	funcReceivers := map[string]string{"GetWindowTitle": "value"}
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Renderer).Clear": "func (renderer *Renderer) Clear() bool {\n\treturn RenderClear(renderer)\n}",
+			},
+		},
+		{
+			name: "nil guard",
+			opts: &GenOptions{NilGuard: true},
+			want: map[string]string{
+				"(*Renderer).Clear":     "func (renderer *Renderer) Clear() bool {\n\tif renderer == nil {\n\t\tpanic(\"nil Renderer\")\n\t}\n\treturn RenderClear(renderer)\n}",
+				"(*Window).Destroy":     "func (window *Window) Destroy() {\n\tif window == nil {\n\t\tpanic(\"nil Window\")\n\t}\n\tDestroyWindow(window)\n}",
+				"Window.GetWindowTitle": "func (window Window) GetWindowTitle() string {\n\treturn GetWindowTitle(&window)\n}",
+			},
+		},
+		{
+			name: "nil guard message",
+			opts: &GenOptions{NilGuard: true, NilGuardMessage: "{method} called on nil {type}"},
+			want: map[string]string{
+				"(*Renderer).Clear":     "func (renderer *Renderer) Clear() bool {\n\tif renderer == nil {\n\t\tpanic(\"Clear called on nil Renderer\")\n\t}\n\treturn RenderClear(renderer)\n}",
+				"(*Window).GetSize":     "func (window *Window) GetSize(w, h *int32) bool {\n\tif window == nil {\n\t\tpanic(\"GetSize called on nil Window\")\n\t}\n\treturn GetWindowSize(window, w, h)\n}",
+				"Window.GetWindowTitle": "func (window Window) GetWindowTitle() string {\n\treturn GetWindowTitle(&window)\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			g.opts.Config = &Config{FuncReceivers: funcReceivers}
+			genFixture(t, "sdl", g.opts).checkMethods(t, g.want)
+		})
+	}
+	opts := &GenOptions{NilGuard: true, StubNilChecks: true}
+	const want = "-stub-nil-checks and -nil-guard are mutually exclusive"
+	if err := opts.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("error mismatch; expected %q, got %v", want, err)
+	}
+}
+
+func TestTypeImports(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: map[string]string{
+				"(*Window).GetWindowBounds":   "func (window *Window) GetWindowBounds() rect.Rect {\n\treturn GetWindowBounds(window)\n}",
+				"(*Window).SetWindowBounds":   "func (window *Window) SetWindowBounds(bounds rect.Rect) { SetWindowBounds(window, bounds) }",
+				"(*Window).GetWindowPosition": "func (window *Window) GetWindowPosition() geom.Point {\n\treturn GetWindowPosition(window)\n}",
+			},
+		},
+		{
+			name: "receiver names",
+			opts: &GenOptions{Config: &Config{RecvNames: map[string]string{"*" + fixturePkgPath + ".Window": "w"}}},
+			want: map[string]string{
+				"(*Window).GetWindowBounds":   "func (w *Window) GetWindowBounds() rect.Rect {\n\treturn GetWindowBounds(w)\n}",
+				"(*Window).GetWindowPosition": "func (w *Window) GetWindowPosition() geom.Point {\n\treturn GetWindowPosition(w)\n}",
+			},
+		},
+	}
+	// imports of the rect package, by the package names of the source files.
+	rectPath := strconv.Quote("github.com/jupiterrider/purego-sdl3/rect")
+	wantImports := []string{rectPath, "geom " + rectPath}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "imports")
+			// load the rect dependency of the fixture package from source.
+			cfg := packagesConfig(g.opts)
+			cfg.Mode |= packages.NeedDeps
+			pkgs, err := packages.Load(cfg, fixturePkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if packages.PrintErrors(pkgs) > 0 {
+				t.Fatalf("unable to load package %q", fixturePkgPath)
+			}
+			output := filepath.Join(dir, "sdl", "methods_gen.go")
+			gen, err := newGenFromPkg(pkgs[0], output, g.opts)
+			if err != nil {
+				t.Fatalf("unable to generate methods; %+v", err)
+			}
+			if err := gen.printMethods(output); err != nil {
+				t.Fatalf("unable to print methods; %+v", err)
+			}
+			src, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkCompiles(t, dir)
+			got := parseGenerated(t, src)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			var imports []string
+			for _, spec := range got.file.Imports {
+				imp := spec.Path.Value
+				if spec.Name != nil {
+					imp = spec.Name.Name + " " + imp
+				}
+				imports = append(imports, imp)
+			}
+			if !slices.Equal(imports, wantImports) {
+				t.Errorf("imports mismatch; expected %q, got %q", wantImports, imports)
+			}
+		})
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	golden := []struct {
+		name string
+		args []string
+		// expected package name of generated file.
+		want string
+	}{
+		{name: "default", args: nil, want: "main"},
+		{name: "override", args: []string{"-package", "main"}, want: "main"},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "command")
+			args := append([]string{"-pkg", fixturePkgPath, "-o", "sdl/methods_gen.go"}, g.args...)
+			if _, stderr, err := runMain(t, dir, args...); err != nil {
+				t.Fatalf("unable to run genmethods; %v\n%s", err, stderr)
+			}
+			src, err := os.ReadFile(filepath.Join(dir, "sdl", "methods_gen.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := parseGenerated(t, src)
+			if name := got.file.Name.Name; name != g.want {
+				t.Errorf("package name mismatch; expected %q, got %q", g.want, name)
+			}
+			// the command builds with the generated methods.
+			checkCompiles(t, dir)
+			cmd := exec.Command("go", "build", "-o", os.DevNull, "./sdl")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("unable to build command; %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestFilePerm(t *testing.T) {
+	const warning = "include execute bits"
+	golden := []struct {
+		name string
+		args []string
+		// expected permission bits of the generated file.
+		want fs.FileMode
+		// expected warning of execute bits.
+		warn bool
+		// expected error; empty if generated.
+		err string
+	}{
+		{name: "default", want: 0o644},
+		{name: "owner only", args: []string{"-file-perm", "0o600"}, want: 0o600},
+		{name: "execute bits", args: []string{"-file-perm", "0o755"}, want: 0o755, warn: true},
+		{name: "not writable", args: []string{"-file-perm", "0o444"}, err: "generated files must be readable and writable by their owner"},
+		{name: "invalid bits", args: []string{"-file-perm", "0o1644"}, err: "expected at most 0o777"},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "sdl")
+			args := append([]string{"-pkg", fixturePkgPath, "-o", "sdl/methods_gen.go"}, g.args...)
+			_, stderr, err := runMain(t, dir, args...)
+			if len(g.err) > 0 {
+				if err == nil || !bytes.Contains(stderr, []byte(g.err)) {
+					t.Errorf("error mismatch; expected %q, got %v\n%s", g.err, err, stderr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to run genmethods; %v\n%s", err, stderr)
+			}
+			if n := bytes.Count(stderr, []byte(warning)); (n == 1) != g.warn || n > 1 {
+				t.Errorf("warning mismatch; expected warning %v, got %d warnings\n%s", g.warn, n, stderr)
+			}
+			info, err := os.Stat(filepath.Join(dir, "sdl", "methods_gen.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != g.want {
+				t.Errorf("permission bits mismatch; expected %O, got %O", g.want, perm)
+			}
+		})
+	}
+}
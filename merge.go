@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Markers of the region replaced by generated methods in merge mode.
+const (
+	mergeBegin = "// genmethods:begin"
+	mergeEnd   = "// genmethods:end"
+)
+
+// mergeFile splices the declarations of the generated source file into the
+// marked region of the existing file at the given path, and returns the
+// merged file contents. Declarations outside of the marked region are left
+// untouched; imports required by the generated methods are added to the
+// import declarations of the existing file, and imports only used by the
+// previously generated methods of the marked region are removed.
+func mergeFile(mergePath string, generated []byte) ([]byte, error) {
+	src, err := os.ReadFile(mergePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	start, end, err := findMergeRegion(src)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid merge region in %q", mergePath)
+	}
+	decls, imports, err := splitDecls(generated)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	oldFset := token.NewFileSet()
+	oldFile, err := parser.ParseFile(oldFset, mergePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// names of imports used by the previously generated methods.
+	oldNames := selectorNames(oldFile, func(pos token.Pos) bool {
+		off := oldFset.Position(pos).Offset
+		return start <= off && off < end
+	})
+	buf := &bytes.Buffer{}
+	buf.Write(src[:start])
+	buf.WriteString("\n")
+	buf.Write(decls)
+	buf.WriteString("\n")
+	buf.Write(src[end:])
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, mergePath, buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, spec := range imports {
+		astutil.AddNamedImport(fset, file, spec.name, spec.path)
+	}
+	names := selectorNames(file, func(token.Pos) bool { return true })
+	var stale []mergeImport
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var name string
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		switch name {
+		case "_", ".":
+			continue // keep blank and dot imports.
+		}
+		used := name
+		if len(used) == 0 {
+			used = path.Base(importPath) // see importDecl.
+		}
+		if oldNames[used] && !names[used] {
+			stale = append(stale, mergeImport{name: name, path: importPath})
+		}
+	}
+	for _, spec := range stale {
+		astutil.DeleteNamedImport(fset, file, spec.name, spec.path)
+	}
+	out := &bytes.Buffer{}
+	if err := format.Node(out, fset, file); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return out.Bytes(), nil
+}
+
+// findMergeRegion locates the merge markers in the given source, and returns
+// the offset directly after the begin marker line and the offset of the start
+// of the end marker line.
+func findMergeRegion(src []byte) (start, end int, err error) {
+	start, end = -1, -1
+	for off := 0; off < len(src); {
+		lineEnd := bytes.IndexByte(src[off:], '\n')
+		if lineEnd == -1 {
+			lineEnd = len(src)
+		} else {
+			lineEnd += off + 1
+		}
+		line := string(bytes.TrimSpace(src[off:lineEnd]))
+		switch line {
+		case mergeBegin:
+			if start != -1 {
+				return 0, 0, errors.Errorf("duplicate %q marker", mergeBegin)
+			}
+			start = lineEnd
+		case mergeEnd:
+			if start == -1 {
+				return 0, 0, errors.Errorf("%q marker before %q marker", mergeEnd, mergeBegin)
+			}
+			if end != -1 {
+				return 0, 0, errors.Errorf("duplicate %q marker", mergeEnd)
+			}
+			end = off
+		}
+		off = lineEnd
+	}
+	switch {
+	case start == -1:
+		return 0, 0, errors.Errorf("missing %q marker", mergeBegin)
+	case end == -1:
+		return 0, 0, errors.Errorf("missing %q marker", mergeEnd)
+	}
+	return start, end, nil
+}
+
+// mergeImport is an import of the generated source file.
+type mergeImport struct {
+	// import name; empty if unnamed.
+	name string
+	// import path.
+	path string
+}
+
+// splitDecls splits the given generated source file into the source of its
+// non-import declarations and the imports it uses.
+func splitDecls(src []byte) ([]byte, []mergeImport, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	var imports []mergeImport
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		imp := mergeImport{path: importPath}
+		if spec.Name != nil {
+			imp.name = spec.Name.Name
+		}
+		imports = append(imports, imp)
+	}
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			continue
+		}
+		pos := decl.Pos()
+		if doc := declDoc(decl); doc != nil {
+			pos = doc.Pos()
+		}
+		return src[fset.Position(pos).Offset:], imports, nil
+	}
+	return nil, imports, nil
+}
+
+// selectorNames returns the identifiers qualifying selector expressions (e.g.
+// "io" of io.Reader) at the positions accepted by the given filter of the
+// given file.
+func selectorNames(file *ast.File, filter func(pos token.Pos) bool) map[string]bool {
+	names := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && filter(sel.Pos()) {
+				names[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// declDoc returns the doc comment of the given declaration.
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch decl := decl.(type) {
+	case *ast.GenDecl:
+		return decl.Doc
+	case *ast.FuncDecl:
+		return decl.Doc
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// mockImportPath is the import path of the testify mock package used by
+// generated mocks.
+const mockImportPath = "github.com/stretchr/testify/mock"
+
+// mockFileSuffix is the suffix of the output file of generated mocks
+// (-gen-mocks).
+const mockFileSuffix = "_mocks_gen_test.go"
+
+// mockPath returns the output path of generated mocks for the given output
+// path of generated methods (e.g. "sdl/methods_mocks_gen_test.go" for
+// "sdl/methods.go"). In split and group-by-file mode, the output path is
+// interpreted as the output directory.
+func (gen *Gen) mockPath(output string) (string, error) {
+	if isMultiFile(gen.opts) {
+		if len(output) == 0 {
+			if len(gen.pkg.GoFiles) == 0 {
+				return "", errors.Errorf("unable to locate directory of package %q", gen.pkg.PkgPath)
+			}
+			output = filepath.Dir(gen.pkg.GoFiles[0])
+		}
+		return filepath.Join(output, "methods"+mockFileSuffix), nil
+	}
+	if len(output) == 0 {
+		return "", errors.New("mock generation (-gen-mocks) requires an output path (-o)")
+	}
+	return strings.TrimSuffix(output, ".go") + mockFileSuffix, nil
+}
+
+// printMocks writes testify mocks of the receiver types of the generated
+// methods to the mock output path of the given output path.
+func (gen *Gen) printMocks(output string) error {
+	mockPath, err := gen.mockPath(output)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	data, err := gen.mocksSource()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := gen.writeFile(mockPath, data); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// mocksSource returns the Go source of testify mocks (e.g. MockWindow) of the
+// receiver types of the generated methods, each with one method per generated
+// method of the receiver type, in order of first generated method.
+//
+// Example:
+//
+//	type MockWindow struct {
+//		mock.Mock
+//	}
+//
+//	func (m *MockWindow) GetSize(w, h *int32) bool {
+//		args := m.Called(w, h)
+//		return args.Bool(0)
+//	}
+func (gen *Gen) mocksSource() ([]byte, error) {
+	var decls []ast.Decl
+	var mockNames []string
+	mockMethods := make(map[string][]*Method)
+	mockTypes := make(map[string]types.Type)
+	for _, method := range gen.methods {
+		base := method.RecvType
+		if ptr, ok := base.(*types.Pointer); ok {
+			base = ptr.Elem()
+		}
+		named, ok := base.(*types.Named)
+		if !ok {
+			continue
+		}
+		mockName := "Mock" + strings.ToUpper(named.Obj().Name()[:1]) + named.Obj().Name()[1:]
+		if prev, ok := mockTypes[mockName]; !ok {
+			if gen.isDeclared(mockName) {
+				return nil, errors.Errorf("mock %q of %v already declared in package %q", mockName, base, gen.pkg.PkgPath)
+			}
+			mockTypes[mockName] = base
+			mockNames = append(mockNames, mockName)
+		} else if !types.Identical(prev, base) {
+			return nil, errors.Errorf("mocks of %v and %v share name %q", prev, base, mockName)
+		}
+		mockMethods[mockName] = append(mockMethods[mockName], method)
+	}
+	for _, mockName := range mockNames {
+		decls = append(decls, mockTypeDecl(mockName))
+		for _, method := range mockMethods[mockName] {
+			decls = append(decls, gen.mockMethod(mockName, method))
+		}
+	}
+	pkgName, err := gen.pkgName()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	file := &ast.File{
+		Name: ast.NewIdent(pkgName),
+	}
+	// mocks are written to a separate file; thus the testify import is added
+	// to a copy of the imports of the generated methods.
+	imports := maps.Clone(gen.imports)
+	imports[mockImportPath] = true
+	if importDecl := gen.importDeclOf(imports, decls); importDecl != nil {
+		file.Decls = append(file.Decls, importDecl)
+	}
+	file.Decls = append(file.Decls, decls...)
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s\n", pre)
+	// mock methods are synthesized without source positions (see
+	// copyFieldList).
+	if err := format.Node(buf, token.NewFileSet(), file); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	data, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
+
+// mockTypeDecl returns the type declaration of the given testify mock.
+func mockTypeDecl(mockName string) *ast.GenDecl {
+	return &ast.GenDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// " + mockName + " is a testify mock with the generated methods."},
+			},
+		},
+		Tok: token.TYPE,
+		Specs: []ast.Spec{
+			&ast.TypeSpec{
+				Name: ast.NewIdent(mockName),
+				Type: &ast.StructType{
+					Fields: &ast.FieldList{
+						List: []*ast.Field{
+							{
+								Type: &ast.SelectorExpr{
+									X:   ast.NewIdent("mock"),
+									Sel: ast.NewIdent("Mock"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// mockMethod returns the testify mock method of the given generated method,
+// recording the call and returning the configured return values.
+func (gen *Gen) mockMethod(mockName string, method *Method) *ast.FuncDecl {
+	used := make(map[string]bool)
+	var args []ast.Expr
+	for _, param := range flatParams(method.Decl.Type.Params) {
+		used[param.name.Name] = true
+		args = append(args, ast.NewIdent(param.name.Name))
+	}
+	uniqueName := func(name string) string {
+		for used[name] {
+			name += "_"
+		}
+		used[name] = true
+		return name
+	}
+	recvName := uniqueName("m")
+	called := &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   ast.NewIdent(recvName),
+			Sel: ast.NewIdent("Called"),
+		},
+		Args: args,
+	}
+	var stmts []ast.Stmt
+	results := method.Decl.Type.Results
+	if results == nil || len(results.List) == 0 {
+		stmts = append(stmts, &ast.ExprStmt{X: called})
+	} else {
+		argsName := uniqueName("args")
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(argsName)},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{called},
+		})
+		ret := &ast.ReturnStmt{}
+		for i, field := range flatResults(results) {
+			index := &ast.BasicLit{Kind: token.INT, Value: fmt.Sprint(i)}
+			getter := func(name string) ast.Expr {
+				return &ast.CallExpr{
+					Fun: &ast.SelectorExpr{
+						X:   ast.NewIdent(argsName),
+						Sel: ast.NewIdent(name),
+					},
+					Args: []ast.Expr{index},
+				}
+			}
+			if name, ok := mockGetter(gen.pkg.TypesInfo.TypeOf(field.Type)); ok {
+				ret.Results = append(ret.Results, getter(name))
+				continue
+			}
+			// comma-ok type assertion, as nil return values (e.g. of pointer
+			// types) are not assertable.
+			resultName := uniqueName(fmt.Sprintf("r%d", i))
+			stmts = append(stmts, &ast.AssignStmt{
+				Lhs: []ast.Expr{ast.NewIdent(resultName), ast.NewIdent("_")},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.TypeAssertExpr{X: getter("Get"), Type: copyExpr(field.Type)}},
+			})
+			ret.Results = append(ret.Results, ast.NewIdent(resultName))
+		}
+		stmts = append(stmts, ret)
+	}
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// " + method.Decl.Name.Name + " records a call of the mocked method."},
+			},
+		},
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{ast.NewIdent(recvName)},
+					Type:  &ast.StarExpr{X: ast.NewIdent(mockName)},
+				},
+			},
+		},
+		Name: ast.NewIdent(method.Decl.Name.Name),
+		Type: &ast.FuncType{
+			TypeParams: copyFieldList(method.Decl.Type.TypeParams),
+			Params:     copyFieldList(method.Decl.Type.Params),
+			Results:    copyFieldList(method.Decl.Type.Results),
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}
+}
+
+// copyFieldList returns a copy of the given field list without source
+// positions, as the parameter lists of generated methods mix source and
+// synthetic positions (e.g. of injected context parameters), which misplaces
+// comments when printed as part of another file.
+func copyFieldList(list *ast.FieldList) *ast.FieldList {
+	if list == nil {
+		return nil
+	}
+	newList := &ast.FieldList{}
+	for _, field := range list.List {
+		newField := &ast.Field{Type: copyExpr(field.Type)}
+		for _, name := range field.Names {
+			newField.Names = append(newField.Names, ast.NewIdent(name.Name))
+		}
+		newList.List = append(newList.List, newField)
+	}
+	return newList
+}
+
+// copyExpr returns a copy of the given type expression without source
+// positions.
+func copyExpr(expr ast.Expr) ast.Expr {
+	// variadic parameter types (e.g. `...int`) are not expressions by their
+	// own.
+	if ellipsis, ok := expr.(*ast.Ellipsis); ok {
+		return &ast.Ellipsis{Elt: copyExpr(ellipsis.Elt)}
+	}
+	s := types.ExprString(expr)
+	newExpr, err := parser.ParseExpr(s)
+	if err != nil {
+		panic(fmt.Errorf("unable to parse type expression %q: %v", s, err))
+	}
+	return stripPos(newExpr)
+}
+
+// mockGetter returns the name of the typed getter of testify mock.Arguments
+// for the given result type (e.g. "Bool" for bool), and reports whether the
+// result type has a typed getter.
+func mockGetter(typ types.Type) (string, bool) {
+	if typ == nil {
+		return "", false
+	}
+	if types.Identical(typ, types.Universe.Lookup("error").Type()) {
+		return "Error", true
+	}
+	basic, ok := typ.(*types.Basic)
+	if !ok {
+		return "", false
+	}
+	switch basic.Kind() {
+	case types.Bool:
+		return "Bool", true
+	case types.Int:
+		return "Int", true
+	case types.String:
+		return "String", true
+	}
+	return "", false
+}
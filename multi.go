@@ -0,0 +1,131 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// isMultiPkg reports whether the given package path specifies multiple
+// packages, either as a comma-separated list or as a package pattern (e.g.
+// "./...").
+func isMultiPkg(pkgPath string) bool {
+	return strings.Contains(pkgPath, ",") || strings.Contains(pkgPath, "...")
+}
+
+// genMultiPkg generates methods for each package of the given comma-separated
+// list of package paths or patterns. The output path is interpreted as the
+// file name of the generated methods file within each package directory.
+//
+// Packages are generated concurrently, bounded by opts.Jobs. Errors and
+// statistics of all packages are aggregated.
+func genMultiPkg(pkgPath, output string, opts *GenOptions) (*GenerationStats, error) {
+	if len(opts.OutputPattern) > 0 {
+		if len(output) > 0 {
+			return nil, errors.New("output file name (-o) and output pattern (-output-pattern) are mutually exclusive")
+		}
+	} else if len(output) == 0 {
+		return nil, errors.New("multi-package mode requires an output file name (-o) or output pattern (-output-pattern)")
+	}
+	if len(output) > 0 && filepath.Base(output) != output {
+		return nil, errors.Errorf("multi-package mode requires an output file name without directory (-o); got %q", output)
+	}
+	pkgs, err := loadPkgs(strings.Split(pkgPath, ","), opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(opts.SinceCommit) > 0 {
+		changed, err := changedFiles(opts.SinceCommit)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		pkgs = slices.DeleteFunc(pkgs, func(pkg *packages.Package) bool {
+			return !pkgChanged(pkg, changed)
+		})
+	}
+	stats := &GenerationStats{}
+	jobs := max(1, opts.Jobs)
+	sem := make(chan struct{}, jobs)
+	errs := make([]error, len(pkgs))
+	var wg sync.WaitGroup
+	for i, pkg := range pkgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = genPkg(pkg, output, opts, stats)
+		}()
+	}
+	wg.Wait()
+	var merr multiError
+	for _, err := range errs {
+		if err != nil {
+			merr = append(merr, err)
+		}
+	}
+	if len(merr) > 0 {
+		return nil, merr
+	}
+	return stats, nil
+}
+
+// genPkg generates methods for the given loaded package, writing them to the
+// output file name within the package directory. Statistics of the generation
+// are added to stats.
+func genPkg(pkg *packages.Package, output string, opts *GenOptions, stats *GenerationStats) error {
+	if len(pkg.GoFiles) == 0 {
+		clog.Warnf("skipping package %q without Go files", pkg.PkgPath)
+		return nil
+	}
+	output = filepath.Join(filepath.Dir(pkg.GoFiles[0]), output)
+	if len(opts.OutputPattern) > 0 {
+		var err error
+		if output, err = pkgOutput(pkg, opts); err != nil {
+			return errors.Wrapf(err, "unable to locate output file of package %q", pkg.PkgPath)
+		}
+	}
+	gen, err := newGenFromPkg(pkg, output, opts)
+	if err != nil {
+		return errors.Wrapf(err, "unable to generate methods of package %q", pkg.PkgPath)
+	}
+	stats.add(&gen.Stats)
+	if len(gen.methods) == 0 {
+		clog.Infof("skipping package %q without generated methods", pkg.PkgPath)
+		return nil
+	}
+	if err := gen.printMethods(output); err != nil {
+		return errors.Wrapf(err, "unable to write methods of package %q", pkg.PkgPath)
+	}
+	return nil
+}
+
+// loadPkgs loads the packages of the given package paths or patterns, using
+// the load options (e.g. build tags) of the specified generation options.
+func loadPkgs(patterns []string, opts *GenOptions) ([]*packages.Package, error) {
+	pkgPath := strings.Join(patterns, ",")
+	pkgs, err := loadPackages(packagesConfig(opts), opts, pkgPath, patterns...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := checkPkgs(pkgPath, pkgs); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pkgs, nil
+}
+
+// multiError is an aggregate of errors.
+type multiError []error
+
+func (merr multiError) Error() string {
+	var msgs []string
+	for _, err := range merr {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
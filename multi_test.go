@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newMultiPkgModule writes a synthetic module of the given number of packages
+// with the given number of functions on *Window each into a temporary
+// directory, and changes the working directory to the module for the
+// remainder of the test. The module directory is returned.
+func newMultiPkgModule(tb testing.TB, npkgs, nfuncs int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/multi\n\ngo 1.23\n"), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	for i := 0; i < npkgs; i++ {
+		pkgName := fmt.Sprintf("pkg%d", i)
+		buf := &bytes.Buffer{}
+		fmt.Fprintf(buf, "package %s\n\n", pkgName)
+		fmt.Fprintf(buf, "// Window is a window.\ntype Window struct{ id int32 }\n")
+		for j := 0; j < nfuncs; j++ {
+			fmt.Fprintf(buf, "\n// SetWindowProp%d sets property %d of the window.\n", j, j)
+			fmt.Fprintf(buf, "func SetWindowProp%d(window *Window, value int32) bool { return true }\n", j)
+		}
+		pkgDir := filepath.Join(dir, pkgName)
+		if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, pkgName+".go"), buf.Bytes(), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			tb.Fatal(err)
+		}
+	})
+	return dir
+}
+
+// multiPkgOptions returns the generation options of synthetic multi-package
+// modules, using the given number of concurrent jobs.
+func multiPkgOptions(jobs int) *GenOptions {
+	return &GenOptions{
+		Jobs:   jobs,
+		Config: &Config{Types: []string{"*.Window"}},
+	}
+}
+
+func TestGenMultiPkg(t *testing.T) {
+	const npkgs, nfuncs = 8, 4
+	golden := []struct {
+		name string
+		jobs int
+	}{
+		{name: "sequential", jobs: 1},
+		{name: "concurrent", jobs: npkgs},
+	}
+	// generated source of the first package, by number of jobs.
+	var srcs [][]byte
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newMultiPkgModule(t, npkgs, nfuncs)
+			stats, err := genMethods("./...", "methods_gen.go", multiPkgOptions(g.jobs))
+			if err != nil {
+				t.Fatalf("unable to generate methods; %+v", err)
+			}
+			if want := int64(npkgs * nfuncs); stats.MethodsGenerated != want {
+				t.Errorf("number of generated methods mismatch; expected %d, got %d", want, stats.MethodsGenerated)
+			}
+			checkCompiles(t, dir)
+			for i := 0; i < npkgs; i++ {
+				src, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("pkg%d", i), "methods_gen.go"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := parseGenerated(t, src)
+				const key = "(*Window).SetWindowProp0"
+				if method, want := got.method(t, key), "func (window *Window) SetWindowProp0(value int32) bool { return SetWindowProp0(window, value) }"; method != want {
+					t.Errorf("method %s of package pkg%d mismatch; expected %q, got %q", key, i, want, method)
+				}
+				if i == 0 {
+					srcs = append(srcs, src)
+				}
+			}
+		})
+	}
+	// output is deterministic regardless of scheduling.
+	if len(srcs) == 2 && !bytes.Equal(srcs[0], srcs[1]) {
+		t.Errorf("generated source mismatch between sequential and concurrent generation\n%s\n%s", srcs[0], srcs[1])
+	}
+}
+
+func BenchmarkGenMultiPkg(b *testing.B) {
+	const npkgs, nfuncs = 16, 64
+	for _, jobs := range []int{1, npkgs} {
+		b.Run(fmt.Sprintf("j=%d", jobs), func(b *testing.B) {
+			dir := newMultiPkgModule(b, npkgs, nfuncs)
+			opts := multiPkgOptions(jobs)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := genMethods("./...", "methods_gen.go", opts); err != nil {
+					b.Fatalf("unable to generate methods; %+v", err)
+				}
+				// remove generated files, so they are not loaded by the next
+				// iteration.
+				b.StopTimer()
+				for j := 0; j < npkgs; j++ {
+					if err := os.Remove(filepath.Join(dir, fmt.Sprintf("pkg%d", j), "methods_gen.go")); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.StartTimer()
+			}
+		})
+	}
+}
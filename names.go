@@ -0,0 +1,233 @@
+package main
+
+import (
+	"go/types"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// acronyms specifies the default acronyms kept all-caps when converting
+// snake_case function names to CamelCase method names.
+var acronyms = []string{
+	"API",
+	"GL",
+	"GPU",
+	"HTTP",
+	"ID",
+	"IO",
+	"RGB",
+	"RGBA",
+	"SDL",
+	"URL",
+	"UTF8",
+}
+
+// camelCase converts the given snake_case function name to CamelCase (e.g.
+// "render_clear" to "RenderClear" and "sdl_get_io" to "SDLGetIO"). Names
+// without underscores are returned unchanged.
+func (gen *Gen) camelCase(funcName string) string {
+	if !strings.Contains(funcName, "_") {
+		return funcName
+	}
+	isAcronym := make(map[string]bool)
+	for _, acronym := range acronyms {
+		isAcronym[acronym] = true
+	}
+	if config := gen.opts.Config; config != nil {
+		for _, acronym := range config.Acronyms {
+			isAcronym[strings.ToUpper(acronym)] = true
+		}
+	}
+	var sb strings.Builder
+	for _, part := range strings.Split(funcName, "_") {
+		if len(part) == 0 {
+			continue
+		}
+		if upper := strings.ToUpper(part); isAcronym[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]))
+		sb.WriteString(part[1:])
+	}
+	if sb.Len() == 0 {
+		return funcName
+	}
+	return sb.String()
+}
+
+// snakeCase converts the given CamelCase name to snake_case (e.g. "GPUDevice"
+// to "gpu_device").
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// renameRule is a sed-style substitution rule applied to method names
+// (-rename-rule), e.g. `s/^SDL_//`.
+type renameRule struct {
+	// pattern of names to substitute.
+	re *regexp.Regexp
+	// replacement, in the template syntax of regexp.Regexp.Expand.
+	repl string
+	// substitute all matches, not only the first (g flag).
+	global bool
+}
+
+// parseRenameRule parses the given sed-style substitution rule of the form
+// `s/pattern/replacement/flags`, where any character may be used as delimiter
+// in place of `/`. The pattern is in the regexp syntax of Go. In the
+// replacement, `\1` through `\9` refer to capture groups and `&` to the
+// entire match, as in sed. The supported flags are g (substitute all matches)
+// and i (case-insensitive matching).
+func parseRenameRule(spec string) (*renameRule, error) {
+	if len(spec) < 2 || spec[0] != 's' {
+		return nil, errors.Errorf("invalid rename rule %q; expected s/pattern/replacement/", spec)
+	}
+	delim, size := utf8.DecodeRuneInString(spec[1:])
+	if delim == '\\' || delim == '\n' || unicode.IsLetter(delim) || unicode.IsDigit(delim) {
+		return nil, errors.Errorf("invalid delimiter %q of rename rule %q", delim, spec)
+	}
+	// split on unescaped delimiters; escaped delimiters are unescaped.
+	var parts []string
+	part := &strings.Builder{}
+	rest := spec[1+size:]
+	for len(rest) > 0 {
+		r, n := utf8.DecodeRuneInString(rest)
+		switch {
+		case r == '\\' && strings.HasPrefix(rest[n:], string(delim)):
+			part.WriteRune(delim)
+			n += size
+		case r == '\\' && len(rest) > n:
+			// keep other escapes (e.g. `\d` or `\1`) as is.
+			next, m := utf8.DecodeRuneInString(rest[n:])
+			part.WriteRune(r)
+			part.WriteRune(next)
+			n += m
+		case r == delim:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteRune(r)
+		}
+		rest = rest[n:]
+	}
+	if len(parts) != 2 {
+		return nil, errors.Errorf("invalid rename rule %q; expected s/pattern/replacement/", spec)
+	}
+	pattern, flags := parts[0], part.String()
+	rule := &renameRule{repl: sedReplacement(parts[1])}
+	for _, flag := range flags {
+		switch flag {
+		case 'g':
+			rule.global = true
+		case 'i':
+			pattern = "(?i)" + pattern
+		default:
+			return nil, errors.Errorf("invalid flag %q of rename rule %q; expected g or i", flag, spec)
+		}
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern of rename rule %q", spec)
+	}
+	rule.re = re
+	return rule, nil
+}
+
+// sedReplacement converts the given sed replacement to the template syntax of
+// regexp.Regexp.Expand (e.g. `\1_&` to `${1}_${0}`).
+func sedReplacement(repl string) string {
+	buf := &strings.Builder{}
+	for i := 0; i < len(repl); i++ {
+		switch c := repl[i]; {
+		case c == '\\' && i+1 < len(repl) && '0' <= repl[i+1] && repl[i+1] <= '9':
+			buf.WriteString("${" + string(repl[i+1]) + "}")
+			i++
+		case c == '\\' && i+1 < len(repl):
+			// escaped character (e.g. `\&`).
+			if repl[i+1] == '$' {
+				buf.WriteString("$$")
+			} else {
+				buf.WriteByte(repl[i+1])
+			}
+			i++
+		case c == '&':
+			buf.WriteString("${0}")
+		case c == '$':
+			buf.WriteString("$$")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+// apply applies the rename rule to the given name.
+func (rule *renameRule) apply(name string) string {
+	if rule.global {
+		return rule.re.ReplaceAllString(name, rule.repl)
+	}
+	loc := rule.re.FindStringSubmatchIndex(name)
+	if loc == nil {
+		return name
+	}
+	dst := rule.re.ExpandString(nil, rule.repl, name, loc)
+	return name[:loc[0]] + string(dst) + name[loc[1]:]
+}
+
+// applyRenameRules applies the rename rules of the generation options
+// (-rename-rule) in order to the given method name, and reports whether the
+// name was changed.
+func (gen *Gen) applyRenameRules(methodName string) (string, bool) {
+	newMethodName := methodName
+	for _, rule := range gen.renameRules {
+		newMethodName = rule.apply(newMethodName)
+	}
+	return newMethodName, newMethodName != methodName
+}
+
+// defaultConstructorPrefixes specifies the default name prefixes of
+// constructors replaced by "New" in the names of generated constructors
+// (-gen-constructors).
+var defaultConstructorPrefixes = []string{"Create"}
+
+// constructorName returns the name of the generated constructor forwarding to
+// the given constructor returning the given type (-gen-constructors). A name
+// prefix configured for the result type, or otherwise for all types ("*"), is
+// replaced by "New" (e.g. "CreateWindow" to "NewWindow" by default, or
+// "OpenCamera" to "NewCamera" with prefix "Open"). Names without such a prefix
+// followed by an upper-case letter are returned unchanged.
+func (gen *Gen) constructorName(funcName string, resultType types.Type) string {
+	prefixes := defaultConstructorPrefixes
+	if config := gen.opts.Config; config != nil {
+		if typePrefixes, ok := config.ConstructorPrefixes[resultType.String()]; ok {
+			prefixes = typePrefixes
+		} else if allPrefixes, ok := config.ConstructorPrefixes["*"]; ok {
+			prefixes = allPrefixes
+		}
+	}
+	for _, prefix := range prefixes {
+		rest, ok := strings.CutPrefix(funcName, prefix)
+		if r, _ := utf8.DecodeRuneInString(rest); !ok || !unicode.IsUpper(r) {
+			continue // e.g. "Created" of prefix "Create".
+		}
+		return "New" + rest
+	}
+	return funcName
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestParseRenameRule(t *testing.T) {
+	golden := []struct {
+		spec string
+		in   string
+		// expected name; empty if invalid rule.
+		want string
+		// expected substring of error.
+		err string
+	}{
+		{spec: `s/^SDL_//`, in: "SDL_GetWindowSize", want: "GetWindowSize"},
+		{spec: `s/^(Get|Set)Window(.+)$/\1\2/`, in: "SetWindowTitle", want: "SetTitle"},
+		{spec: `s/^(Get|Set)Window(.+)$/\2\1/`, in: "GetWindowTitle", want: "TitleGet"},
+		{spec: `s/Window/&s/`, in: "GetWindowTitle", want: "GetWindowsTitle"},
+		{spec: `s/Window/\&/`, in: "GetWindowTitle", want: "Get&Title"},
+		{spec: `s/o/0/`, in: "GetWindowColor", want: "GetWind0wColor"},
+		{spec: `s/o/0/g`, in: "GetWindowColor", want: "GetWind0wC0l0r"},
+		{spec: `s/window/Win/i`, in: "GetWindowTitle", want: "GetWinTitle"},
+		// literal $ in replacement, as in sed.
+		{spec: `s/(?P<verb>Get)Window/${verb}/`, in: "GetWindowTitle", want: "${verb}Title"},
+		{spec: `s|^Create(.*)$|New\1|`, in: "CreateRenderer", want: "NewRenderer"},
+		{spec: `s,a\,b,c,`, in: "a,b", want: "c"},
+		{spec: `s/^Get(\w+)Size$/\1Extent/`, in: "GetWindowSize", want: "WindowExtent"},
+		{spec: `s/x//`, in: "GetWindowSize", want: "GetWindowSize"},
+		{spec: `SDL_//`, err: "expected s/pattern/replacement/"},
+		{spec: `s/SDL_/`, err: "expected s/pattern/replacement/"},
+		{spec: `s/SDL_//x`, err: `invalid flag 'x'`},
+		{spec: `sa^SDL_aa`, err: "invalid delimiter"},
+		{spec: `s/(SDL_//`, err: "invalid pattern of rename rule"},
+	}
+	for _, g := range golden {
+		t.Run(g.spec, func(t *testing.T) {
+			rule, err := parseRenameRule(g.spec)
+			if len(g.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), g.err) {
+					t.Errorf("error mismatch; expected %q, got %v", g.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to parse rename rule; %v", err)
+			}
+			if got := rule.apply(g.in); got != g.want {
+				t.Errorf("name mismatch; expected %q, got %q", g.want, got)
+			}
+		})
+	}
+}
+
+func TestRenameRules(t *testing.T) {
+	golden := []struct {
+		name  string
+		rules []string
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "capture groups",
+			rules: []string{
+				`s/^(Get|Set)Window(.+)$/\1\2/`,
+				`s|^Create(.*)$|New\1|`,
+			},
+			want: map[string]string{
+				"(*Window).SetTitle":    "func (window *Window) SetTitle(title string) bool { return SetWindowTitle(window, title) }",
+				"(*Window).GetTitle":    "func (window *Window) GetTitle() string {\n\treturn GetWindowTitle(window)\n}",
+				"(*Window).NewRenderer": "func (window *Window) NewRenderer(name string) (*Renderer, error) {\n\treturn CreateRenderer(window, name)\n}",
+				// applied after built-in renames (of GetWindowSize).
+				"(*Window).GetSize": "func (window *Window) GetSize(w, h *int32) bool { return GetWindowSize(window, w, h) }",
+			},
+		},
+		{
+			name: "applied in order",
+			rules: []string{
+				`s/^(Get|Set)Window(.+)$/\1\2/`,
+				`s/^Get(.+)$/\1/`,
+				`s/size/Extent/gi`,
+				`s/^Destroy$/&Now/`,
+			},
+			want: map[string]string{
+				"(*Window).Title":            "func (window *Window) Title() string {\n\treturn GetWindowTitle(window)\n}",
+				"(*Window).Opacity":          "func (window *Window) Opacity() float32 {\n\treturn GetWindowOpacity(window)\n}",
+				"(*Window).Extent":           "func (window *Window) Extent(w, h *int32) bool { return GetWindowSize(window, w, h) }",
+				"(*Window).SetMinimumExtent": "func (window *Window) SetMinimumExtent(w, h int32) bool { return SetWindowMinimumSize(window, w, h) }",
+				"(*Window).DestroyNow":       "func (window *Window) DestroyNow() {\n\tDestroyWindow(window)\n}",
+				"(*Renderer).DestroyNow":     "func (renderer *Renderer) DestroyNow() {\n\tDestroyRenderer(renderer)\n}",
+			},
+		},
+		{
+			name: "config rename",
+			rules: []string{
+				`s/^Set(.+)$/Change\1/`,
+			},
+			want: map[string]string{
+				// applied to config renames.
+				"(*Window).ChangeTitle": "func (window *Window) ChangeTitle(title string) bool { return SetWindowTitle(window, title) }",
+				// directives are not affected.
+				"(*Window).Resize": "func (window *Window) Resize(w, h int32) bool { return ResizeWindow(window, w, h) }",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			opts := &GenOptions{
+				RenameRules: g.rules,
+				Config:      &Config{Rename: map[string]string{"SetWindowTitle": "SetTitle", "ResizeWindow": "SetSize"}},
+			}
+			genFixture(t, "sdl", opts).checkMethods(t, g.want)
+		})
+	}
+}
+
+func TestConstructorName(t *testing.T) {
+	sdlPkg := types.NewPackage(fixturePkgPath, "sdl")
+	newType := func(name string) types.Type {
+		return types.NewPointer(types.NewNamed(types.NewTypeName(0, sdlPkg, name, nil), types.NewStruct(nil, nil), nil))
+	}
+	windowType, cameraType := newType("Window"), newType("Camera")
+	golden := []struct {
+		name     string
+		prefixes map[string][]string
+		funcName string
+		typ      types.Type
+		want     string
+	}{
+		{name: "default", funcName: "CreateWindow", typ: windowType, want: "NewWindow"},
+		{name: "default prefix only", funcName: "CreatedWindow", typ: windowType, want: "CreatedWindow"},
+		{name: "default other prefix", funcName: "OpenCamera", typ: cameraType, want: "OpenCamera"},
+		{name: "default new", funcName: "NewWindow", typ: windowType, want: "NewWindow"},
+		{
+			name:     "type prefix",
+			prefixes: map[string][]string{"*" + fixturePkgPath + ".Camera": {"Open"}},
+			funcName: "OpenCamera",
+			typ:      cameraType,
+			want:     "NewCamera",
+		},
+		{
+			name:     "type prefix of other type",
+			prefixes: map[string][]string{"*" + fixturePkgPath + ".Camera": {"Open"}},
+			funcName: "CreateWindow",
+			typ:      windowType,
+			want:     "NewWindow",
+		},
+		{
+			name:     "global prefixes",
+			prefixes: map[string][]string{"*": {"Create", "Open"}},
+			funcName: "OpenCamera",
+			typ:      cameraType,
+			want:     "NewCamera",
+		},
+		{
+			name:     "type prefix overrides global prefixes",
+			prefixes: map[string][]string{"*": {"Open"}, "*" + fixturePkgPath + ".Window": {"Create"}},
+			funcName: "OpenWindow",
+			typ:      windowType,
+			want:     "OpenWindow",
+		},
+		{
+			name:     "kept names",
+			prefixes: map[string][]string{"*": {}},
+			funcName: "CreateWindow",
+			typ:      windowType,
+			want:     "CreateWindow",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			gen := &Gen{opts: &GenOptions{Config: &Config{ConstructorPrefixes: g.prefixes}}}
+			if got := gen.constructorName(g.funcName, g.typ); got != g.want {
+				t.Errorf("constructor name of %q mismatch; expected %q, got %q", g.funcName, g.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/mewpkg/clog"
+)
+
+// defaultOverlayName is the file name of the generated methods file within the
+// package directory, as used by Overlay when no output path is specified.
+const defaultOverlayName = "methods.go"
+
+// Overlay returns the generated methods file as an overlay which may be passed
+// to packages.Config.Overlay, mapping the absolute path of the output file to
+// its would-be contents, without touching the file system. If no output path
+// was specified, "methods.go" within the package directory is used.
+//
+// Overlay returns nil if the output file could not be generated.
+func (gen *Gen) Overlay() map[string][]byte {
+	output := gen.output
+	if len(output) == 0 {
+		if len(gen.pkg.GoFiles) == 0 {
+			clog.Warnf("unable to locate directory of package %q", gen.pkg.PkgPath)
+			return nil
+		}
+		output = filepath.Join(filepath.Dir(gen.pkg.GoFiles[0]), defaultOverlayName)
+	}
+	absOutput, err := filepath.Abs(output)
+	if err != nil {
+		clog.Warnf("unable to resolve absolute path of %q: %v", output, err)
+		return nil
+	}
+	data, err := gen.outputSource()
+	if err != nil {
+		clog.Warnf("unable to generate overlay of %q: %+v", absOutput, err)
+		return nil
+	}
+	return map[string][]byte{
+		absOutput: data,
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"go/types"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// OutputPatternData is the data of output path templates (-output-pattern).
+type OutputPatternData struct {
+	// package directory (e.g. "/home/u/purego-sdl3/sdl").
+	PkgDir string
+	// package name (e.g. "sdl").
+	PkgName string
+	// base receiver type name (e.g. "GPUDevice"); empty outside split mode.
+	TypeName string
+	// lowercase base receiver type name (e.g. "gpudevice"); empty outside split
+	// mode.
+	TypeShortName string
+}
+
+// patternOutput returns the output path of the methods of the given package
+// on the given receiver type (or all receiver types if nil), as specified by
+// the given output path template in Go template syntax (e.g.
+// `{{.PkgDir}}/{{.TypeShortName}}_gen.go`).
+func patternOutput(pattern string, pkg *packages.Package, recvType types.Type) (string, error) {
+	t, err := template.New("output-pattern").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid output pattern %q", pattern)
+	}
+	data := OutputPatternData{
+		PkgName: pkg.Name,
+	}
+	if len(pkg.GoFiles) > 0 {
+		data.PkgDir = filepath.Dir(pkg.GoFiles[0])
+	}
+	if recvType != nil {
+		data.TypeName = typeName(recvType)
+		data.TypeShortName = strings.ToLower(data.TypeName)
+	}
+	buf := &strings.Builder{}
+	if err := t.Execute(buf, data); err != nil {
+		return "", errors.Wrapf(err, "unable to evaluate output pattern %q", pattern)
+	}
+	output := filepath.Clean(buf.String())
+	if !strings.HasSuffix(output, ".go") || strings.HasSuffix(output, string(filepath.Separator)+".go") {
+		return "", errors.Errorf("invalid output pattern %q; expected path of Go file, got %q", pattern, output)
+	}
+	return output, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"go/ast"
+	"plugin"
+
+	"github.com/pkg/errors"
+)
+
+// pluginSymbol is the symbol name of the method body generator of Go plugins
+// (-plugin).
+const pluginSymbol = "GenMethodBody"
+
+// loadPlugin loads the method body generator of the Go plugin at the given path
+// (-plugin). The plugin must export a GenMethodBody function (or variable of
+// function type) of the type of GenOptions.MethodBody, which is given the
+// source function and the default forwarding statement of each generated
+// method.
+//
+// Example:
+//
+//	package main
+//
+//	func GenMethodBody(funcDecl *ast.FuncDecl, stmt ast.Stmt) ast.Stmt {
+//		return nil // keep default forwarding statement.
+//	}
+//
+// As plugins cannot import package main, the generator state (Gen) is not
+// accessible to plugins.
+func loadPlugin(path string) (func(*ast.FuncDecl, ast.Stmt) ast.Stmt, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open plugin %q", path)
+	}
+	sym, err := p.Lookup(pluginSymbol)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to locate symbol %s of plugin %q", pluginSymbol, path)
+	}
+	switch fn := sym.(type) {
+	case func(*ast.FuncDecl, ast.Stmt) ast.Stmt:
+		return fn, nil
+	case *func(*ast.FuncDecl, ast.Stmt) ast.Stmt:
+		if *fn == nil {
+			return nil, errors.Errorf("nil symbol %s of plugin %q", pluginSymbol, path)
+		}
+		return *fn, nil
+	}
+	return nil, errors.Errorf("invalid type %T of symbol %s of plugin %q; expected func(*ast.FuncDecl, ast.Stmt) ast.Stmt", sym, pluginSymbol, path)
+}
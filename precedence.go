@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"slices"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+)
+
+// Configuration sources, in order of precedence. Settings of a source with
+// higher precedence override settings of sources with lower precedence:
+//
+//	directive > flag > config file > default
+//
+// The method name of a function is determined by (in order):
+//
+//	//genmethods:name (or //genmethods:rename) directive
+//	-gen-deepcopy flag (DeepCopy methods)
+//	-rename-rule flags (applied to the name of the config file or default)
+//	stringers and rename sections of the config file
+//	default name (built-in renames, or converted by -sanitize-names)
+//
+// The inclusion of a function is determined by (in order):
+//
+//	//genmethods:skip and //genmethods:recv directives
+//	-skip-doc-keyword, result filter (e.g. -max-results) and -types flags
+//	variants and types sections of the config file
+//	built-in receiver types
+const (
+	sourceDirective = "directive"
+	sourceFlag      = "flag"
+	sourceConfig    = "config file"
+	sourceDefault   = "default"
+)
+
+// resolveMethodName returns the method name of the given function converted to
+// a method on the given receiver type, and the configuration source
+// determining the name (e.g. "config file (rename)").
+func (gen *Gen) resolveMethodName(decl *ast.FuncDecl, recvType types.Type) (string, string, error) {
+	funcName := decl.Name.String()
+	if methodName, directive, ok := nameDirective(decl); ok {
+		if !token.IsIdentifier(methodName) {
+			return "", "", errors.Errorf("invalid method name %q of directive on function %q", methodName, funcName)
+		}
+		return methodName, sourceDirective + " (//genmethods:" + directive + ")", nil
+	}
+	if gen.opts.GenDeepCopy && gen.isCopyFunc(decl, recvType) {
+		return "DeepCopy", sourceFlag + " (-gen-deepcopy)", nil
+	}
+	if gen.isStringerFunc(funcName) {
+		if err := gen.checkStringer(decl); err != nil {
+			clog.Warnf("unable to generate String method for function %q: %v", decl.Name, err)
+		} else {
+			return "String", sourceConfig + " (stringers)", nil
+		}
+	}
+	methodName := gen.methodName(funcName)
+	source := sourceDefault + " (function name)"
+	renamedName, _ := gen.renamedMethodName(funcName)
+	_, ruled := gen.applyRenameRules(renamedName)
+	switch _, ok := renameMethod[funcName]; {
+	case ruled:
+		source = sourceFlag + " (-rename-rule)"
+	case gen.opts.Config != nil && len(gen.opts.Config.Rename[funcName]) > 0:
+		source = sourceConfig + " (rename)"
+	case ok:
+		source = sourceDefault + " (built-in rename)"
+	case gen.opts.SanitizeNames:
+		source = sourceDefault + " (-sanitize-names)"
+	}
+	return methodName, source, nil
+}
+
+// inclusionSource returns the configuration source determining the inclusion
+// of the given function converted to a method on the given receiver type (e.g.
+// "flag (-types)").
+func (gen *Gen) inclusionSource(decl *ast.FuncDecl, recvType types.Type) string {
+	if _, ok := recvDirective(decl); ok {
+		return sourceDirective + " (//genmethods:recv)"
+	}
+	// match receiver types adapted to pointer or value receivers.
+	typStrs := []string{recvType.String()}
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		typStrs = append(typStrs, ptr.Elem().String())
+	} else {
+		typStrs = append(typStrs, types.NewPointer(recvType).String())
+	}
+	for _, typStr := range typStrs {
+		if gen.resolvedTypes[typStr] {
+			return sourceFlag + " (-types)"
+		}
+	}
+	if config := gen.opts.Config; config != nil {
+		for _, typStr := range typStrs {
+			if slices.ContainsFunc(config.Types, func(pattern string) bool {
+				return pattern == typStr || matchType(pattern, typStr)
+			}) {
+				return sourceConfig + " (types)"
+			}
+		}
+	}
+	return sourceDefault + " (built-in receiver types)"
+}
+
+// printExplain prints the configuration sources determining the name and
+// inclusion of each generated method to w, in order of generated methods.
+//
+// The output is line-oriented, with tab-separated entries:
+//
+//	(*Window).GetSize	GetWindowSize	name: default (built-in rename)	included: default (built-in receiver types)
+//	(*Window).SetTitle	SetWindowTitle	name: config file (rename)	included: default (built-in receiver types)
+func (gen *Gen) printExplain(w io.Writer) {
+	qualifier := types.RelativeTo(gen.pkg.Types)
+	sources := make(map[string]ParsedFunc)
+	for _, parsed := range gen.ParsedFuncs {
+		if !parsed.Skipped {
+			sources[parsed.Decl.Name.String()] = parsed
+		}
+	}
+	for _, method := range gen.methods {
+		parsed, ok := sources[method.Func.Name()]
+		if !ok {
+			continue
+		}
+		recvType := types.TypeString(method.RecvType, qualifier)
+		if isPointer(method.RecvType) {
+			recvType = "(" + recvType + ")"
+		}
+		fmt.Fprintf(w, "%s.%s\t%s\tname: %s\tincluded: %s\n", recvType, method.Decl.Name, method.Func.Name(), parsed.NameSource, parsed.InclusionSource)
+	}
+}
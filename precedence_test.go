@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPrecedence(t *testing.T) {
+	surfaceType := "*" + fixturePkgPath + ".Surface"
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected -explain lines.
+		want []string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: []string{
+				"(*Window).GetSize\tGetWindowSize\tname: default (built-in rename)\tincluded: default (built-in receiver types)",
+				"(*Window).SetWindowTitle\tSetWindowTitle\tname: default (function name)\tincluded: default (built-in receiver types)",
+				"(*Window).Resize\tResizeWindow\tname: directive (//genmethods:name)\tincluded: default (built-in receiver types)",
+			},
+		},
+		{
+			name: "config file over default",
+			opts: &GenOptions{
+				Config: &Config{
+					Rename: map[string]string{"GetWindowSize": "Size", "SetWindowTitle": "SetTitle", "ResizeWindow": "SetSize"},
+					Types:  []string{surfaceType},
+				},
+			},
+			want: []string{
+				"(*Window).Size\tGetWindowSize\tname: config file (rename)\tincluded: default (built-in receiver types)",
+				"(*Window).SetTitle\tSetWindowTitle\tname: config file (rename)\tincluded: default (built-in receiver types)",
+				// directive over config file.
+				"(*Window).Resize\tResizeWindow\tname: directive (//genmethods:name)\tincluded: default (built-in receiver types)",
+				"(*Surface).Lock\tLockSurface\tname: default (built-in rename)\tincluded: config file (types)",
+			},
+		},
+		{
+			name: "flag over config file",
+			opts: &GenOptions{
+				RenameRules: []string{`s/^Set(.*)$/Change\1/`},
+				Types:       []string{"*Surface"},
+				Config: &Config{
+					Rename: map[string]string{"SetWindowTitle": "SetTitle"},
+					Types:  []string{surfaceType},
+				},
+			},
+			want: []string{
+				"(*Window).ChangeTitle\tSetWindowTitle\tname: flag (-rename-rule)\tincluded: default (built-in receiver types)",
+				// rename rules do not affect directives.
+				"(*Window).Resize\tResizeWindow\tname: directive (//genmethods:name)\tincluded: default (built-in receiver types)",
+				"(*Surface).Lock\tLockSurface\tname: default (built-in rename)\tincluded: flag (-types)",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "sdl")
+			output := filepath.Join(dir, "sdl", "methods_gen.go")
+			gen, err := newGen(fixturePkgPath, output, g.opts)
+			if err != nil {
+				t.Fatalf("unable to generate methods of fixture; %+v", err)
+			}
+			buf := &bytes.Buffer{}
+			gen.printExplain(buf)
+			lines := strings.Split(buf.String(), "\n")
+			for _, want := range g.want {
+				if !slices.Contains(lines, want) {
+					t.Errorf("explain line %q not found\n%s", want, buf)
+				}
+			}
+			// explained methods are generated.
+			if err := gen.printMethods(output); err != nil {
+				t.Fatalf("unable to print methods; %+v", err)
+			}
+			checkCompiles(t, dir)
+			src, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := parseGenerated(t, src)
+			for _, want := range g.want {
+				key, _, _ := strings.Cut(want, "\t")
+				if !got.hasMethod(key) {
+					t.Errorf("method %s not generated; got %v", key, got.methods())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// prometheusImportPath is the import path of the Prometheus client package used
+// by generated metrics (-gen-metrics).
+const prometheusImportPath = "github.com/prometheus/client_golang/prometheus"
+
+// prometheusNameRE matches valid Prometheus metric names.
+var prometheusNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// promMetrics holds the variable names of the Prometheus metrics of a generated
+// method (-gen-metrics).
+type promMetrics struct {
+	// summary of call durations in seconds.
+	duration string
+	// counter of calls.
+	calls string
+	// counter of calls returning a non-nil error; empty if the method does not
+	// return an error.
+	errors string
+}
+
+// vars returns the variable names of the metrics, in order of declaration.
+func (metrics *promMetrics) vars() []string {
+	vars := []string{metrics.duration, metrics.calls}
+	if len(metrics.errors) > 0 {
+		vars = append(vars, metrics.errors)
+	}
+	return vars
+}
+
+// promMetricDecls returns the Prometheus metrics of the given method on the
+// given receiver type, and the declaration of the package-level metric
+// variables, registered by promInitDecl. Metrics are named
+// `<package>_<receiver>_<method>_*` in snake_case (e.g.
+// sdl_window_set_title_duration_seconds).
+//
+// Example:
+//
+//	var (
+//		promWindowSetTitleDuration = prometheus.NewSummary(prometheus.SummaryOpts{
+//			Name: "sdl_window_set_title_duration_seconds",
+//			Help: "Duration of (*Window).SetTitle calls in seconds.",
+//		})
+//		...
+//	)
+func (gen *Gen) promMetricDecls(recvType types.Type, methodName string, returnsError bool) (*promMetrics, ast.Decl, error) {
+	pkgName, err := gen.pkgName()
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	recvTypeName := typeName(recvType)
+	methodExpr := recvTypeName + "." + methodName
+	if isPointer(recvType) {
+		methodExpr = "(*" + recvTypeName + ")." + methodName
+	}
+	prefix := "prom" + recvTypeName + methodName
+	metricPrefix := pkgName + "_" + snakeCase(recvTypeName) + "_" + snakeCase(methodName)
+	metrics := &promMetrics{
+		duration: prefix + "Duration",
+		calls:    prefix + "Calls",
+	}
+	type metric struct {
+		varName, ctor, opts, name, help string
+	}
+	ms := []metric{
+		{metrics.duration, "NewSummary", "SummaryOpts", metricPrefix + "_duration_seconds", "Duration of " + methodExpr + " calls in seconds."},
+		{metrics.calls, "NewCounter", "CounterOpts", metricPrefix + "_calls_total", "Number of " + methodExpr + " calls."},
+	}
+	if returnsError {
+		metrics.errors = prefix + "Errors"
+		ms = append(ms, metric{metrics.errors, "NewCounter", "CounterOpts", metricPrefix + "_errors_total", "Number of " + methodExpr + " calls returning an error."})
+	}
+	gen.imports[prometheusImportPath] = true
+	prom := func(name string) *ast.SelectorExpr {
+		return &ast.SelectorExpr{X: ast.NewIdent("prometheus"), Sel: ast.NewIdent(name)}
+	}
+	varDecl := &ast.GenDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// Prometheus metrics of " + methodExpr + "."},
+			},
+		},
+		Tok:    token.VAR,
+		Lparen: 1, // parenthesized.
+	}
+	for _, m := range ms {
+		if gen.isDeclared(m.varName) {
+			return nil, nil, errors.Errorf("metric variable %q of method %q already declared in package %q", m.varName, methodName, gen.pkg.PkgPath)
+		}
+		if !prometheusNameRE.MatchString(m.name) {
+			return nil, nil, errors.Errorf("invalid Prometheus metric name %q of method %q", m.name, methodName)
+		}
+		opts := &ast.CompositeLit{
+			Type: prom(m.opts),
+			Elts: []ast.Expr{
+				&ast.KeyValueExpr{Key: ast.NewIdent("Name"), Value: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(m.name)}},
+				&ast.KeyValueExpr{Key: ast.NewIdent("Help"), Value: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(m.help)}},
+			},
+		}
+		varDecl.Specs = append(varDecl.Specs, &ast.ValueSpec{
+			Names:  []*ast.Ident{ast.NewIdent(m.varName)},
+			Values: []ast.Expr{&ast.CallExpr{Fun: prom(m.ctor), Args: []ast.Expr{opts}}},
+		})
+	}
+	return metrics, varDecl, nil
+}
+
+// promInitDecl returns the init function registering the Prometheus metrics of
+// the given generated methods with the default registry in a single
+// MustRegister call, or nil if no metrics are recorded.
+//
+// Example:
+//
+//	// Register Prometheus metrics of generated methods.
+//	func init() {
+//		prometheus.MustRegister(promWindowSetTitleDuration, promWindowSetTitleCalls, promWindowSetTitleErrors, promRendererClearDuration, promRendererClearCalls)
+//	}
+func promInitDecl(methods []*Method) ast.Decl {
+	register := &ast.CallExpr{
+		Fun: &ast.SelectorExpr{X: ast.NewIdent("prometheus"), Sel: ast.NewIdent("MustRegister")},
+	}
+	for _, method := range methods {
+		if method.metrics == nil {
+			continue
+		}
+		for _, varName := range method.metrics.vars() {
+			register.Args = append(register.Args, ast.NewIdent(varName))
+		}
+	}
+	if len(register.Args) == 0 {
+		return nil
+	}
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// Register Prometheus metrics of generated methods."},
+			},
+		},
+		Name: ast.NewIdent("init"),
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ExprStmt{X: register}}},
+	}
+}
+
+// callStmts returns the statements recording the call count and duration of
+// the generated method, run before forwarding.
+//
+//	promWindowSetTitleCalls.Inc()
+//	defer prometheus.NewTimer(promWindowSetTitleDuration).ObserveDuration()
+func (metrics *promMetrics) callStmts() []ast.Stmt {
+	incStmt := &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: ast.NewIdent(metrics.calls), Sel: ast.NewIdent("Inc")},
+		},
+	}
+	timer := &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: ast.NewIdent("prometheus"), Sel: ast.NewIdent("NewTimer")},
+		Args: []ast.Expr{ast.NewIdent(metrics.duration)},
+	}
+	deferStmt := &ast.DeferStmt{
+		Call: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: timer, Sel: ast.NewIdent("ObserveDuration")},
+		},
+	}
+	return []ast.Stmt{incStmt, deferStmt}
+}
+
+// errorStmt returns the statement counting non-nil errors of the given error
+// variable, returned by the forwarded call.
+//
+//	if err != nil {
+//		promWindowSetTitleErrors.Inc()
+//	}
+func (metrics *promMetrics) errorStmt(errName string) ast.Stmt {
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{X: ast.NewIdent(errName), Op: token.NEQ, Y: ast.NewIdent("nil")},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: ast.NewIdent(metrics.errors), Sel: ast.NewIdent("Inc")},
+					},
+				},
+			},
+		},
+	}
+}
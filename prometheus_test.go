@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+func TestGenMetrics(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected generated source.
+		want string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{},
+			want: `// Code generated by "genmethods"; DO NOT EDIT.
+
+package sdl
+
+// SetWindowTitle sets the title of the window.
+func (window *Window) SetWindowTitle(title string) error { return SetWindowTitle(window, title) }
+
+// RenderClear clears the rendering target.
+func (renderer *Renderer) Clear() bool {
+	return RenderClear(renderer)
+}
+`,
+		},
+		{
+			name: "metrics",
+			opts: &GenOptions{GenMetrics: true},
+			want: `// Code generated by "genmethods"; DO NOT EDIT.
+
+package sdl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus metrics of (*Window).SetWindowTitle.
+var (
+	promWindowSetWindowTitleDuration = prometheus.NewSummary(prometheus.SummaryOpts{Name: "sdl_window_set_window_title_duration_seconds", Help: "Duration of (*Window).SetWindowTitle calls in seconds."})
+	promWindowSetWindowTitleCalls    = prometheus.NewCounter(prometheus.CounterOpts{Name: "sdl_window_set_window_title_calls_total", Help: "Number of (*Window).SetWindowTitle calls."})
+	promWindowSetWindowTitleErrors   = prometheus.NewCounter(prometheus.CounterOpts{Name: "sdl_window_set_window_title_errors_total", Help: "Number of (*Window).SetWindowTitle calls returning an error."})
+)
+
+// SetWindowTitle sets the title of the window.
+func (window *Window) SetWindowTitle(title string) error {
+	promWindowSetWindowTitleCalls.Inc()
+	defer prometheus.NewTimer(promWindowSetWindowTitleDuration).ObserveDuration()
+	err := SetWindowTitle(window, title)
+	if err != nil {
+		promWindowSetWindowTitleErrors.Inc()
+	}
+	return err
+}
+
+// Prometheus metrics of (*Renderer).Clear.
+var (
+	promRendererClearDuration = prometheus.NewSummary(prometheus.SummaryOpts{Name: "sdl_renderer_clear_duration_seconds", Help: "Duration of (*Renderer).Clear calls in seconds."})
+	promRendererClearCalls    = prometheus.NewCounter(prometheus.CounterOpts{Name: "sdl_renderer_clear_calls_total", Help: "Number of (*Renderer).Clear calls."})
+)
+
+// RenderClear clears the rendering target.
+func (renderer *Renderer) Clear() bool {
+	promRendererClearCalls.Inc()
+	defer prometheus.NewTimer(promRendererClearDuration).ObserveDuration()
+	return RenderClear(renderer)
+}
+
+// Register Prometheus metrics of generated methods.
+func init() {
+	prometheus.MustRegister(promWindowSetWindowTitleDuration, promWindowSetWindowTitleCalls, promWindowSetWindowTitleErrors, promRendererClearDuration, promRendererClearCalls)
+}
+`,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got := genFixture(t, "prometheus", g.opts)
+			if string(got.src) != g.want {
+				t.Errorf("generated source mismatch; expected:\n%s\ngot:\n%s", g.want, got.src)
+			}
+		})
+	}
+}
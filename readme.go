@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+)
+
+// Markers of the region replaced by the table of generated methods in
+// README.md.
+const (
+	readmeStart = "<!-- genmethods:start -->"
+	readmeEnd   = "<!-- genmethods:end -->"
+)
+
+// updateReadme updates the table of generated methods in the README.md file of
+// the package directory. The table replaces the region between the README
+// markers if present, and is otherwise appended to the README.
+func (gen *Gen) updateReadme() error {
+	if len(gen.pkg.GoFiles) == 0 {
+		return errors.Errorf("unable to locate directory of package %q", gen.pkg.PkgPath)
+	}
+	readmePath := filepath.Join(filepath.Dir(gen.pkg.GoFiles[0]), "README.md")
+	src, err := os.ReadFile(readmePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errors.WithStack(err)
+	}
+	table := readmeStart + "\n" + gen.methodTable() + readmeEnd
+	var data []byte
+	start := bytes.Index(src, []byte(readmeStart))
+	end := bytes.Index(src, []byte(readmeEnd))
+	switch {
+	case start == -1 && end == -1:
+		buf := &bytes.Buffer{}
+		buf.Write(src)
+		if len(src) > 0 {
+			if !bytes.HasSuffix(src, []byte("\n")) {
+				buf.WriteString("\n")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(table)
+		buf.WriteString("\n")
+		data = buf.Bytes()
+	case start == -1 || end == -1 || end < start:
+		return errors.Errorf("malformed %q and %q markers in %q", readmeStart, readmeEnd, readmePath)
+	default:
+		buf := &bytes.Buffer{}
+		buf.Write(src[:start])
+		buf.WriteString(table)
+		buf.Write(src[end+len(readmeEnd):])
+		data = buf.Bytes()
+	}
+	clog.Debugf("writing to %q", readmePath)
+	if err := os.WriteFile(readmePath, data, 0o644); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// methodTable returns a Markdown table of the generated methods. Parameters
+// are printed from the generated method declarations (e.g. with renamed
+// parameters), excluding the receiver.
+func (gen *Gen) methodTable() string {
+	buf := &strings.Builder{}
+	buf.WriteString("| Receiver | Method | Wraps | Parameters |\n")
+	buf.WriteString("| --- | --- | --- | --- |\n")
+	qualifier := types.RelativeTo(gen.pkg.Types)
+	for _, method := range gen.methods {
+		var params []string
+		for _, param := range flatParams(method.Decl.Type.Params) {
+			params = append(params, fmt.Sprintf("%s %s", param.name, types.ExprString(param.field.Type)))
+		}
+		fmt.Fprintf(buf, "| `%s` | `%s` | `%s` | %s |\n", types.TypeString(method.RecvType, qualifier), method.Decl.Name, method.Func.Name(), markdownCode(strings.Join(params, ", ")))
+	}
+	return buf.String()
+}
+
+// apiTable returns a Markdown table of the generated methods, listing the
+// receiver type, method name, signature and source function of each method in
+// the order of the generated Go file. Signatures are printed from the generated
+// method declarations.
+func (gen *Gen) apiTable() (string, error) {
+	buf := &strings.Builder{}
+	buf.WriteString("| Receiver | Method | Signature | Source function |\n")
+	buf.WriteString("| --- | --- | --- | --- |\n")
+	qualifier := types.RelativeTo(gen.pkg.Types)
+	for _, method := range gen.methods {
+		sig := &strings.Builder{}
+		if err := format.Node(sig, gen.pkg.Fset, method.Decl.Type); err != nil {
+			return "", errors.WithStack(err)
+		}
+		// join signatures spanning multiple lines.
+		signature := strings.Join(strings.Fields(sig.String()), " ")
+		fmt.Fprintf(buf, "| `%s` | `%s` | %s | `%s` |\n", types.TypeString(method.RecvType, qualifier), method.Decl.Name, markdownCode(signature), method.Func.Name())
+	}
+	return buf.String(), nil
+}
+
+// markdownCode returns s as inline Markdown code, or the empty string if s is
+// empty.
+func markdownCode(s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenReadme(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected table row of BlitSurface.
+		want string
+	}{
+		{
+			name: "first",
+			opts: &GenOptions{GenReadme: true, AnyPosition: true},
+			want: "| `*Surface` | `Blit` | `BlitSurface` | `dst *Surface` |",
+		},
+		{
+			name: "last",
+			opts: &GenOptions{GenReadme: true, AnyPosition: true, RecvPriority: "last"},
+			want: "| `*Surface` | `Blit` | `BlitSurface` | `src *Surface` |",
+		},
+		{
+			name: "renamed parameter",
+			opts: &GenOptions{
+				GenReadme:    true,
+				AnyPosition:  true,
+				RecvPriority: "last",
+				Config:       &Config{ParamNames: map[string]map[string]string{"BlitSurface": {"src": "source"}}},
+			},
+			want: "| `*Surface` | `Blit` | `BlitSurface` | `source *Surface` |",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			genFixture(t, "sdl", g.opts)
+			data, err := os.ReadFile(filepath.Join("sdl", "README.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(string(data), "\n")
+			if !slices.Contains(lines, g.want) {
+				t.Errorf("table row %q not found in README.md\n%s", g.want, data)
+			}
+			const getSize = "| `*Window` | `GetSize` | `GetWindowSize` | `w *int32, h *int32` |"
+			if !slices.Contains(lines, getSize) {
+				t.Errorf("table row %q not found in README.md\n%s", getSize, data)
+			}
+		})
+	}
+}
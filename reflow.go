@@ -0,0 +1,43 @@
+package main
+
+import (
+	"go/ast"
+	"strings"
+	"unicode/utf8"
+)
+
+// reflowDoc returns a copy of the given doc comment with `//` comment lines
+// longer than width re-flowed at word boundaries. Directives (e.g.
+// "//go:noinline"), indented lines (e.g. code blocks) and block comments are
+// left as is, as are words longer than width.
+func reflowDoc(doc *ast.CommentGroup, width int) *ast.CommentGroup {
+	newDoc := &ast.CommentGroup{}
+	for _, comment := range doc.List {
+		text, ok := strings.CutPrefix(comment.Text, "// ")
+		if !ok || utf8.RuneCountInString(comment.Text) <= width || strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			newDoc.List = append(newDoc.List, comment)
+			continue
+		}
+		var line strings.Builder
+		flush := func() {
+			newComment := &ast.Comment{
+				Text: "// " + line.String(),
+			}
+			newDoc.List = append(newDoc.List, newComment)
+			line.Reset()
+		}
+		for _, word := range strings.Fields(text) {
+			if line.Len() > 0 && len("// ")+utf8.RuneCountInString(line.String())+1+utf8.RuneCountInString(word) > width {
+				flush()
+			}
+			if line.Len() > 0 {
+				line.WriteString(" ")
+			}
+			line.WriteString(word)
+		}
+		if line.Len() > 0 {
+			flush()
+		}
+	}
+	return newDoc
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// rewriteImports rewrites the import paths of the given Go source file, as
+// specified by the import path rewrites of the form "old/path=new/path".
+func rewriteImports(src []byte, rewrites []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, rewrite := range rewrites {
+		oldPath, newPath, ok := strings.Cut(rewrite, "=")
+		if !ok || len(oldPath) == 0 || len(newPath) == 0 {
+			return nil, errors.Errorf("invalid import path rewrite %q; expected old/path=new/path", rewrite)
+		}
+		astutil.RewriteImport(fset, file, oldPath, newPath)
+	}
+	buf := &bytes.Buffer{}
+	if err := format.Node(buf, fset, file); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
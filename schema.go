@@ -0,0 +1,271 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// defaultSchema is the default JSON Schema of config files.
+//
+//go:embed config.schema.json
+var defaultSchema []byte
+
+// loadSchema loads the JSON Schema at the given path, or the default schema if
+// path is empty.
+func loadSchema(path string) (*schema, error) {
+	data := defaultSchema
+	if len(path) > 0 {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	s := &schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse JSON Schema %q", path)
+	}
+	return s, nil
+}
+
+// schema is a JSON Schema, supporting a subset of the validation keywords of
+// JSON Schema (draft 2020-12); unsupported keywords are ignored.
+type schema struct {
+	// allowed JSON types; either a string or an array of strings.
+	Type any `json:"type"`
+	// allowed values.
+	Enum []any `json:"enum"`
+	// object property schemas.
+	Properties map[string]*schema `json:"properties"`
+	// required object properties.
+	Required []string `json:"required"`
+	// schema of object properties not listed in properties; either a boolean
+	// or a schema.
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	// schema of array items.
+	Items *schema `json:"items"`
+	// minimum string length.
+	MinLength *int `json:"minLength"`
+	// regular expression matched by strings.
+	Pattern string `json:"pattern"`
+}
+
+// SchemaError is a JSON Schema validation error.
+type SchemaError struct {
+	// JSON path of the invalid value (e.g. "$.rename.Foo").
+	Path string
+	// human-readable error message.
+	Msg string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// validateConfig validates the given JSON config file contents against the
+// JSON Schema.
+func validateConfig(configPath string, data []byte, s *schema) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return errors.Wrapf(err, "unable to parse config file %q", configPath)
+	}
+	errs, err := s.validate("$", v)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(errs) > 0 {
+		return errors.WithStack(&ErrSchemaValidation{ConfigPath: configPath, Errors: errs})
+	}
+	return nil
+}
+
+// validate validates the given JSON value at the specified JSON path against
+// the schema.
+func (s *schema) validate(path string, v any) ([]*SchemaError, error) {
+	var errs []*SchemaError
+	addErr := func(format string, args ...any) {
+		errs = append(errs, &SchemaError{Path: path, Msg: fmt.Sprintf(format, args...)})
+	}
+	if s.Type != nil {
+		allowed, err := schemaTypes(s.Type)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if typ := jsonType(v); !typeAllowed(allowed, typ) {
+			addErr("expected %s, got %s", strings.Join(allowed, " or "), typ)
+			return errs, nil
+		}
+	}
+	if s.Enum != nil && !containsValue(s.Enum, v) {
+		var vals []string
+		for _, val := range s.Enum {
+			buf, _ := json.Marshal(val)
+			vals = append(vals, string(buf))
+		}
+		addErr("expected one of %s", strings.Join(vals, ", "))
+	}
+	switch v := v.(type) {
+	case string:
+		if s.MinLength != nil && utf8.RuneCountInString(v) < *s.MinLength {
+			addErr("expected string of at least %d characters", *s.MinLength)
+		}
+		if len(s.Pattern) > 0 {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid pattern %q of JSON Schema", s.Pattern)
+			}
+			if !re.MatchString(v) {
+				addErr("%q does not match pattern %q", v, s.Pattern)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				itemErrs, err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
+				errs = append(errs, itemErrs...)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				addErr("missing required property %q", name)
+			}
+		}
+		additional, err := s.additionalSchema()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		// validate properties in sorted order for deterministic output.
+		var names []string
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propPath := propertyPath(path, name)
+			prop, ok := s.Properties[name]
+			if !ok {
+				if additional == nil {
+					errs = append(errs, &SchemaError{Path: propPath, Msg: "unknown property"})
+					continue
+				}
+				prop = additional
+			}
+			propErrs, err := prop.validate(propPath, v[name])
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			errs = append(errs, propErrs...)
+		}
+	}
+	return errs, nil
+}
+
+// additionalSchema returns the schema of additional object properties, or nil
+// if additional properties are not allowed.
+func (s *schema) additionalSchema() (*schema, error) {
+	if len(s.AdditionalProperties) == 0 {
+		return &schema{}, nil // allow any
+	}
+	var allowed bool
+	if err := json.Unmarshal(s.AdditionalProperties, &allowed); err == nil {
+		if allowed {
+			return &schema{}, nil
+		}
+		return nil, nil
+	}
+	additional := &schema{}
+	if err := json.Unmarshal(s.AdditionalProperties, additional); err != nil {
+		return nil, errors.Wrap(err, "invalid additionalProperties of JSON Schema")
+	}
+	return additional, nil
+}
+
+// schemaTypes returns the allowed JSON types of the given "type" keyword
+// value.
+func schemaTypes(typ any) ([]string, error) {
+	switch typ := typ.(type) {
+	case string:
+		return []string{typ}, nil
+	case []any:
+		var types []string
+		for _, t := range typ {
+			s, ok := t.(string)
+			if !ok {
+				return nil, errors.Errorf("invalid type %v of JSON Schema", typ)
+			}
+			types = append(types, s)
+		}
+		return types, nil
+	default:
+		return nil, errors.Errorf("invalid type %v of JSON Schema", typ)
+	}
+}
+
+// jsonType returns the JSON type of the given decoded JSON value.
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		panic(fmt.Errorf("support for JSON value of type %T not yet implemented", v))
+	}
+}
+
+// typeAllowed reports whether the given JSON type is one of the allowed types.
+// Integers are also numbers.
+func typeAllowed(allowed []string, typ string) bool {
+	for _, t := range allowed {
+		if t == typ || (t == "number" && typ == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue reports whether vals contains a value deeply equal to v.
+func containsValue(vals []any, v any) bool {
+	for _, val := range vals {
+		if reflect.DeepEqual(val, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// identPattern matches property names written in dot notation of JSON paths.
+var identPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// propertyPath returns the JSON path of the named property of the object at
+// the given JSON path (e.g. $.rename.Foo or $.receivers["pkg.T"]).
+func propertyPath(path, name string) string {
+	if identPattern.MatchString(name) {
+		return path + "." + name
+	}
+	return path + "[" + strconv.Quote(name) + "]"
+}
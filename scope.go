@@ -0,0 +1,213 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Scope specifies a scoped method generated on a receiver type (e.g.
+// `(*Surface).WithLock(fn func())`), which calls an acquire function, runs a
+// callback and calls the paired release function via defer.
+type Scope struct {
+	// name of scoped method (e.g. "WithLock").
+	Name string `json:"name"`
+	// name of acquire function (e.g. "LockSurface"), taking the receiver as its
+	// only parameter, and returning no result, a bool or an error.
+	Acquire string `json:"acquire"`
+	// name of release function (e.g. "UnlockSurface"), taking the receiver as
+	// its only parameter; its results are discarded.
+	Release string `json:"release"`
+}
+
+// scopeDecls returns the scoped methods configured for the receiver types of
+// the given generated methods, sorted by receiver type. Scoped methods are
+// declared in the output file of the first generated method of the receiver
+// type, or in the primary output file if the receiver type has no generated
+// methods.
+//
+// Example:
+//
+//	func (surface *Surface) WithLock(fn func()) bool {
+//		if !LockSurface(surface) {
+//			return false
+//		}
+//		defer UnlockSurface(surface)
+//		fn()
+//		return true
+//	}
+func (gen *Gen) scopeDecls(methods []*Method, primary bool) ([]ast.Decl, error) {
+	config := gen.opts.Config
+	if config == nil || len(config.Scopes) == 0 {
+		return nil, nil
+	}
+	// map from base receiver type to first generated method.
+	first := make(map[string]*Method)
+	for _, method := range gen.methods {
+		base := method.RecvType
+		if ptr, ok := base.(*types.Pointer); ok {
+			base = ptr.Elem()
+		}
+		if first[base.String()] == nil {
+			first[base.String()] = method
+		}
+	}
+	var typStrs []string
+	for typStr := range config.Scopes {
+		typStrs = append(typStrs, typStr)
+	}
+	sort.Strings(typStrs)
+	var decls []ast.Decl
+	for _, typStr := range typStrs {
+		recvType, err := gen.lookupType(typStr)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		base := recvType
+		if ptr, ok := base.(*types.Pointer); ok {
+			base = ptr.Elem()
+		}
+		if method, ok := first[base.String()]; (ok && !slices.Contains(methods, method)) || (!ok && !primary) {
+			continue
+		}
+		for _, scope := range config.Scopes[typStr] {
+			decl, err := gen.scopeMethod(recvType, scope)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			decls = append(decls, decl)
+		}
+	}
+	return decls, nil
+}
+
+// scopeMethod returns the scoped method of the given scope on the given
+// receiver type.
+func (gen *Gen) scopeMethod(recvType types.Type, scope *Scope) (*ast.FuncDecl, error) {
+	if !token.IsIdentifier(scope.Name) {
+		return nil, errors.Errorf("invalid name %q of scoped method on %v", scope.Name, recvType)
+	}
+	if obj, _, _ := types.LookupFieldOrMethod(recvType, true, gen.pkg.Types, scope.Name); obj != nil && !gen.isOutputFile(gen.pkg.Fset.Position(obj.Pos()).Filename) {
+		return nil, errors.Errorf("scoped method %q already declared on %v", scope.Name, recvType)
+	}
+	base := recvType
+	if ptr, ok := base.(*types.Pointer); ok {
+		base = ptr.Elem()
+	}
+	if funcName, ok := gen.methodFuncs[base.String()+"."+scope.Name]; ok {
+		return nil, errors.Errorf("scoped method %q on %v collides with method generated for function %q", scope.Name, recvType, funcName)
+	}
+	acquire, err := gen.scopeFunc(recvType, scope.Name, scope.Acquire)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	release, err := gen.scopeFunc(recvType, scope.Name, scope.Release)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// name receiver by the config or the receiver parameter of the acquire
+	// function, suffixed with underscores if colliding with the callback.
+	recvName := acquire.Type().(*types.Signature).Params().At(0).Name()
+	if name, ok := gen.recvNameOverride(recvType); ok {
+		recvName = name
+	}
+	if len(recvName) == 0 || recvName == "_" {
+		recvName = initialLower(typeName(recvType))
+	}
+	for recvName == "fn" || recvName == "err" {
+		recvName += "_"
+	}
+	call := func(funcName string) *ast.CallExpr {
+		return &ast.CallExpr{
+			Fun:  ast.NewIdent(funcName),
+			Args: []ast.Expr{ast.NewIdent(recvName)},
+		}
+	}
+	deferStmt := &ast.DeferStmt{Call: call(release.Name())}
+	callbackStmt := &ast.ExprStmt{X: &ast.CallExpr{Fun: ast.NewIdent("fn")}}
+	doc := []*ast.Comment{
+		{Text: "// " + scope.Name + " calls " + acquire.Name() + ", runs fn and calls " + release.Name() + " after fn"},
+		{Text: "// returns (or panics)."},
+	}
+	var (
+		results *ast.FieldList
+		stmts   []ast.Stmt
+	)
+	switch acquireResults := acquire.Type().(*types.Signature).Results(); {
+	case acquireResults.Len() == 0:
+		stmts = []ast.Stmt{&ast.ExprStmt{X: call(acquire.Name())}, deferStmt, callbackStmt}
+	case acquireResults.Len() == 1 && types.Identical(acquireResults.At(0).Type(), types.Typ[types.Bool]):
+		doc = append(doc, &ast.Comment{Text: "// It reports whether " + acquire.Name() + " succeeded; fn is only run on success."})
+		results = &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("bool")}}}
+		stmts = []ast.Stmt{
+			&ast.IfStmt{
+				Cond: &ast.UnaryExpr{Op: token.NOT, X: call(acquire.Name())},
+				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("false")}}}},
+			},
+			deferStmt,
+			callbackStmt,
+			&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("true")}},
+		}
+	case acquireResults.Len() == 1 && isError(acquireResults.At(0).Type()):
+		doc = append(doc, &ast.Comment{Text: "// It returns the error of " + acquire.Name() + "; fn is only run on success."})
+		results = &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("error")}}}
+		stmts = []ast.Stmt{
+			&ast.IfStmt{
+				Init: &ast.AssignStmt{
+					Lhs: []ast.Expr{ast.NewIdent("err")},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{call(acquire.Name())},
+				},
+				Cond: &ast.BinaryExpr{X: ast.NewIdent("err"), Op: token.NEQ, Y: ast.NewIdent("nil")},
+				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("err")}}}},
+			},
+			deferStmt,
+			callbackStmt,
+			&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("nil")}},
+		}
+	default:
+		return nil, errors.Errorf("unsupported results of acquire function %q of scoped method %q; expected no result, bool or error", acquire.Name(), scope.Name)
+	}
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{List: doc},
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{ast.NewIdent(recvName)},
+					Type:  gen.typeExpr(recvType),
+				},
+			},
+		},
+		Name: ast.NewIdent(scope.Name),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Names: []*ast.Ident{ast.NewIdent("fn")},
+						Type:  &ast.FuncType{Params: &ast.FieldList{}},
+					},
+				},
+			},
+			Results: results,
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}, nil
+}
+
+// scopeFunc returns the acquire or release function with the given name of the
+// given scoped method, which must take the receiver as its only parameter.
+func (gen *Gen) scopeFunc(recvType types.Type, methodName, funcName string) (*types.Func, error) {
+	fn, ok := gen.pkg.Types.Scope().Lookup(funcName).(*types.Func)
+	if !ok {
+		return nil, errors.Errorf("unable to locate function %q of scoped method %q in package %q", funcName, methodName, gen.pkg.PkgPath)
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.TypeParams().Len() > 0 || sig.Params().Len() != 1 || !types.Identical(sig.Params().At(0).Type(), recvType) {
+		return nil, errors.Errorf("invalid function %q of scoped method %q; expected %v as only parameter", funcName, methodName, recvType)
+	}
+	return fn, nil
+}
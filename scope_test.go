@@ -0,0 +1,94 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScopes(t *testing.T) {
+	scopes := map[string][]*Scope{
+		"*" + fixturePkgPath + ".Surface":   {{Name: "WithLock", Acquire: "LockSurface", Release: "UnlockSurface"}},
+		"*" + fixturePkgPath + ".GPUDevice": {{Name: "WithClaim", Acquire: "ClaimGPUDevice", Release: "ReleaseGPUDevice"}},
+		"*" + fixturePkgPath + ".Window":    {{Name: "WithFrame", Acquire: "BeginWindowFrame", Release: "EndWindowFrame"}},
+	}
+	golden := []struct {
+		name      string
+		recvNames map[string]string
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "scopes",
+			want: map[string]string{
+				// acquire function returning bool.
+				"(*Surface).WithLock": "func (surface *Surface) WithLock(fn func()) bool {\n\tif !LockSurface(surface) {\n\t\treturn false\n\t}\n\tdefer UnlockSurface(surface)\n\tfn()\n\treturn true\n}",
+				// acquire function returning error.
+				"(*GPUDevice).WithClaim": "func (device *GPUDevice) WithClaim(fn func()) error {\n\tif err := ClaimGPUDevice(device); err != nil {\n\t\treturn err\n\t}\n\tdefer ReleaseGPUDevice(device)\n\tfn()\n\treturn nil\n}",
+				// acquire function without result.
+				"(*Window).WithFrame": "func (window *Window) WithFrame(fn func()) {\n\tBeginWindowFrame(window)\n\tdefer EndWindowFrame(window)\n\tfn()\n}",
+			},
+		},
+		{
+			name:      "recv names",
+			recvNames: map[string]string{"*" + fixturePkgPath + ".Surface": "s"},
+			want: map[string]string{
+				"(*Surface).WithLock": "func (s *Surface) WithLock(fn func()) bool {\n\tif !LockSurface(s) {\n\t\treturn false\n\t}\n\tdefer UnlockSurface(s)\n\tfn()\n\treturn true\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			opts := &GenOptions{Config: &Config{Scopes: scopes, RecvNames: g.recvNames}}
+			got := genFixture(t, "scope", opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			// the tests of the fixture check the pairing of acquire and release
+			// calls.
+			runFixtureTests(t)
+		})
+	}
+}
+
+func TestScopesError(t *testing.T) {
+	surfaceType := "*" + fixturePkgPath + ".Surface"
+	golden := []struct {
+		name  string
+		scope *Scope
+		// expected error.
+		want string
+	}{
+		{
+			name:  "invalid name",
+			scope: &Scope{Name: "With Lock", Acquire: "LockSurface", Release: "UnlockSurface"},
+			want:  `invalid name "With Lock" of scoped method on *github.com/jupiterrider/purego-sdl3/sdl.Surface`,
+		},
+		{
+			name:  "collides with generated method",
+			scope: &Scope{Name: "Lock", Acquire: "LockSurface", Release: "UnlockSurface"},
+			want:  `scoped method "Lock" on *github.com/jupiterrider/purego-sdl3/sdl.Surface collides with method generated for function "LockSurface"`,
+		},
+		{
+			name:  "missing function",
+			scope: &Scope{Name: "WithLock", Acquire: "LockSurface", Release: "FreeSurface"},
+			want:  `unable to locate function "FreeSurface" of scoped method "WithLock" in package "github.com/jupiterrider/purego-sdl3/sdl"`,
+		},
+		{
+			name:  "other receiver type",
+			scope: &Scope{Name: "WithLock", Acquire: "LockSurface", Release: "EndWindowFrame"},
+			want:  `invalid function "EndWindowFrame" of scoped method "WithLock"; expected *github.com/jupiterrider/purego-sdl3/sdl.Surface as only parameter`,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "scope")
+			opts := &GenOptions{Config: &Config{Scopes: map[string][]*Scope{surfaceType: {g.scope}}}}
+			_, err := genMethods(fixturePkgPath, filepath.Join(dir, "sdl", "methods_gen.go"), opts)
+			if err == nil || err.Error() != g.want {
+				t.Errorf("error mismatch; expected %q, got %v", g.want, err)
+			}
+		})
+	}
+}
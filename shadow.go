@@ -0,0 +1,31 @@
+package main
+
+import (
+	"go/types"
+
+	"github.com/mewpkg/clog"
+)
+
+// checkShadowed warns if the given generated method shadows a method promoted
+// from an embedded field of the receiver type, which changes the behaviour of
+// existing calls of the promoted method. Method sets are computed once per
+// receiver base type.
+func (gen *Gen) checkShadowed(baseType types.Type, methodName string) {
+	key := baseType.String()
+	mset, ok := gen.methodSets[key]
+	if !ok {
+		// method set of *T includes the methods promoted to both T and *T.
+		mset = types.NewMethodSet(types.NewPointer(baseType))
+		gen.methodSets[key] = mset
+	}
+	sel := mset.Lookup(gen.pkg.Types, methodName)
+	if sel == nil || len(sel.Index()) < 2 {
+		// not promoted; methods declared on the receiver type itself are
+		// previously generated or reported by the type checker.
+		return
+	}
+	promoted := sel.Obj().(*types.Func)
+	embedded := promoted.Type().(*types.Signature).Recv().Type()
+	qualifier := types.RelativeTo(gen.pkg.Types)
+	clog.Warnf("generated method %q on %s shadows method promoted from embedded %s", methodName, types.TypeString(baseType, qualifier), types.TypeString(embedded, qualifier))
+}
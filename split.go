@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"go/build/constraint"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// partition is a subset of generated methods, written to the same output file.
+type partition struct {
+	// output path.
+	output string
+	// generated methods.
+	methods []*Method
+	// build constraint of the output file (e.g. of the source file in
+	// group-by-file mode); or nil if unconstrained.
+	buildExpr constraint.Expr
+}
+
+// printSplitMethods writes one output file per receiver type (or per source
+// file in group-by-file mode) into the given output directory (or the package
+// directory if empty).
+//
+// Output files are formatted and written concurrently, bounded by opts.Jobs.
+// The first error cancels the remaining writes, and removes the files created
+// so far; files which existed before the run are left in place.
+func (gen *Gen) printSplitMethods(outputDir string) error {
+	if gen.opts.Merge {
+		return errors.New("merge mode (-merge) is not supported in split mode (-split-by-type) or group-by-file mode (-group-by-file)")
+	}
+	if len(outputDir) == 0 {
+		if len(gen.pkg.GoFiles) == 0 {
+			return errors.Errorf("unable to locate directory of package %q", gen.pkg.PkgPath)
+		}
+		outputDir = filepath.Dir(gen.pkg.GoFiles[0])
+	}
+	partitionBy := gen.partitionByType
+	if gen.opts.GroupByFile {
+		partitionBy = gen.partitionByFile
+	}
+	parts, err := partitionBy(outputDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	gen.splitOutputs = nil
+	for _, part := range parts {
+		if err := gen.checkOverwrite(part.output); err != nil {
+			return errors.WithStack(err)
+		}
+		gen.splitOutputs = append(gen.splitOutputs, part.output)
+	}
+	eg, ctx := errgroup.WithContext(context.Background())
+	eg.SetLimit(max(1, gen.opts.Jobs))
+	var (
+		mu      sync.Mutex
+		created []string
+	)
+	for i, part := range parts {
+		eg.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// package-level declarations are placed in the first output file.
+			data, err := gen.sourceOf(part.methods, i == 0, part.buildExpr)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if data, err = gen.postProcess(data, part.output); err != nil {
+				return errors.WithStack(err)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, err := os.Lstat(part.output); os.IsNotExist(err) {
+				mu.Lock()
+				created = append(created, part.output)
+				mu.Unlock()
+			}
+			if err := gen.writeFile(part.output, data); err != nil {
+				return errors.WithStack(err)
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		for _, output := range created {
+			if err := os.Remove(output); err != nil && !os.IsNotExist(err) {
+				clog.Warnf("unable to remove partially written file %q: %v", output, err)
+			}
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// partitionByType partitions the generated methods by receiver type, sorted by
+// output path. Output file names are derived from the receiver type using the
+// split template (opts.SplitTemplate), or output paths using the output
+// pattern (opts.OutputPattern).
+func (gen *Gen) partitionByType(outputDir string) ([]*partition, error) {
+	partMap := make(map[string]*partition)
+	// map from output path to base receiver type, used to detect name
+	// collisions.
+	outputTypes := make(map[string]types.Type)
+	for _, method := range gen.methods {
+		output, err := gen.typeOutput(outputDir, method.RecvType)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		baseType := method.RecvType
+		if ptr, ok := baseType.(*types.Pointer); ok {
+			baseType = ptr.Elem()
+		}
+		if prevType, ok := outputTypes[output]; ok && !types.Identical(prevType, baseType) {
+			return nil, errors.Errorf("output file name collision of receiver types %v and %v; both map to %q", prevType, baseType, output)
+		}
+		outputTypes[output] = baseType
+		part, ok := partMap[output]
+		if !ok {
+			part = &partition{output: output}
+			partMap[output] = part
+		}
+		part.methods = append(part.methods, method)
+	}
+	var parts []*partition
+	for _, part := range partMap {
+		parts = append(parts, part)
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].output < parts[j].output
+	})
+	return parts, nil
+}
+
+// typeOutput returns the output path of the methods on the given receiver type
+// in split mode; as specified by the output pattern (-output-pattern) if
+// present, or the split template within the given output directory otherwise.
+func (gen *Gen) typeOutput(outputDir string, recvType types.Type) (string, error) {
+	if len(gen.opts.OutputPattern) > 0 {
+		return patternOutput(gen.opts.OutputPattern, gen.pkg, recvType)
+	}
+	fileName, err := typeFileName(gen.opts.SplitTemplate, recvType)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return filepath.Join(outputDir, fileName), nil
+}
+
+// defaultSplitTemplate is the default output file name template of split mode.
+const defaultSplitTemplate = "{type_lower}_methods.go"
+
+// typeFileName returns the output file name of methods on the given receiver
+// type, as specified by the given file name template (e.g.
+// "renderer_methods.go" for *Renderer).
+//
+// Placeholders:
+//
+//	{type}        base type name (e.g. "GPUDevice")
+//	{type_lower}  lowercase base type name (e.g. "gpudevice")
+//	{type_snake}  snake_case base type name (e.g. "gpu_device")
+func typeFileName(template string, recvType types.Type) (string, error) {
+	if len(template) == 0 {
+		template = defaultSplitTemplate
+	}
+	name := typeName(recvType)
+	r := strings.NewReplacer(
+		"{type}", name,
+		"{type_lower}", strings.ToLower(name),
+		"{type_snake}", snakeCase(name),
+	)
+	fileName := r.Replace(template)
+	if !strings.HasSuffix(fileName, ".go") || strings.ContainsAny(fileName, `/\`) {
+		return "", errors.Errorf("invalid split template %q; expected file name with .go extension, got %q", template, fileName)
+	}
+	return fileName, nil
+}
+
+// typeName returns the name of the base type of the given receiver type (e.g.
+// "Renderer" for *Renderer).
+func typeName(recvType types.Type) string {
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		recvType = ptr.Elem()
+	}
+	if named, ok := types.Unalias(recvType).(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return recvType.String()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// exitDivergent is the exit code of -check-variants when generated methods
+// diverge across build tag combinations.
+const exitDivergent = 1
+
+// tagVariant is a build tag combination of a package (e.g. the cgo and !cgo
+// variants of a binding package with no-op stubs).
+type tagVariant struct {
+	// build tag combination as specified (e.g. "linux,!cgo").
+	name string
+	// comma-separated build tags used to load the package (e.g. "linux").
+	tags string
+	// additional environment variables used to load the package (e.g.
+	// "CGO_ENABLED=0").
+	env []string
+}
+
+// parseTagVariant parses the given build tag combination of -check-variants.
+// The pseudo-tags "cgo" and "!cgo" set CGO_ENABLED, as cgo is not selected by
+// build tags.
+func parseTagVariant(s string) (*tagVariant, error) {
+	variant := &tagVariant{name: s}
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		switch tag = strings.TrimSpace(tag); tag {
+		case "":
+			// no tags.
+		case "cgo":
+			variant.env = append(variant.env, "CGO_ENABLED=1")
+		case "!cgo":
+			variant.env = append(variant.env, "CGO_ENABLED=0")
+		default:
+			tags = append(tags, tag)
+		}
+	}
+	variant.tags = strings.Join(tags, ",")
+	if len(variant.tags) > 0 {
+		if err := checkBuildTags(variant.tags); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if len(variant.name) == 0 {
+		variant.name = "(no tags)"
+	}
+	return variant, nil
+}
+
+// variantMethod is a generated method of a build tag combination.
+type variantMethod struct {
+	// source function name.
+	funcName string
+	// method signature (e.g. "func(title string) bool").
+	sig string
+}
+
+// runCheckVariants generates the methods of the given package for each of the
+// given build tag combinations (-check-variants), and reports divergences of
+// the generated methods to w; i.e. methods generated for some but not all
+// combinations, or generated with different signatures. The build tags of the
+// generation options (-pkg-tag), if any, are added to each combination.
+// Nothing is written, and the exit code is returned.
+func runCheckVariants(w io.Writer, pkgPath string, variantSpecs []string, opts *GenOptions) (int, error) {
+	if isMultiPkg(pkgPath) {
+		return 0, errors.Errorf("variant check mode (-check-variants) does not support multi-package mode; got package path %q", pkgPath)
+	}
+	if len(variantSpecs) < 2 {
+		return 0, errors.Errorf("variant check mode (-check-variants) requires at least two build tag combinations; got %d", len(variantSpecs))
+	}
+	var variants []*tagVariant
+	// map from method key to variant name to generated method.
+	methods := make(map[string]map[string]variantMethod)
+	for _, spec := range variantSpecs {
+		variant, err := parseTagVariant(spec)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		variants = append(variants, variant)
+		variantOpts := *opts
+		variantOpts.PkgEnv = append(append([]string(nil), opts.PkgEnv...), variant.env...)
+		switch {
+		case len(opts.PkgTags) > 0 && len(variant.tags) > 0:
+			variantOpts.PkgTags = opts.PkgTags + "," + variant.tags
+		case len(variant.tags) > 0:
+			variantOpts.PkgTags = variant.tags
+		}
+		gen, err := newGen(pkgPath, "", &variantOpts)
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable to generate methods of build tag combination %q", variant.name)
+		}
+		qualifier := types.RelativeTo(gen.pkg.Types)
+		for _, method := range gen.methods {
+			recvType := types.TypeString(method.RecvType, qualifier)
+			if isPointer(method.RecvType) {
+				recvType = "(" + recvType + ")"
+			}
+			key := recvType + "." + method.Decl.Name.Name
+			if methods[key] == nil {
+				methods[key] = make(map[string]variantMethod)
+			}
+			methods[key][variant.name] = variantMethod{
+				funcName: method.Func.Name(),
+				sig:      types.ExprString(method.Decl.Type),
+			}
+		}
+	}
+	var keys []string
+	for key := range methods {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	n := 0
+	for _, key := range keys {
+		var missing, sigs []string
+		funcName := ""
+		for _, variant := range variants {
+			method, ok := methods[key][variant.name]
+			if !ok {
+				missing = append(missing, variant.name)
+				continue
+			}
+			funcName = method.funcName
+			sigs = append(sigs, fmt.Sprintf("%s in %s", method.sig, variant.name))
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(w, "method %s (of function %s) missing in build tag combination %s\n", key, funcName, strings.Join(missing, ", "))
+			n++
+			continue
+		}
+		sig := methods[key][variants[0].name].sig
+		for _, variant := range variants[1:] {
+			if methods[key][variant.name].sig != sig {
+				fmt.Fprintf(w, "method %s (of function %s) differs in signature: %s\n", key, funcName, strings.Join(sigs, "; "))
+				n++
+				break
+			}
+		}
+	}
+	switch {
+	case n == 1:
+		fmt.Fprintln(w, "1 divergent method across build tag combinations")
+		return exitDivergent, nil
+	case n > 1:
+		fmt.Fprintf(w, "%d divergent methods across build tag combinations\n", n)
+		return exitDivergent, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,21 @@
+// Command sdl is a test fixture of functions of a main package.
+package main
+
+// Window is a window.
+type Window struct{ title string }
+
+// SetWindowTitle sets the title of the window.
+func SetWindowTitle(window *Window, title string) {
+	window.title = title
+}
+
+// GetWindowTitle returns the title of the window.
+func GetWindowTitle(window *Window) string {
+	return window.title
+}
+
+func main() {
+	window := &Window{}
+	SetWindowTitle(window, "sdl")
+	println(GetWindowTitle(window))
+}
@@ -0,0 +1,63 @@
+// Package sdl is a test fixture of functions forwarded to by the methods of
+// wrapper types in a wrapper package (-gen-wrap).
+package sdl
+
+// Window is a window.
+type Window struct {
+	title string
+	// renderer of the window.
+	renderer *Renderer
+}
+
+// Renderer is a 2D rendering context.
+type Renderer struct{ window *Window }
+
+// Color is an RGBA color.
+type Color struct{ R, G, B, A uint8 }
+
+// CreateWindow creates a window with the given title.
+func CreateWindow(title string) (w *Window) {
+	return &Window{title: title}
+}
+
+// CreateRenderer creates a renderer of the window.
+func CreateRenderer(name string, flags ...uint32) (*Renderer, error) {
+	return &Renderer{}, nil
+}
+
+// SetWindowTitle sets the title of the window.
+func SetWindowTitle(window *Window, title string) {
+	window.title = title
+}
+
+// GetRenderer returns the renderer of the window.
+func GetRenderer(window *Window) (*Renderer, error) {
+	return window.renderer, nil
+}
+
+// RenderClear clears the rendering target using the given color.
+func RenderClear(renderer *Renderer, color Color) bool {
+	return true
+}
+
+// RenderPoints renders the given points.
+func RenderPoints(renderer *Renderer, points map[string][]Point) bool {
+	return true
+}
+
+// Point is a point.
+type Point struct{ X, Y int32 }
+
+// syncWindow synchronizes the window state; not accessible by wrapper
+// packages.
+func syncWindow(window *Window) {}
+
+// SetWindowState sets the state of the window; not accessible by wrapper
+// packages.
+func SetWindowState(window *Window, state windowState) {}
+
+// windowState is the state of a window.
+type windowState int
+
+// SetWindowData sets user data of the window.
+func SetWindowData[T any](window *Window, data T) {}
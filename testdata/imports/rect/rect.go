@@ -0,0 +1,9 @@
+// Package rect is a test fixture of a package of types referenced by the
+// parameter and result types of functions of the sdl package.
+package rect
+
+// Rect is a rectangle.
+type Rect struct{ X, Y, W, H int32 }
+
+// Point is a point.
+type Point struct{ X, Y int32 }
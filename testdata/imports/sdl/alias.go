@@ -0,0 +1,8 @@
+package sdl
+
+import geom "github.com/jupiterrider/purego-sdl3/rect"
+
+// GetWindowPosition returns the position of the window.
+func GetWindowPosition(window *Window) geom.Point {
+	return geom.Point{X: window.bounds.X, Y: window.bounds.Y}
+}
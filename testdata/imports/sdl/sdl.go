@@ -0,0 +1,18 @@
+// Package sdl is a test fixture of functions with parameter and result types of
+// other packages.
+package sdl
+
+import "github.com/jupiterrider/purego-sdl3/rect"
+
+// Window is a window.
+type Window struct{ bounds rect.Rect }
+
+// GetWindowBounds returns the bounds of the window.
+func GetWindowBounds(window *Window) rect.Rect {
+	return window.bounds
+}
+
+// SetWindowBounds sets the bounds of the window.
+func SetWindowBounds(window *Window, bounds rect.Rect) {
+	window.bounds = bounds
+}
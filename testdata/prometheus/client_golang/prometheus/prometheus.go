@@ -0,0 +1,76 @@
+// Package prometheus is a test fixture of the subset of the Prometheus client
+// package used by generated metrics (-gen-metrics).
+package prometheus
+
+import "time"
+
+// Collector is a metric collector.
+type Collector interface {
+	collect()
+}
+
+// Observer observes values of a metric.
+type Observer interface {
+	Observe(float64)
+}
+
+// SummaryOpts are the options of a summary.
+type SummaryOpts struct {
+	Name string
+	Help string
+}
+
+// Summary is a summary metric.
+type Summary interface {
+	Collector
+	Observer
+}
+
+type summary struct{ opts SummaryOpts }
+
+func (s *summary) collect()          {}
+func (s *summary) Observe(v float64) {}
+
+// NewSummary returns a summary with the given options.
+func NewSummary(opts SummaryOpts) Summary { return &summary{opts: opts} }
+
+// CounterOpts are the options of a counter.
+type CounterOpts struct {
+	Name string
+	Help string
+}
+
+// Counter is a counter metric.
+type Counter interface {
+	Collector
+	Inc()
+}
+
+type counter struct{ opts CounterOpts }
+
+func (c *counter) collect() {}
+func (c *counter) Inc()     {}
+
+// NewCounter returns a counter with the given options.
+func NewCounter(opts CounterOpts) Counter { return &counter{opts: opts} }
+
+// Timer measures the duration of calls.
+type Timer struct {
+	observer Observer
+	start    time.Time
+}
+
+// NewTimer returns a timer observing durations with the given observer.
+func NewTimer(observer Observer) *Timer {
+	return &Timer{observer: observer, start: time.Now()}
+}
+
+// ObserveDuration observes the duration since the timer was created.
+func (t *Timer) ObserveDuration() time.Duration {
+	d := time.Since(t.start)
+	t.observer.Observe(d.Seconds())
+	return d
+}
+
+// MustRegister registers the given collectors with the default registry.
+func MustRegister(collectors ...Collector) {}
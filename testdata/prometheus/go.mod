@@ -0,0 +1,7 @@
+module github.com/jupiterrider/purego-sdl3
+
+go 1.23
+
+require github.com/prometheus/client_golang v1.20.0
+
+replace github.com/prometheus/client_golang => ./client_golang
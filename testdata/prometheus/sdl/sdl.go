@@ -0,0 +1,20 @@
+// Package sdl is a test fixture of functions recording Prometheus metrics of
+// generated methods (-gen-metrics).
+package sdl
+
+// Window is a window.
+type Window struct{ title string }
+
+// Renderer is a 2D rendering context.
+type Renderer struct{ window *Window }
+
+// SetWindowTitle sets the title of the window.
+func SetWindowTitle(window *Window, title string) error {
+	window.title = title
+	return nil
+}
+
+// RenderClear clears the rendering target.
+func RenderClear(renderer *Renderer) bool {
+	return true
+}
@@ -0,0 +1,3 @@
+module github.com/jupiterrider/purego-sdl3
+
+go 1.23
@@ -0,0 +1,25 @@
+// Package sdl is a test fixture of SDL bindings panicking on invalid handles.
+package sdl
+
+// Window is a window.
+type Window struct{ destroyed bool }
+
+// ShowWindow shows the window; panics if the window is destroyed.
+func ShowWindow(window *Window) error {
+	if window.destroyed {
+		panic("window destroyed")
+	}
+	return nil
+}
+
+// GetWindowIcon returns the icon of the window; panics if the window is
+// destroyed.
+func GetWindowIcon(window *Window, size int32) ([]byte, error) {
+	if window.destroyed {
+		panic("window destroyed")
+	}
+	return make([]byte, size), nil
+}
+
+// GetWindowTitle returns the title of the window.
+func GetWindowTitle(window *Window) string { return "" }
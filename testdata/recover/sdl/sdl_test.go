@@ -0,0 +1,22 @@
+package sdl
+
+import "testing"
+
+// TestRecover is run on the generated methods of the fixture (-recover).
+func TestRecover(t *testing.T) {
+	window := &Window{destroyed: true}
+	const want = "panic in ShowWindow: window destroyed"
+	if err := window.Show(); err == nil || err.Error() != want {
+		t.Errorf("error mismatch; expected %q, got %v", want, err)
+	}
+	icon, err := window.GetWindowIcon(16)
+	if err == nil || err.Error() != "panic in GetWindowIcon: window destroyed" {
+		t.Errorf("expected recovered panic of GetWindowIcon, got %v", err)
+	}
+	if icon != nil {
+		t.Errorf("expected nil icon, got %v", icon)
+	}
+	if err := (&Window{}).Show(); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+}
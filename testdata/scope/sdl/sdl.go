@@ -0,0 +1,75 @@
+// Package sdl is a test fixture of SDL bindings with paired acquire and release
+// functions.
+package sdl
+
+// Error is an SDL error.
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// Surface is a collection of pixels.
+type Surface struct {
+	locked bool
+	// log of calls.
+	calls []string
+}
+
+// LockSurface locks the surface for direct pixel access; reports false if
+// already locked.
+func LockSurface(surface *Surface) bool {
+	if surface.locked {
+		return false
+	}
+	surface.locked = true
+	surface.calls = append(surface.calls, "lock")
+	return true
+}
+
+// UnlockSurface unlocks the surface.
+func UnlockSurface(surface *Surface) {
+	surface.locked = false
+	surface.calls = append(surface.calls, "unlock")
+}
+
+// GPUDevice is a GPU device.
+type GPUDevice struct {
+	claimed bool
+	// log of calls.
+	calls []string
+}
+
+// ClaimGPUDevice claims the GPU device.
+func ClaimGPUDevice(device *GPUDevice) error {
+	if device.claimed {
+		return Error("device already claimed")
+	}
+	device.claimed = true
+	device.calls = append(device.calls, "claim")
+	return nil
+}
+
+// ReleaseGPUDevice releases the GPU device.
+func ReleaseGPUDevice(device *GPUDevice) bool {
+	device.claimed = false
+	device.calls = append(device.calls, "release")
+	return true
+}
+
+// Window is a window.
+type Window struct {
+	// log of calls.
+	calls []string
+}
+
+// BeginWindowFrame begins a frame of the window.
+func BeginWindowFrame(window *Window) {
+	window.calls = append(window.calls, "begin")
+}
+
+// EndWindowFrame ends a frame of the window.
+func EndWindowFrame(window *Window) {
+	window.calls = append(window.calls, "end")
+}
+
+// GetWindowSize returns the size of the window.
+func GetWindowSize(window *Window, w, h *int32) (int32, int32) { return 0, 0 }
@@ -0,0 +1,56 @@
+package sdl
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestScopes is run on the generated scoped methods of the fixture.
+func TestScopes(t *testing.T) {
+	surface := &Surface{}
+	ok := surface.WithLock(func() {
+		if !surface.locked {
+			t.Errorf("expected locked surface in callback")
+		}
+		surface.calls = append(surface.calls, "fn")
+	})
+	if !ok {
+		t.Errorf("expected successful lock")
+	}
+	if want := []string{"lock", "fn", "unlock"}; !slices.Equal(surface.calls, want) {
+		t.Errorf("calls mismatch; expected %q, got %q", want, surface.calls)
+	}
+	// callback is not run if the acquire function fails.
+	locked := &Surface{locked: true}
+	if locked.WithLock(func() { t.Errorf("unexpected callback of locked surface") }) {
+		t.Errorf("expected failed lock")
+	}
+	// release function is called if the callback panics.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic of callback")
+			}
+		}()
+		surface.WithLock(func() { panic("callback") })
+	}()
+	if surface.locked {
+		t.Errorf("expected unlocked surface after panic")
+	}
+	device := &GPUDevice{}
+	if err := device.WithClaim(func() { device.calls = append(device.calls, "fn") }); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	if want := []string{"claim", "fn", "release"}; !slices.Equal(device.calls, want) {
+		t.Errorf("calls mismatch; expected %q, got %q", want, device.calls)
+	}
+	claimed := &GPUDevice{claimed: true}
+	if err := claimed.WithClaim(func() { t.Errorf("unexpected callback of claimed device") }); err == nil || err.Error() != "device already claimed" {
+		t.Errorf("expected error of ClaimGPUDevice, got %v", err)
+	}
+	window := &Window{}
+	window.WithFrame(func() { window.calls = append(window.calls, "fn") })
+	if want := []string{"begin", "fn", "end"}; !slices.Equal(window.calls, want) {
+		t.Errorf("calls mismatch; expected %q, got %q", want, window.calls)
+	}
+}
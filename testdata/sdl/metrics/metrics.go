@@ -0,0 +1,11 @@
+// Package metrics is a test fixture of a metrics package used by generated
+// methods.
+package metrics
+
+import "time"
+
+// Inc increments the call counter of the given function.
+func Inc(name string) {}
+
+// Since records the duration of a call of the given function.
+func Since(name string, start time.Time) {}
@@ -0,0 +1,10 @@
+package sdl
+
+// device is an internal rendering device.
+type device struct{ flags uint32 }
+
+// deviceFlags returns the flags of the device.
+func deviceFlags(d *device) uint32 { return d.flags }
+
+// ResetDevice resets the device.
+func ResetDevice(d *device) {}
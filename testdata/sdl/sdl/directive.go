@@ -0,0 +1,6 @@
+package sdl
+
+// ResizeWindow resizes the window.
+//
+//genmethods:name Resize
+func ResizeWindow(window *Window, w, h int32) bool { return true }
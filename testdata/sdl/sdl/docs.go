@@ -0,0 +1,16 @@
+package sdl
+
+// sets the minimum size of the window
+func SetWindowMinimumSize(window *Window, w, h int32) bool { return true }
+
+// Hides the window
+func HideWindow(window *Window) bool { return true }
+
+// maximizewindow maximizes the window
+// to fill the screen
+//
+// The window must be resizable
+func MaximizeWindow(window *Window) bool { return true }
+
+// GetWindowOpacity returns the opacity of the window.
+func GetWindowOpacity(window *Window) float32 { return 1 }
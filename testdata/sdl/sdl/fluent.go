@@ -0,0 +1,7 @@
+package sdl
+
+// ResetWindow resets the window.
+func ResetWindow(window *Window) *Window { return window }
+
+// FocusWindow focuses the window.
+func FocusWindow(window *Window) WindowPtr { return window }
@@ -0,0 +1,46 @@
+// Package sdl is a test fixture of SDL bindings.
+package sdl
+
+// Window is a window.
+type Window struct{ id int32 }
+
+// Renderer is a 2D rendering context.
+type Renderer struct{ window *Window }
+
+// Surface is a collection of pixels.
+type Surface struct{ locked bool }
+
+// CreateWindow creates a window.
+func CreateWindow(title string, w, h int32) *Window { return &Window{} }
+
+// DestroyWindow destroys the window.
+func DestroyWindow(window *Window) {}
+
+// GetWindowSize returns the size of the window.
+func GetWindowSize(window *Window, w, h *int32) bool { return true }
+
+// SetWindowTitle sets the title of the window.
+func SetWindowTitle(window *Window, title string) bool { return true }
+
+// GetWindowTitle returns the title of the window.
+func GetWindowTitle(window *Window) string { return "" }
+
+// CreateRenderer creates a renderer of the window.
+func CreateRenderer(window *Window, name string) (*Renderer, error) {
+	return &Renderer{window: window}, nil
+}
+
+// RenderClear clears the rendering target.
+func RenderClear(renderer *Renderer) bool { return true }
+
+// DestroyRenderer destroys the renderer.
+func DestroyRenderer(renderer *Renderer) {}
+
+// LockSurface locks the surface for direct pixel access.
+func LockSurface(surface *Surface) bool { return true }
+
+// UnlockSurface unlocks the surface.
+func UnlockSurface(surface *Surface) {}
+
+// GetError returns the last error message.
+func GetError() string { return "" }
@@ -0,0 +1,4 @@
+package sdl
+
+// GetWindowID returns the identifier of the window.
+func GetWindowID(window Window) int32 { return window.id }
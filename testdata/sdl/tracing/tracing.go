@@ -0,0 +1,8 @@
+// Package tracing is a test fixture of a tracing package used by generated
+// methods.
+package tracing
+
+import "context"
+
+// Trace records a call of the given method.
+func Trace(ctx context.Context, method string) {}
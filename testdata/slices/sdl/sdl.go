@@ -0,0 +1,35 @@
+// Package sdl is a test fixture of SDL bindings taking C-style (count, pointer)
+// parameter pairs.
+package sdl
+
+// Renderer is a 2D rendering context.
+type Renderer struct {
+	// points of the last call.
+	points []Point
+}
+
+// Point is a 2D point.
+type Point struct{ X, Y float32 }
+
+// RenderPoints draws the given points.
+func RenderPoints(renderer *Renderer, points *Point, count int32) bool {
+	renderer.points = nil
+	if count > 0 && points == nil {
+		return false
+	}
+	if points != nil {
+		renderer.points = append(renderer.points, *points)
+	}
+	return count >= 0
+}
+
+// RenderLines draws lines connecting the given points.
+func RenderLines(renderer *Renderer, count int, points *Point) bool { return true }
+
+// RenderGeometry draws the given vertices with the given color.
+func RenderGeometry(renderer *Renderer, count int32, color uint32, vertices *Point) bool {
+	return true
+}
+
+// RenderScaled draws the given points scaled by the given factor.
+func RenderScaled(renderer *Renderer, scale float32, points *Point) bool { return true }
@@ -0,0 +1,24 @@
+package sdl
+
+import "testing"
+
+// TestSlices is run on the generated methods of the fixture, collapsing
+// (count, pointer) parameter pairs into slices.
+func TestSlices(t *testing.T) {
+	renderer := &Renderer{}
+	points := []Point{{X: 1, Y: 2}, {X: 3, Y: 4}}
+	if !renderer.RenderPoints(points) {
+		t.Errorf("expected rendered points")
+	}
+	if len(renderer.points) != 1 || renderer.points[0] != points[0] {
+		t.Errorf("expected address of first point forwarded; got %v", renderer.points)
+	}
+	// empty slices forward a nil pointer.
+	renderer.points = []Point{{}}
+	if !renderer.RenderPoints(nil) || renderer.points != nil {
+		t.Errorf("expected nil pointer of empty slice forwarded; got %v", renderer.points)
+	}
+	if !renderer.RenderPoints([]Point{}) || renderer.points != nil {
+		t.Errorf("expected nil pointer of empty slice forwarded; got %v", renderer.points)
+	}
+}
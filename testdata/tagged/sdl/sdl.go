@@ -0,0 +1,11 @@
+//go:build sdl_tagged
+
+// Package sdl is a test fixture of SDL bindings, only built with the
+// sdl_tagged build tag.
+package sdl
+
+// Window is a window.
+type Window struct{ id int32 }
+
+// ShowWindow shows the window.
+func ShowWindow(window *Window) bool { return true }
@@ -0,0 +1,32 @@
+// Package sdl is a test fixture of SDL bindings returning errors.
+package sdl
+
+// Error is an SDL error.
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// Window is a window.
+type Window struct{ destroyed bool }
+
+// Renderer is a 2D rendering context.
+type Renderer struct{ window *Window }
+
+// SetWindowOpacity sets the opacity of the window.
+func SetWindowOpacity(window *Window, opacity float32) error {
+	if opacity < 0 || opacity > 1 {
+		return Error("invalid opacity")
+	}
+	return nil
+}
+
+// CreateRenderer creates a renderer of the window.
+func CreateRenderer(window *Window, name string) (*Renderer, error) {
+	if window.destroyed {
+		return nil, Error("window destroyed")
+	}
+	return &Renderer{window: window}, nil
+}
+
+// GetWindowTitle returns the title of the window.
+func GetWindowTitle(window *Window) string { return "" }
@@ -0,0 +1,29 @@
+package sdl
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestWrapErrors is run on the generated methods of the fixture (-wrap-errors).
+func TestWrapErrors(t *testing.T) {
+	window := &Window{}
+	err := window.SetWindowOpacity(2)
+	if err == nil || err.Error() != "SetWindowOpacity: invalid opacity" {
+		t.Errorf("expected wrapped error of SetWindowOpacity, got %v", err)
+	}
+	var sdlErr Error
+	if !errors.As(err, &sdlErr) || sdlErr != "invalid opacity" {
+		t.Errorf("expected unwrappable SDL error, got %v", err)
+	}
+	if err := window.SetWindowOpacity(0.5); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	renderer, err := (&Window{destroyed: true}).CreateRenderer("")
+	if err == nil || err.Error() != "CreateRenderer: window destroyed" {
+		t.Errorf("expected wrapped error of CreateRenderer, got %v", err)
+	}
+	if renderer != nil {
+		t.Errorf("expected nil renderer, got %v", renderer)
+	}
+}
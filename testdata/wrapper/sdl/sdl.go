@@ -0,0 +1,42 @@
+// Package sdl is a test fixture of SDL bindings wrapping raw handles in
+// structs with unexported fields.
+package sdl
+
+// sdlWindow is a raw window handle.
+type sdlWindow struct {
+	id    uint32
+	shown bool
+}
+
+// Window is a window, wrapping a raw window handle.
+type Window struct{ handle *sdlWindow }
+
+// sdlRenderer is a raw renderer handle.
+type sdlRenderer struct{ cleared int }
+
+// rendererHandle holds a raw renderer handle.
+type rendererHandle struct{ raw *sdlRenderer }
+
+// Handle returns the raw renderer handle.
+func (h rendererHandle) Handle() *sdlRenderer { return h.raw }
+
+// Renderer is a 2D rendering context, wrapping a raw renderer handle.
+type Renderer struct{ inner rendererHandle }
+
+// ShowWindow shows the window.
+func ShowWindow(window *sdlWindow) bool {
+	window.shown = true
+	return true
+}
+
+// GetWindowID returns the numeric ID of the window.
+func GetWindowID(window *sdlWindow) uint32 { return window.id }
+
+// SetWindowTitle sets the title of the window.
+func SetWindowTitle(window *sdlWindow, title string) bool { return true }
+
+// RenderClear clears the rendering target.
+func RenderClear(renderer *sdlRenderer) bool {
+	renderer.cleared++
+	return true
+}
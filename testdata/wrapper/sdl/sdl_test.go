@@ -0,0 +1,19 @@
+package sdl
+
+import "testing"
+
+// TestAccessors is run on the generated methods of the fixture, forwarding the
+// raw handles of wrapper receivers.
+func TestAccessors(t *testing.T) {
+	window := &Window{handle: &sdlWindow{id: 7}}
+	if id := window.GetWindowID(); id != 7 {
+		t.Errorf("window ID mismatch; expected 7, got %d", id)
+	}
+	if !window.Show() || !window.handle.shown {
+		t.Errorf("expected shown raw window handle")
+	}
+	renderer := &Renderer{inner: rendererHandle{raw: &sdlRenderer{}}}
+	if !renderer.Clear() || renderer.inner.raw.cleared != 1 {
+		t.Errorf("expected cleared raw renderer handle")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+)
+
+// resolveVariants groups the functions of the analyzed package by stem, and
+// records non-preferred variants to skip.
+func (gen *Gen) resolveVariants() error {
+	config := gen.opts.Config
+	if config == nil || config.Variants == nil || len(config.Variants.Suffixes) == 0 {
+		return nil
+	}
+	variants := config.Variants
+	// longest suffixes first.
+	suffixes := append([]string(nil), variants.Suffixes...)
+	sort.SliceStable(suffixes, func(i, j int) bool {
+		return len(suffixes[i]) > len(suffixes[j])
+	})
+	// map from stem to functions sharing the stem.
+	groups := make(map[string][]string)
+	scope := gen.pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if _, ok := obj.Type().Underlying().(*types.Signature); !ok {
+			continue
+		}
+		if _, ok := obj.(*types.TypeName); ok {
+			continue
+		}
+		stem := name
+		for _, suffix := range suffixes {
+			if s, ok := strings.CutSuffix(name, suffix); ok && len(s) > 0 {
+				stem = s
+				break
+			}
+		}
+		groups[stem] = append(groups[stem], name)
+	}
+	gen.variantSkips = make(map[string]string)
+	for stem, names := range groups {
+		if len(names) < 2 {
+			continue
+		}
+		preferred, ok := variants.Prefer[stem]
+		if !ok {
+			preferred = stem
+		}
+		found := false
+		for _, name := range names {
+			if name == preferred {
+				found = true
+				break
+			}
+		}
+		if !found {
+			if ok {
+				return errors.Errorf("preferred variant %q of stem %q not found; expected one of %s", preferred, stem, strings.Join(names, ", "))
+			}
+			continue // no function without variant suffix; keep all variants.
+		}
+		for _, name := range names {
+			if name != preferred {
+				clog.Infof("skipping variant %q of preferred function %q", name, preferred)
+				gen.variantSkips[name] = preferred
+			}
+		}
+	}
+	return nil
+}
+
+// skipVariant returns the skip reason of the given function, and reports
+// whether it is a non-preferred variant.
+func (gen *Gen) skipVariant(funcName string) (string, bool) {
+	preferred, ok := gen.variantSkips[funcName]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("variant of preferred function %q", preferred), true
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// verifyFileName is the file name of the generated verification program
+// (-emit-verify).
+const verifyFileName = "verify_gen.go"
+
+// verifyPath returns the output path of the verification program for the given
+// output path of generated methods (e.g. "sdl/verify_gen.go" for
+// "sdl/methods.go"). In split and group-by-file mode, the output path is
+// interpreted as the output directory.
+func (gen *Gen) verifyPath(output string) (string, error) {
+	if isMultiFile(gen.opts) {
+		if len(output) == 0 {
+			if len(gen.pkg.GoFiles) == 0 {
+				return "", errors.Errorf("unable to locate directory of package %q", gen.pkg.PkgPath)
+			}
+			output = filepath.Dir(gen.pkg.GoFiles[0])
+		}
+		return filepath.Join(output, verifyFileName), nil
+	}
+	if len(output) == 0 {
+		return "", errors.New("verification program generation (-emit-verify) requires an output path (-o)")
+	}
+	return filepath.Join(filepath.Dir(output), verifyFileName), nil
+}
+
+// printVerify writes the verification program of the generated methods next to
+// the given output path.
+func (gen *Gen) printVerify(output string) error {
+	verifyPath, err := gen.verifyPath(output)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	data, err := gen.verifySource()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := gen.writeFile(verifyPath, data); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// verifySource returns the Go source of a standalone verification program of
+// the generated methods, excluded from builds of the package by a `//go:build
+// ignore` constraint. The program pins the signature of each source function
+// as of generation and references the generated method, so that running (or
+// vetting) the program reports signature drift of source functions and
+// missing methods since generation.
+//
+// Example:
+//
+//	var _ func(*sdl.Window, string) error = sdl.SetWindowTitle
+//	var _ = (*sdl.Window).SetTitle
+//
+// Methods of unexported functions or receiver types, and of generic functions,
+// are not referenced, as they are not accessible by method expressions of the
+// verification program.
+func (gen *Gen) verifySource() ([]byte, error) {
+	if gen.pkg.Name == "main" {
+		return nil, errors.Errorf("verification program generation (-emit-verify) does not support main packages; package %q cannot be imported", gen.pkg.PkgPath)
+	}
+	// import names by import path; disambiguated by numeric suffix.
+	importNames := make(map[string]string)
+	used := map[string]bool{"main": true}
+	qualifier := func(pkg *types.Package) string {
+		if name, ok := importNames[pkg.Path()]; ok {
+			return name
+		}
+		name := pkg.Name()
+		for i := 2; used[name]; i++ {
+			name = pkg.Name() + strconv.Itoa(i)
+		}
+		used[name] = true
+		importNames[pkg.Path()] = name
+		return name
+	}
+	qualifier(gen.pkg.Types)
+	body := &bytes.Buffer{}
+	for _, method := range gen.methods {
+		if method.Func == nil || !method.Func.Exported() || !isExportedType(method.RecvType) {
+			continue
+		}
+		sig, ok := method.Func.Type().(*types.Signature)
+		if !ok || sig.TypeParams().Len() > 0 || hasUnexported(sig) {
+			continue
+		}
+		recvType := types.TypeString(method.RecvType, qualifier)
+		if isPointer(method.RecvType) {
+			recvType = "(" + recvType + ")"
+		}
+		fmt.Fprintf(body, "\n// %s.%s forwards to %s.\n", recvType, method.Decl.Name, method.Func.Name())
+		fmt.Fprintf(body, "var _ %s = %s.%s\n", types.TypeString(unnamedSignature(sig), qualifier), importNames[gen.pkg.PkgPath], method.Func.Name())
+		fmt.Fprintf(body, "var _ = %s.%s\n", recvType, method.Decl.Name)
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s\n", pre)
+	buf.WriteString("//go:build ignore\n\n")
+	fmt.Fprintf(buf, "// Command verify_gen verifies that the signatures of source functions and the\n// generated methods of package %s are unchanged since generation.\n//\n//\tgo run %s\n", gen.pkg.Name, verifyFileName)
+	buf.WriteString("package main\n\n")
+	var importPaths []string
+	for importPath := range importNames {
+		importPaths = append(importPaths, importPath)
+	}
+	// standard library imports first.
+	sort.Slice(importPaths, func(i, j int) bool {
+		if stdI, stdJ := isStdImport(importPaths[i]), isStdImport(importPaths[j]); stdI != stdJ {
+			return stdI
+		}
+		return importPaths[i] < importPaths[j]
+	})
+	buf.WriteString("import (\n")
+	for i, importPath := range importPaths {
+		if i > 0 && isStdImport(importPaths[i-1]) && !isStdImport(importPath) {
+			buf.WriteString("\n")
+		}
+		if name := importNames[importPath]; name != path.Base(importPath) {
+			fmt.Fprintf(buf, "\t%s %q\n", name, importPath)
+		} else {
+			fmt.Fprintf(buf, "\t%q\n", importPath)
+		}
+	}
+	buf.WriteString(")\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("\nfunc main() {}\n")
+	data, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
+
+// unnamedSignature returns a copy of the given function signature without
+// parameter and result names.
+func unnamedSignature(sig *types.Signature) *types.Signature {
+	unnamed := func(tuple *types.Tuple) *types.Tuple {
+		var vars []*types.Var
+		for i := 0; i < tuple.Len(); i++ {
+			v := tuple.At(i)
+			vars = append(vars, types.NewParam(v.Pos(), v.Pkg(), "", v.Type()))
+		}
+		return types.NewTuple(vars...)
+	}
+	return types.NewSignatureType(nil, nil, nil, unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic())
+}
+
+// hasUnexported reports whether the given type refers to unexported names of
+// packages (e.g. unexported named types or struct fields), which are not
+// accessible by the verification program.
+func hasUnexported(typ types.Type) bool {
+	switch t := typ.(type) {
+	case *types.Basic, *types.TypeParam:
+		return false
+	case *types.Alias:
+		if obj := t.Obj(); obj.Pkg() != nil && !obj.Exported() {
+			return true
+		}
+		return hasUnexported(types.Unalias(t))
+	case *types.Named:
+		if obj := t.Obj(); obj.Pkg() != nil && !obj.Exported() {
+			return true
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if hasUnexported(t.TypeArgs().At(i)) {
+				return true
+			}
+		}
+		return false
+	case *types.Pointer:
+		return hasUnexported(t.Elem())
+	case *types.Slice:
+		return hasUnexported(t.Elem())
+	case *types.Array:
+		return hasUnexported(t.Elem())
+	case *types.Chan:
+		return hasUnexported(t.Elem())
+	case *types.Map:
+		return hasUnexported(t.Key()) || hasUnexported(t.Elem())
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{t.Params(), t.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if hasUnexported(tuple.At(i).Type()) {
+					return true
+				}
+			}
+		}
+		return false
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if field := t.Field(i); !field.Exported() || hasUnexported(field.Type()) {
+				return true
+			}
+		}
+		return false
+	case *types.Interface:
+		for i := 0; i < t.NumMethods(); i++ {
+			if method := t.Method(i); !method.Exported() || hasUnexported(method.Type()) {
+				return true
+			}
+		}
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			if hasUnexported(t.EmbeddedType(i)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// isExportedType reports whether the given (pointer to) named type is
+// exported.
+func isExportedType(typ types.Type) bool {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := types.Unalias(typ).(*types.Named)
+	return ok && ast.IsExported(named.Obj().Name())
+}
+
+// isStdImport reports whether the given import path is of the standard
+// library; i.e. its first path element contains no dot.
+func isStdImport(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmitVerify(t *testing.T) {
+	dir := newFixture(t, "sdl")
+	if _, stderr, err := runMain(t, dir, "-pkg", fixturePkgPath, "-o", "sdl/methods_gen.go", "-emit-verify"); err != nil {
+		t.Fatalf("unable to run genmethods; %v\n%s", err, stderr)
+	}
+	verifyPath := filepath.Join("sdl", verifyFileName)
+	src, err := os.ReadFile(filepath.Join(dir, verifyPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"//go:build ignore", "var _ func(*sdl.Window, string) bool = sdl.SetWindowTitle", "var _ = (*sdl.Window).SetWindowTitle"} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("verification program mismatch; expected to contain %q\n%s", want, src)
+		}
+	}
+	run := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+		return cmd.CombinedOutput()
+	}
+	// the verification program builds and vets while the source functions are
+	// unchanged, and the package builds without it.
+	for _, args := range [][]string{{"vet", verifyPath}, {"build", "-o", os.DevNull, verifyPath}} {
+		if out, err := run(args...); err != nil {
+			t.Fatalf("go %v failed; %v\n%s", args, err, out)
+		}
+	}
+	checkCompiles(t, dir)
+	// signature drift of source functions is reported.
+	sdlPath := filepath.Join(dir, "sdl", "sdl.go")
+	sdlSrc, err := os.ReadFile(sdlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drifted := bytes.Replace(sdlSrc, []byte("func SetWindowTitle(window *Window, title string)"), []byte("func SetWindowTitle(window *Window, title []byte)"), 1)
+	if bytes.Equal(drifted, sdlSrc) {
+		t.Fatal("unable to locate SetWindowTitle in fixture")
+	}
+	if err := os.WriteFile(sdlPath, drifted, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run("vet", verifyPath); err == nil || !bytes.Contains(out, []byte("SetWindowTitle")) {
+		t.Errorf("expected go vet to report signature drift of SetWindowTitle; %v\n%s", err, out)
+	}
+}
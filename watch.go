@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mewpkg/clog"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// watchDebounce is the delay after the last change of a burst of source file
+// changes (e.g. as made by editors saving files) before regenerating.
+const watchDebounce = 250 * time.Millisecond
+
+// watchPkg watches the source files of the given package and regenerates the
+// methods on each change, printing a short summary of each generation to w.
+// Errors loading the package or generating the methods are printed to w, and
+// watching continues until an error of the file system watcher.
+func watchPkg(w io.Writer, pkgPath, output string, opts *GenOptions) error {
+	if isMultiPkg(pkgPath) {
+		return errors.Errorf("watch mode does not support multi-package mode; got package path %q", pkgPath)
+	}
+	dir, err := pkgDir(pkgPath, opts)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		return errors.WithStack(err)
+	}
+	var outputPath string
+	if len(output) > 0 {
+		if outputPath, err = filepath.Abs(output); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	regenerate := func() {
+		start := time.Now()
+		stats, err := genMethods(pkgPath, output, opts)
+		if err != nil {
+			fmt.Fprintf(w, "genmethods: %v\n", err)
+			return
+		}
+		fmt.Fprintf(w, "genmethods: generated %d methods (%d functions skipped) in %v\n", stats.MethodsGenerated, stats.FuncsSkipped, time.Since(start).Round(time.Millisecond))
+	}
+	regenerate()
+	fmt.Fprintf(w, "genmethods: watching %q\n", dir)
+	// debounce timer; stopped until the first change.
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !isSourceChange(event, outputPath) {
+				continue
+			}
+			clog.Debugf("source change: %v", event)
+			timer.Reset(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return errors.WithStack(err)
+		case <-timer.C:
+			regenerate()
+		}
+	}
+}
+
+// pkgDir returns the directory of the given package, as located by its Go
+// source files. The package is not type-checked, so its directory is located
+// even if it does not compile.
+func pkgDir(pkgPath string, opts *GenOptions) (string, error) {
+	cfg := packagesConfig(opts)
+	cfg.Mode = packages.NeedName | packages.NeedFiles
+	pkgs, err := loadPackages(cfg, opts, pkgPath, pkgPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) > 0 {
+			return filepath.Dir(pkg.GoFiles[0]), nil
+		}
+	}
+	return "", errors.Errorf("unable to locate directory of package %q", pkgPath)
+}
+
+// isSourceChange reports whether the given file system event changes a Go
+// source file of the watched package, ignoring the output file and files
+// generated by genmethods (which are rewritten on each generation).
+func isSourceChange(event fsnotify.Event, outputPath string) bool {
+	if !strings.HasSuffix(event.Name, ".go") || event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return false
+	}
+	if path, err := filepath.Abs(event.Name); err == nil && path == outputPath {
+		return false
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		return true
+	}
+	data, err := os.ReadFile(event.Name)
+	if err != nil {
+		return true // e.g. removed since the event.
+	}
+	return !bytes.HasPrefix(data, []byte(`// Code generated by "genmethods"`))
+}
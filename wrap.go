@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// defaultUnwrapField is the default name of the wrapped value field of wrapper
+// types (-unwrap-field).
+const defaultUnwrapField = "inner"
+
+// validateGenWrap returns the violations of generation options unsupported in
+// wrapper package mode (-gen-wrap), as the generated methods are declared on
+// wrapper types outside of the wrapped package.
+func (opts *GenOptions) validateGenWrap() []string {
+	var errs []string
+	if len(opts.UnwrapField) > 0 && !token.IsIdentifier(opts.UnwrapField) {
+		errs = append(errs, fmt.Sprintf("invalid name %q of wrapped value field (-unwrap-field)", opts.UnwrapField))
+	}
+	unsupported := []struct {
+		flag string
+		set  bool
+	}{
+		{"-split-by-type", opts.SplitByType},
+		{"-group-by-file", opts.GroupByFile},
+		{"-merge", opts.Merge},
+		{"-gen-mocks", opts.GenMocks},
+		{"-emit-verify", opts.EmitVerify},
+		{"-assert-interfaces", opts.AssertInterfaces},
+		{"-interface-recv", opts.InterfaceRecv},
+		{"-auto-fluent", opts.AutoFluent},
+	}
+	for _, u := range unsupported {
+		if u.set {
+			errs = append(errs, fmt.Sprintf("%s is not supported in wrapper package mode (-gen-wrap)", u.flag))
+		}
+	}
+	if config := opts.Config; config != nil {
+		sections := []struct {
+			name string
+			set  bool
+		}{
+			{"forward", len(config.Forward) > 0},
+			{"accessors", len(config.Accessors) > 0},
+			{"interfaces", len(config.Interfaces) > 0},
+			{"safe_wrappers", len(config.SafeWrappers) > 0},
+			{"scopes", len(config.Scopes) > 0},
+		}
+		for _, section := range sections {
+			if section.set {
+				errs = append(errs, fmt.Sprintf("config section %s is not supported in wrapper package mode (-gen-wrap)", section.name))
+			}
+		}
+	}
+	return errs
+}
+
+// unwrappableFunc reports whether the given function on the given receiver
+// type cannot be forwarded to by the methods of wrapper types (-gen-wrap), and
+// if so the reason why; i.e. unexported functions and receiver types, or
+// signatures referring to unexported names of the wrapped package, which are
+// not accessible outside of the wrapped package, and generic functions.
+func (gen *Gen) unwrappableFunc(decl *ast.FuncDecl, recvType types.Type) (string, bool) {
+	switch {
+	case !decl.Name.IsExported():
+		return "unexported functions are not accessible by wrapper packages (-gen-wrap)", true
+	case !isExportedType(recvType):
+		return fmt.Sprintf("unexported receiver type %v is not accessible by wrapper packages (-gen-wrap)", recvType), true
+	case decl.Type.TypeParams != nil:
+		return "generic functions are not supported by wrapper packages (-gen-wrap)", true
+	}
+	if sig, ok := gen.pkg.TypesInfo.Defs[decl.Name].Type().(*types.Signature); ok && hasUnexported(sig) {
+		return "signature refers to unexported names not accessible by wrapper packages (-gen-wrap)", true
+	}
+	return "", false
+}
+
+// unwrapField returns the name of the wrapped value field of wrapper types
+// (-unwrap-field).
+func (gen *Gen) unwrapField() string {
+	if len(gen.opts.UnwrapField) > 0 {
+		return gen.opts.UnwrapField
+	}
+	return defaultUnwrapField
+}
+
+// wrapPkgName returns the package name of the wrapper package (-gen-wrap); the
+// base name of the output directory unless overridden (-package).
+func (gen *Gen) wrapPkgName() (string, error) {
+	if len(gen.opts.PackageName) > 0 {
+		return gen.opts.PackageName, nil
+	}
+	if len(gen.output) == 0 {
+		return "", errors.New("wrapper package (-gen-wrap) written to standard output requires a package name (-package)")
+	}
+	dir, err := filepath.Abs(filepath.Dir(gen.output))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return filepath.Base(dir), nil
+}
+
+// checkWrapOutput reports an error if the output file of the wrapper package
+// (-gen-wrap) is located in the directory of the wrapped package, as the
+// wrapper types would collide with the wrapped types.
+func (gen *Gen) checkWrapOutput() error {
+	if len(gen.output) == 0 || len(gen.pkg.GoFiles) == 0 {
+		return nil
+	}
+	if sameFile(filepath.Dir(gen.output), filepath.Dir(gen.pkg.GoFiles[0])) {
+		return errors.Errorf("wrapper package (-gen-wrap) must be generated outside of the directory of package %q; got output path %q", gen.pkg.PkgPath, gen.output)
+	}
+	return nil
+}
+
+// wrapImport adds the import of the wrapped package to the imports of the
+// wrapper package, and returns the package name used to qualify its
+// identifiers.
+func (gen *Gen) wrapImport() string {
+	importPath, name := gen.pkg.PkgPath, gen.pkg.Name
+	gen.imports[importPath] = true
+	if name != path.Base(importPath) {
+		if gen.importNames[importPath] == nil {
+			gen.importNames[importPath] = make(map[string]bool)
+		}
+		gen.importNames[importPath][name] = true
+	}
+	return name
+}
+
+// wrappedType returns the type of the wrapped value field of the wrapper type
+// of the given receiver type, which is the receiver type of the first generated
+// method on its base type (e.g. *sdl.Window).
+func (gen *Gen) wrappedType(recvType types.Type) types.Type {
+	base := recvType
+	if ptr, ok := base.(*types.Pointer); ok {
+		base = ptr.Elem()
+	}
+	if typ, ok := gen.wrappedTypes[base.String()]; ok {
+		return typ
+	}
+	gen.wrappedTypes[base.String()] = recvType
+	return recvType
+}
+
+// qualifiedTypeExpr returns the type expression of the given type, with
+// identifiers of the wrapped package qualified by its package name (e.g.
+// `*sdl.Window`).
+func (gen *Gen) qualifiedTypeExpr(typ types.Type) ast.Expr {
+	s := types.TypeString(typ, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		panic(errors.Errorf("unable to parse type expression %q: %v", s, err))
+	}
+	return stripPos(expr)
+}
+
+// qualifyFields returns a copy of the given parameter or result list, with
+// types of the wrapped package qualified by the given package name (e.g.
+// `r *sdl.Renderer` of `r *Renderer`).
+func (gen *Gen) qualifyFields(fields *ast.FieldList, pkgName string) *ast.FieldList {
+	if fields == nil {
+		return nil
+	}
+	qualified := &ast.FieldList{}
+	for _, field := range fields.List {
+		var names []*ast.Ident
+		for _, name := range field.Names {
+			names = append(names, ast.NewIdent(name.Name))
+		}
+		qualified.List = append(qualified.List, &ast.Field{
+			Names: names,
+			Type:  gen.qualifyExpr(field.Type, pkgName),
+		})
+	}
+	return qualified
+}
+
+// qualifyExpr returns a copy of the given type expression, with identifiers of
+// types of the wrapped package qualified by the given package name.
+func (gen *Gen) qualifyExpr(expr ast.Expr, pkgName string) ast.Expr {
+	if ellipsis, ok := expr.(*ast.Ellipsis); ok {
+		// variadic parameters.
+		return &ast.Ellipsis{Elt: gen.qualifyExpr(ellipsis.Elt, pkgName)}
+	}
+	s := types.ExprString(expr)
+	copied, err := parser.ParseExpr(s)
+	if err != nil {
+		panic(errors.Errorf("unable to parse type expression %q: %v", s, err))
+	}
+	return astutil.Apply(stripPos(copied), func(c *astutil.Cursor) bool {
+		switch c.Parent().(type) {
+		case *ast.SelectorExpr:
+			return false // qualified identifiers of other packages.
+		case *ast.Field:
+			if c.Name() == "Names" {
+				return false // names of fields and parameters.
+			}
+		}
+		ident, ok := c.Node().(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if _, ok := gen.pkg.Types.Scope().Lookup(ident.Name).(*types.TypeName); ok {
+			c.Replace(&ast.SelectorExpr{X: ast.NewIdent(pkgName), Sel: ast.NewIdent(ident.Name)})
+		}
+		return true
+	}, nil).(ast.Expr)
+}
+
+// wrapCtorName returns the name of the constructor of the given wrapper type
+// (e.g. "WrapWindow"); a constructor is generated for each wrapper type.
+func wrapCtorName(wrapperName string) string {
+	return "Wrap" + wrapperName
+}
+
+// wrapDecls returns the declarations of the wrapper types of the receiver types
+// of the given generated methods (-gen-wrap), each followed by its constructor
+// and generated constructors (-gen-constructors), in order of first generated
+// method.
+//
+// Example:
+//
+//	// Window wraps a *sdl.Window.
+//	type Window struct {
+//		inner *sdl.Window
+//	}
+//
+//	// WrapWindow returns a Window wrapping v.
+//	func WrapWindow(v *sdl.Window) *Window {
+//		return &Window{inner: v}
+//	}
+func (gen *Gen) wrapDecls(methods []*Method) []ast.Decl {
+	field := gen.unwrapField()
+	var decls []ast.Decl
+	done := make(map[string]bool)
+	for _, method := range methods {
+		wrapperName := typeName(method.RecvType)
+		if done[wrapperName] {
+			continue
+		}
+		done[wrapperName] = true
+		wrappedType := gen.wrappedType(method.RecvType)
+		wrappedName := types.TypeString(wrappedType, func(pkg *types.Package) string {
+			return pkg.Name()
+		})
+		typeDecl := &ast.GenDecl{
+			Doc: &ast.CommentGroup{
+				List: []*ast.Comment{
+					{Text: "// " + wrapperName + " wraps a " + wrappedName + "."},
+				},
+			},
+			Tok: token.TYPE,
+			Specs: []ast.Spec{
+				&ast.TypeSpec{
+					Name: ast.NewIdent(wrapperName),
+					Type: &ast.StructType{
+						Fields: &ast.FieldList{
+							List: []*ast.Field{
+								{
+									Names: []*ast.Ident{ast.NewIdent(field)},
+									Type:  gen.qualifiedTypeExpr(wrappedType),
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		ctorName := wrapCtorName(wrapperName)
+		ctorDecl := &ast.FuncDecl{
+			Doc: &ast.CommentGroup{
+				List: []*ast.Comment{
+					{Text: "// " + ctorName + " returns a " + wrapperName + " wrapping v."},
+				},
+			},
+			Name: ast.NewIdent(ctorName),
+			Type: &ast.FuncType{
+				Params: &ast.FieldList{
+					List: []*ast.Field{
+						{
+							Names: []*ast.Ident{ast.NewIdent("v")},
+							Type:  gen.qualifiedTypeExpr(wrappedType),
+						},
+					},
+				},
+				Results: &ast.FieldList{
+					List: []*ast.Field{
+						{Type: &ast.StarExpr{X: ast.NewIdent(wrapperName)}},
+					},
+				},
+			},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.ReturnStmt{
+						Results: []ast.Expr{
+							&ast.UnaryExpr{
+								Op: token.AND,
+								X: &ast.CompositeLit{
+									Type: ast.NewIdent(wrapperName),
+									Elts: []ast.Expr{
+										&ast.KeyValueExpr{
+											Key:   ast.NewIdent(field),
+											Value: ast.NewIdent("v"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		decls = append(decls, typeDecl, ctorDecl)
+		for _, decl := range gen.constructors[wrapperName] {
+			decls = append(decls, decl)
+		}
+	}
+	return decls
+}
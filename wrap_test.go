@@ -0,0 +1,265 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenWrap(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// expected generated source of the wrapper package.
+		want string
+	}{
+		{
+			name: "default",
+			opts: &GenOptions{GenWrap: true},
+			want: `// Code generated by "genmethods"; DO NOT EDIT.
+
+package sdlwrap
+
+import (
+	"github.com/jupiterrider/purego-sdl3/sdl"
+)
+
+// Window wraps a *sdl.Window.
+type Window struct {
+	inner *sdl.Window
+}
+
+// WrapWindow returns a Window wrapping v.
+func WrapWindow(v *sdl.Window) *Window {
+	return &Window{inner: v}
+}
+
+// Renderer wraps a *sdl.Renderer.
+type Renderer struct {
+	inner *sdl.Renderer
+}
+
+// WrapRenderer returns a Renderer wrapping v.
+func WrapRenderer(v *sdl.Renderer) *Renderer {
+	return &Renderer{inner: v}
+}
+
+// SetWindowTitle sets the title of the window.
+func (window *Window) SetWindowTitle(title string) {
+	sdl.SetWindowTitle(window.inner, title)
+}
+
+// GetRenderer returns the renderer of the window.
+func (window *Window) GetRenderer() (*sdl.Renderer, error) {
+	return sdl.GetRenderer(window.inner)
+}
+
+// RenderClear clears the rendering target using the given color.
+func (renderer *Renderer) Clear(color sdl.Color) bool {
+	return sdl.RenderClear(renderer.inner, color)
+}
+
+// RenderPoints renders the given points.
+func (renderer *Renderer) RenderPoints(points map[string][]sdl.Point) bool {
+	return sdl.RenderPoints(renderer.inner, points)
+}
+`,
+		},
+		{
+			name: "unwrap field and package name",
+			opts: &GenOptions{GenWrap: true, UnwrapField: "raw", PackageName: "gfx"},
+			want: `// Code generated by "genmethods"; DO NOT EDIT.
+
+package gfx
+
+import (
+	"github.com/jupiterrider/purego-sdl3/sdl"
+)
+
+// Window wraps a *sdl.Window.
+type Window struct {
+	raw *sdl.Window
+}
+
+// WrapWindow returns a Window wrapping v.
+func WrapWindow(v *sdl.Window) *Window {
+	return &Window{raw: v}
+}
+
+// Renderer wraps a *sdl.Renderer.
+type Renderer struct {
+	raw *sdl.Renderer
+}
+
+// WrapRenderer returns a Renderer wrapping v.
+func WrapRenderer(v *sdl.Renderer) *Renderer {
+	return &Renderer{raw: v}
+}
+
+// SetWindowTitle sets the title of the window.
+func (window *Window) SetWindowTitle(title string) {
+	sdl.SetWindowTitle(window.raw, title)
+}
+
+// GetRenderer returns the renderer of the window.
+func (window *Window) GetRenderer() (*sdl.Renderer, error) {
+	return sdl.GetRenderer(window.raw)
+}
+
+// RenderClear clears the rendering target using the given color.
+func (renderer *Renderer) Clear(color sdl.Color) bool {
+	return sdl.RenderClear(renderer.raw, color)
+}
+
+// RenderPoints renders the given points.
+func (renderer *Renderer) RenderPoints(points map[string][]sdl.Point) bool {
+	return sdl.RenderPoints(renderer.raw, points)
+}
+`,
+		},
+		{
+			name: "constructors",
+			opts: &GenOptions{GenWrap: true, GenConstructors: true},
+			want: `// Code generated by "genmethods"; DO NOT EDIT.
+
+package sdlwrap
+
+import (
+	"github.com/jupiterrider/purego-sdl3/sdl"
+)
+
+// Window wraps a *sdl.Window.
+type Window struct {
+	inner *sdl.Window
+}
+
+// WrapWindow returns a Window wrapping v.
+func WrapWindow(v *sdl.Window) *Window {
+	return &Window{inner: v}
+}
+
+// CreateWindow creates a window with the given title.
+func NewWindow(title string) (w *Window) {
+	return WrapWindow(sdl.CreateWindow(title))
+}
+
+// Renderer wraps a *sdl.Renderer.
+type Renderer struct {
+	inner *sdl.Renderer
+}
+
+// WrapRenderer returns a Renderer wrapping v.
+func WrapRenderer(v *sdl.Renderer) *Renderer {
+	return &Renderer{inner: v}
+}
+
+// CreateRenderer creates a renderer of the window.
+func NewRenderer(name string, flags ...uint32) (*Renderer, error) {
+	v, err := sdl.CreateRenderer(name, flags...)
+	if err != nil {
+		return nil, err
+	}
+	return WrapRenderer(v), nil
+}
+
+// SetWindowTitle sets the title of the window.
+func (window *Window) SetWindowTitle(title string) {
+	sdl.SetWindowTitle(window.inner, title)
+}
+
+// GetRenderer returns the renderer of the window.
+func (window *Window) GetRenderer() (*sdl.Renderer, error) {
+	return sdl.GetRenderer(window.inner)
+}
+
+// RenderClear clears the rendering target using the given color.
+func (renderer *Renderer) Clear(color sdl.Color) bool {
+	return sdl.RenderClear(renderer.inner, color)
+}
+
+// RenderPoints renders the given points.
+func (renderer *Renderer) RenderPoints(points map[string][]sdl.Point) bool {
+	return sdl.RenderPoints(renderer.inner, points)
+}
+`,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "genwrap")
+			output := filepath.Join(dir, "sdlwrap", "methods_gen.go")
+			if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			// unexported and generic functions, and functions with unexported
+			// types in their signature are not forwarded to.
+			if _, err := genMethods(fixturePkgPath, output, g.opts); err != nil {
+				t.Fatalf("unable to generate wrapper package; %+v", err)
+			}
+			got, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != g.want {
+				t.Errorf("generated source mismatch; expected:\n%s\ngot:\n%s", g.want, got)
+			}
+			checkCompiles(t, dir)
+		})
+	}
+}
+
+func TestGenWrapErrors(t *testing.T) {
+	golden := []struct {
+		name string
+		opts *GenOptions
+		// output path relative to the fixture directory.
+		output string
+		// expected substring of the error.
+		want string
+	}{
+		{
+			name:   "wrapped package directory",
+			opts:   &GenOptions{GenWrap: true},
+			output: "sdl/methods_gen.go",
+			want:   "must be generated outside of the directory of package",
+		},
+		{
+			name:   "invalid unwrap field",
+			opts:   &GenOptions{GenWrap: true, UnwrapField: "func"},
+			output: "sdlwrap/methods_gen.go",
+			want:   `invalid name "func" of wrapped value field (-unwrap-field)`,
+		},
+		{
+			name:   "constructors without wrapper package",
+			opts:   &GenOptions{GenConstructors: true},
+			output: "sdl/methods_gen.go",
+			want:   "-gen-constructors requires wrapper package mode (-gen-wrap)",
+		},
+		{
+			name:   "unsupported option",
+			opts:   &GenOptions{GenWrap: true, GenMocks: true},
+			output: "sdlwrap/methods_gen.go",
+			want:   "-gen-mocks is not supported in wrapper package mode (-gen-wrap)",
+		},
+		{
+			name:   "unsupported config section",
+			opts:   &GenOptions{GenWrap: true, Config: &Config{Accessors: map[string]string{"*" + fixturePkgPath + ".Renderer": "{recv}.ptr"}}},
+			output: "sdlwrap/methods_gen.go",
+			want:   "config section accessors is not supported in wrapper package mode (-gen-wrap)",
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "genwrap")
+			output := filepath.Join(dir, g.output)
+			if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			_, err := genMethods(fixturePkgPath, output, g.opts)
+			if err == nil || !strings.Contains(err.Error(), g.want) {
+				t.Errorf("error mismatch; expected to contain %q, got %v", g.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// SafeWrapper specifies a thread-safety wrapper type generated around a
+// receiver type, with methods holding a mutex while forwarding to the
+// generated methods of the receiver type.
+type SafeWrapper struct {
+	// name of wrapper type (e.g. "SafeRenderer").
+	Name string `json:"name"`
+	// name of mutex field; defaults to "mu".
+	Mutex string `json:"mutex,omitempty"`
+	// name of wrapped receiver field; defaults to the lowercase initial of the
+	// receiver type name (e.g. "r").
+	Field string `json:"field,omitempty"`
+}
+
+// safeWrapperDecls returns the declarations of the thread-safety wrapper types
+// configured for the receiver types of the given generated methods, each
+// followed by its constructor and mutex-protected methods, in order of first
+// generated method. When generated methods of a receiver type are spread across
+// output files (e.g. in group-by-file mode), the wrapper type and constructor
+// are declared in the output file of the first generated method of the
+// receiver type.
+//
+// Example:
+//
+//	type SafeRenderer struct {
+//		mu sync.Mutex
+//		r  *Renderer
+//	}
+//
+//	func (s *SafeRenderer) Clear() bool {
+//		s.mu.Lock()
+//		defer s.mu.Unlock()
+//		return s.r.Clear()
+//	}
+func (gen *Gen) safeWrapperDecls(methods []*Method) ([]ast.Decl, error) {
+	config := gen.opts.Config
+	if config == nil || len(config.SafeWrappers) == 0 {
+		return nil, nil
+	}
+	baseOf := func(typ types.Type) types.Type {
+		if ptr, ok := typ.(*types.Pointer); ok {
+			return ptr.Elem()
+		}
+		return typ
+	}
+	// map from base receiver type to first generated method.
+	first := make(map[string]*Method)
+	for _, method := range gen.methods {
+		if base := baseOf(method.RecvType).String(); first[base] == nil {
+			first[base] = method
+		}
+	}
+	var decls []ast.Decl
+	done := make(map[string]bool)
+	for _, method := range methods {
+		// locate wrapper by value or pointer receiver type.
+		base := baseOf(method.RecvType).String()
+		typeName := "*" + base
+		wrapper, ok := config.SafeWrappers[typeName]
+		if !ok {
+			typeName = base
+			wrapper, ok = config.SafeWrappers[typeName]
+		}
+		if !ok || done[typeName] {
+			continue
+		}
+		done[typeName] = true
+		wrappedType, err := gen.lookupType(typeName)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !token.IsIdentifier(wrapper.Name) {
+			return nil, errors.Errorf("invalid name %q of safe wrapper of %q", wrapper.Name, typeName)
+		}
+		if gen.isDeclared(wrapper.Name) {
+			return nil, errors.Errorf("safe wrapper %q of %q already declared in package %q", wrapper.Name, typeName, gen.pkg.PkgPath)
+		}
+		if mutex, field := wrapperFields(wrapper, wrappedType); mutex == field {
+			return nil, errors.Errorf("mutex and wrapped field of safe wrapper %q share name %q", wrapper.Name, mutex)
+		}
+		if first[base] == nil || slices.Contains(methods, first[base]) {
+			decls = append(decls, gen.safeWrapperTypeDecls(wrapper, wrappedType)...)
+		}
+		for _, m := range methods {
+			if baseOf(m.RecvType).String() == base {
+				decls = append(decls, safeWrapperMethod(wrapper, m))
+			}
+		}
+	}
+	return decls, nil
+}
+
+// safeWrapperTypeDecls returns the type declaration and constructor of the
+// given thread-safety wrapper of the wrapped type.
+func (gen *Gen) safeWrapperTypeDecls(wrapper *SafeWrapper, wrappedType types.Type) []ast.Decl {
+	mutex, field := wrapperFields(wrapper, wrappedType)
+	wrappedName := types.TypeString(wrappedType, types.RelativeTo(gen.pkg.Types))
+	typeDecl := &ast.GenDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// " + wrapper.Name + " serializes access to a " + wrappedName + " by holding a mutex"},
+				{Text: "// during each method call."},
+			},
+		},
+		Tok: token.TYPE,
+		Specs: []ast.Spec{
+			&ast.TypeSpec{
+				Name: ast.NewIdent(wrapper.Name),
+				Type: &ast.StructType{
+					Fields: &ast.FieldList{
+						List: []*ast.Field{
+							{
+								Names: []*ast.Ident{ast.NewIdent(mutex)},
+								Type: &ast.SelectorExpr{
+									X:   ast.NewIdent("sync"),
+									Sel: ast.NewIdent("Mutex"),
+								},
+							},
+							{
+								Names: []*ast.Ident{ast.NewIdent(field)},
+								Type:  gen.typeExpr(wrappedType),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	ctorName := "New" + wrapper.Name
+	ctorDecl := &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// " + ctorName + " returns a " + wrapper.Name + " wrapping " + field + "."},
+			},
+		},
+		Name: ast.NewIdent(ctorName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Names: []*ast.Ident{ast.NewIdent(field)},
+						Type:  gen.typeExpr(wrappedType),
+					},
+				},
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{Type: &ast.StarExpr{X: ast.NewIdent(wrapper.Name)}},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: []ast.Expr{
+						&ast.UnaryExpr{
+							Op: token.AND,
+							X: &ast.CompositeLit{
+								Type: ast.NewIdent(wrapper.Name),
+								Elts: []ast.Expr{
+									&ast.KeyValueExpr{
+										Key:   ast.NewIdent(field),
+										Value: ast.NewIdent(field),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	return []ast.Decl{typeDecl, ctorDecl}
+}
+
+// safeWrapperMethod returns the mutex-protected method of the given
+// thread-safety wrapper, forwarding to the given generated method.
+func safeWrapperMethod(wrapper *SafeWrapper, method *Method) *ast.FuncDecl {
+	mutex, field := wrapperFields(wrapper, method.RecvType)
+	methodName := method.Decl.Name.Name
+	// name receiver by the lowercase initial of the wrapper name, suffixed with
+	// underscores if colliding with parameters.
+	used := make(map[string]bool)
+	var args []ast.Expr
+	variadic := false
+	if params := method.Decl.Type.Params; params != nil {
+		for _, field := range params.List {
+			if _, ok := field.Type.(*ast.Ellipsis); ok {
+				variadic = true
+			}
+			for _, name := range field.Names {
+				used[name.Name] = true
+				args = append(args, ast.NewIdent(name.Name))
+			}
+		}
+	}
+	recvName := initialLower(wrapper.Name)
+	for used[recvName] {
+		recvName += "_"
+	}
+	lockCall := func(name string) *ast.CallExpr {
+		return &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X: &ast.SelectorExpr{
+					X:   ast.NewIdent(recvName),
+					Sel: ast.NewIdent(mutex),
+				},
+				Sel: ast.NewIdent(name),
+			},
+		}
+	}
+	call := &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X: &ast.SelectorExpr{
+				X:   ast.NewIdent(recvName),
+				Sel: ast.NewIdent(field),
+			},
+			Sel: ast.NewIdent(methodName),
+		},
+		Args: args,
+	}
+	call.Fun = instantiate(call.Fun, method.Decl.Type.TypeParams)
+	if variadic {
+		call.Ellipsis = 1
+	}
+	var callStmt ast.Stmt = &ast.ExprStmt{X: call}
+	if results := method.Decl.Type.Results; results != nil && len(results.List) > 0 {
+		callStmt = &ast.ReturnStmt{Results: []ast.Expr{call}}
+	}
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: "// " + methodName + " calls " + methodName + " of the wrapped value while holding the mutex."},
+			},
+		},
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{ast.NewIdent(recvName)},
+					Type:  &ast.StarExpr{X: ast.NewIdent(wrapper.Name)},
+				},
+			},
+		},
+		Name: ast.NewIdent(methodName),
+		Type: &ast.FuncType{
+			TypeParams: method.Decl.Type.TypeParams,
+			Params:     method.Decl.Type.Params,
+			Results:    method.Decl.Type.Results,
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{X: lockCall("Lock")},
+				&ast.DeferStmt{Call: lockCall("Unlock")},
+				callStmt,
+			},
+		},
+	}
+}
+
+// wrapperFields returns the mutex and wrapped field names of the given
+// thread-safety wrapper of the wrapped type.
+func wrapperFields(wrapper *SafeWrapper, wrappedType types.Type) (mutex, field string) {
+	mutex = wrapper.Mutex
+	if len(mutex) == 0 {
+		mutex = "mu"
+	}
+	field = wrapper.Field
+	if len(field) == 0 {
+		if ptr, ok := wrappedType.(*types.Pointer); ok {
+			wrappedType = ptr.Elem()
+		}
+		name := wrappedType.String()
+		if named, ok := wrappedType.(*types.Named); ok {
+			name = named.Obj().Name()
+		}
+		field = initialLower(name)
+	}
+	return mutex, field
+}
+
+// initialLower returns the lowercase initial of s (e.g. "r" for "Renderer").
+func initialLower(s string) string {
+	r, _ := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSafeWrappers(t *testing.T) {
+	golden := []struct {
+		name     string
+		wrappers map[string]*SafeWrapper
+		// expected methods, by method expression.
+		want map[string]string
+	}{
+		{
+			name: "default fields",
+			wrappers: map[string]*SafeWrapper{
+				"*" + fixturePkgPath + ".Renderer": {Name: "SafeRenderer"},
+			},
+			want: map[string]string{
+				"(*SafeRenderer).Clear":   "func (s *SafeRenderer) Clear() bool {\n\ts.mu.Lock()\n\tdefer s.mu.Unlock()\n\treturn s.r.Clear()\n}",
+				"(*SafeRenderer).Destroy": "func (s *SafeRenderer) Destroy() {\n\ts.mu.Lock()\n\tdefer s.mu.Unlock()\n\ts.r.Destroy()\n}",
+				// forwarded method of the wrapped type.
+				"(*Renderer).Clear": "func (renderer *Renderer) Clear() bool {\n\treturn RenderClear(renderer)\n}",
+			},
+		},
+		{
+			name: "custom fields",
+			wrappers: map[string]*SafeWrapper{
+				"*" + fixturePkgPath + ".Window": {Name: "SafeWindow", Mutex: "lock", Field: "w"},
+			},
+			want: map[string]string{
+				// parameter shares name with wrapped field.
+				"(*SafeWindow).GetSize":        "func (s *SafeWindow) GetSize(w, h *int32) bool {\n\ts.lock.Lock()\n\tdefer s.lock.Unlock()\n\treturn s.w.GetSize(w, h)\n}",
+				"(*SafeWindow).CreateRenderer": "func (s *SafeWindow) CreateRenderer(name string) (*Renderer, error) {\n\ts.lock.Lock()\n\tdefer s.lock.Unlock()\n\treturn s.w.CreateRenderer(name)\n}",
+			},
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			opts := &GenOptions{Config: &Config{SafeWrappers: g.wrappers}}
+			got := genFixture(t, "sdl", opts)
+			for key, want := range g.want {
+				if method := got.method(t, key); method != want {
+					t.Errorf("method %s mismatch; expected %q, got %q", key, want, method)
+				}
+			}
+			if !got.hasImport("sync") {
+				t.Errorf("import of %q missing", "sync")
+			}
+		})
+	}
+}
+
+func TestSafeWrappersError(t *testing.T) {
+	golden := []struct {
+		name    string
+		wrapper *SafeWrapper
+		// expected substring of error.
+		want string
+	}{
+		{
+			name:    "shared field name",
+			wrapper: &SafeWrapper{Name: "SafeRenderer", Mutex: "r"},
+			want:    `mutex and wrapped field of safe wrapper "SafeRenderer" share name "r"`,
+		},
+		{
+			name:    "invalid name",
+			wrapper: &SafeWrapper{Name: "Safe Renderer"},
+			want:    `invalid name "Safe Renderer" of safe wrapper`,
+		},
+		{
+			name:    "declared name",
+			wrapper: &SafeWrapper{Name: "Window"},
+			want:    `safe wrapper "Window" of "*` + fixturePkgPath + `.Renderer" already declared`,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			dir := newFixture(t, "sdl")
+			output := filepath.Join(dir, "sdl", "methods_gen.go")
+			opts := &GenOptions{
+				Config: &Config{
+					SafeWrappers: map[string]*SafeWrapper{"*" + fixturePkgPath + ".Renderer": g.wrapper},
+				},
+			}
+			_, err := genMethods(fixturePkgPath, output, opts)
+			if err == nil || !strings.Contains(err.Error(), g.want) {
+				t.Errorf("error mismatch; expected %q, got %v", g.want, err)
+			}
+		})
+	}
+}